@@ -0,0 +1,62 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/KseniiaSalmina/Profiles/internal/api/models"
+	"github.com/KseniiaSalmina/Profiles/internal/validation"
+)
+
+// @Summary Put log level
+// @Security BasicAuth
+// @Tags super admin
+// @Description change log level of running service, the level is kept until restart or configuration reload
+// @Accept json,application/yaml,application/msgpack
+// @Param level body models.LogLevel true "new log level"
+// @Success 200
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 500 {string} string
+// @Router /v1/admin/log-level [put]
+func (s *Server) putLogLevel(w http.ResponseWriter, r *http.Request) {
+	caller, err := s.authorization(r)
+	if err != nil {
+		s.log(r).WithError(err).Info("put log level handler, failed authorization")
+		http.Error(w, err.Error(), authErrorStatus(w, err))
+		return
+	}
+
+	if !caller.SuperAdmin {
+		s.log(r).Info("put log level handler, user is not super admin")
+		http.Error(w, validation.ErrIsNotSuperAdmin.Error(), http.StatusForbidden)
+		return
+	}
+
+	var level models.LogLevel
+	if err := decodeBody(r, &level); err != nil {
+		s.log(r).WithError(err).Info("put log level handler, failed to unmarshall request body")
+		http.Error(w, err.Error(), decodeErrorStatus(err))
+		return
+	}
+	defer r.Body.Close()
+
+	lvl, err := logrus.ParseLevel(level.Level)
+	if err != nil {
+		s.log(r).WithError(err).Info("put log level handler, incorrect level")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	previous := s.logger.GetLevel()
+	s.logger.SetLevel(lvl)
+	s.log(r).WithFields(logrus.Fields{
+		"previous_level": previous.String(),
+		"level":          lvl.String(),
+		"changed_by":     caller.Username,
+	}).Warn("log level changed")
+
+	w.WriteHeader(http.StatusOK)
+}
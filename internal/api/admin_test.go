@@ -0,0 +1,56 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_putLogLevel(t1 *testing.T) {
+	server := prepareServer()
+
+	serve := func(body, username, password string) int {
+		req := httptest.NewRequest("PUT", "/admin/log-level", strings.NewReader(body))
+		req.SetBasicAuth(username, password)
+		w := httptest.NewRecorder()
+		server.httpServer.Handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t1, http.StatusForbidden, serve(`{"level":"warn"}`, "testUser3", "password"))
+	assert.Equal(t1, http.StatusBadRequest, serve(`{"level":"loud"}`, "username", "password"))
+	assert.Equal(t1, logrus.DebugLevel, server.logger.GetLevel())
+
+	assert.Equal(t1, http.StatusOK, serve(`{"level":"warn"}`, "username", "password"))
+	assert.Equal(t1, logrus.WarnLevel, server.logger.GetLevel())
+}
+
+func TestServer_Reconfigure(t1 *testing.T) {
+	server := prepareServer()
+
+	cfg := serverCfg
+	cfg.RequestTimeout = -time.Second
+	_, err := server.Reconfigure(cfg)
+	assert.ErrorIs(t1, err, ErrNegativeTimeout)
+
+	cfg = serverCfg
+	cfg.TrustedProxies = []string{"not an address"}
+	_, err = server.Reconfigure(cfg)
+	assert.ErrorIs(t1, err, ErrIncorrectTrustedProxy)
+
+	cfg = serverCfg
+	cfg.RequestTimeout = 3 * time.Second
+	cfg.TrustedProxies = []string{"10.0.0.1"}
+	apply, err := server.Reconfigure(cfg)
+	assert.NoError(t1, err)
+	assert.Zero(t1, server.settings.Load().requestTimeout)
+
+	apply()
+	assert.Equal(t1, 3*time.Second, server.settings.Load().requestTimeout)
+	assert.Len(t1, server.settings.Load().trustedProxies, 1)
+}
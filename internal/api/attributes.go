@@ -0,0 +1,77 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/KseniiaSalmina/Profiles/internal/api/models"
+	"github.com/KseniiaSalmina/Profiles/internal/validation"
+)
+
+// @Summary Get attribute schema
+// @Security BasicAuth
+// @Tags user
+// @Description return schema of custom profile attributes
+// @Return json
+// @Produce json,application/yaml,application/msgpack
+// @Success 200 {object} models.AttributeSchema
+// @Failure 401 {string} string
+// @Router /v1/attributes/schema [get]
+func (s *Server) getAttributeSchema(w http.ResponseWriter, r *http.Request) {
+	if _, err := s.authorization(r); err != nil {
+		s.log(r).WithError(err).Info("get attribute schema handler, failed authorization")
+		http.Error(w, err.Error(), authErrorStatus(w, err))
+		return
+	}
+
+	schema, err := s.service.GetAttributeSchema(r.Context())
+	if err != nil {
+		s.log(r).WithError(err).Info("get attribute schema handler, failed to get attribute schema")
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	s.respond(w, r, http.StatusOK, schema)
+}
+
+// @Summary Put attribute schema
+// @Security BasicAuth
+// @Tags super admin
+// @Description replace schema of custom profile attributes shared by all organizations, supports type (string, number, integer, boolean), required, enum, pattern and maxLength
+// @Accept json,application/yaml,application/msgpack
+// @Param schema body models.AttributeSchema true "new attribute schema"
+// @Success 200
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 500 {string} string
+// @Router /v1/attributes/schema [put]
+func (s *Server) putAttributeSchema(w http.ResponseWriter, r *http.Request) {
+	caller, err := s.authorization(r)
+	if err != nil {
+		s.log(r).WithError(err).Info("put attribute schema handler, failed authorization")
+		http.Error(w, err.Error(), authErrorStatus(w, err))
+		return
+	}
+
+	if !caller.SuperAdmin {
+		s.log(r).Info("put attribute schema handler, user is not super admin")
+		http.Error(w, validation.ErrIsNotSuperAdmin.Error(), http.StatusForbidden)
+		return
+	}
+
+	var schema models.AttributeSchema
+	if err := decodeBody(r, &schema); err != nil {
+		s.log(r).WithError(err).Info("put attribute schema handler, failed to unmarshall request body")
+		http.Error(w, err.Error(), decodeErrorStatus(err))
+		return
+	}
+	defer r.Body.Close()
+
+	if err := s.service.SetAttributeSchema(r.Context(), schema); err != nil {
+		s.log(r).WithError(err).Info("put attribute schema handler, failed to set schema")
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -1,28 +1,144 @@
 package api
 
 import (
+	"crypto/x509"
 	"errors"
 	"net/http"
+	"slices"
+	"time"
 
+	"github.com/KseniiaSalmina/Profiles/internal/database"
+	"github.com/KseniiaSalmina/Profiles/internal/metrics"
 	"github.com/KseniiaSalmina/Profiles/internal/validation"
 )
 
 var ErrNoAuthString = errors.New("authorization required")
 
-func (s *Server) authorization(r *http.Request) (bool, error) {
-	username, password, ok := r.BasicAuth()
-	if !ok {
-		return false, ErrNoAuthString
+// callerKey keeps user authorized by middleware before the handler.
+type callerKey struct{}
+
+// authorization checks Basic credentials, requests without them are authorized by verified client certificate
+// if mutual TLS is enabled or by session cookie if sessions are enabled.
+// Caller already authorized during the request, e.g. by idempotent, is taken from context.
+func (s *Server) authorization(r *http.Request) (*database.User, error) {
+	if caller, ok := r.Context().Value(callerKey{}).(*database.User); ok {
+		return caller, nil
+	}
+
+	if username, password, ok := r.BasicAuth(); ok {
+		return s.passwordAuthorization(r, username, password)
+	}
+
+	if cert, err := clientCertificate(r); err == nil {
+		return s.certificateAuthorization(r, cert)
+	}
+
+	if s.sessions != nil {
+		if cookie, err := r.Cookie(sessionCookie); err == nil {
+			return s.sessionAuthorization(r, cookie.Value)
+		}
+	}
+
+	s.metrics.ObserveAuth(metrics.AuthFailure, metrics.ReasonNoCredentials)
+	return nil, ErrNoAuthString
+}
+
+func (s *Server) passwordAuthorization(r *http.Request, username, password string) (*database.User, error) {
+	if err := s.checkAuthFailures(r); err != nil {
+		return nil, err
+	}
+
+	user, err := s.authData(r, username)
+	if err != nil {
+		return nil, err
+	}
+
+	_, span := tracer.Start(r.Context(), "bcrypt.CompareHashAndPassword")
+	start := time.Now()
+	err = validation.Auth(username, password+s.service.ReturnSalt(), *user)
+	s.metrics.ObserveBcrypt(time.Since(start))
+	span.End()
+	if err != nil {
+		reason := metrics.ReasonWrongPassword
+		if errors.Is(err, validation.ErrUserDisabled) {
+			reason = metrics.ReasonDisabled
+		}
+		s.metrics.ObserveAuth(metrics.AuthFailure, reason)
+		s.recordAuthFailure(r)
+		return nil, err
+	}
+
+	return s.authorized(r, user)
+}
+
+// certificateAuthorization maps common name of client certificate to user of the request organization.
+// Subject organization of certificate should name organization of the user, so certificate is valid in one tenant only.
+func (s *Server) certificateAuthorization(r *http.Request, cert *x509.Certificate) (*database.User, error) {
+	if err := s.checkAuthFailures(r); err != nil {
+		return nil, err
 	}
 
-	user, err := s.service.GetAuthData(username)
+	user, err := s.authData(r, cert.Subject.CommonName)
 	if err != nil {
-		return false, err
+		return nil, err
+	}
+
+	org, err := s.service.GetOrganizationByID(r.Context(), user.OrgID)
+	if err != nil {
+		s.metrics.ObserveAuth(metrics.AuthFailure, metrics.ReasonError)
+		return nil, err
+	}
+	if !slices.Contains(cert.Subject.Organization, org.Name) {
+		s.metrics.ObserveAuth(metrics.AuthFailure, metrics.ReasonWrongOrg)
+		s.recordAuthFailure(r)
+		return nil, ErrCertificateOrg
+	}
+
+	return s.activeUser(r, user)
+}
+
+func (s *Server) authData(r *http.Request, username string) (*database.User, error) {
+	user, err := s.service.GetAuthData(r.Context(), organizationFromContext(r.Context()), username)
+	if err != nil {
+		reason := metrics.ReasonError
+		if errors.Is(err, database.ErrUserDoesNotExist) {
+			reason = metrics.ReasonUnknownUser
+		}
+		s.metrics.ObserveAuth(metrics.AuthFailure, reason)
+		if reason == metrics.ReasonUnknownUser {
+			s.recordAuthFailure(r)
+		}
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// activeUser authorizes user whose identity is already verified without password.
+func (s *Server) activeUser(r *http.Request, user *database.User) (*database.User, error) {
+	if user.Disabled {
+		s.metrics.ObserveAuth(metrics.AuthFailure, metrics.ReasonDisabled)
+		s.recordAuthFailure(r)
+		return nil, validation.ErrUserDisabled
+	}
+
+	return s.authorized(r, user)
+}
+
+func (s *Server) authorized(r *http.Request, user *database.User) (*database.User, error) {
+	s.metrics.ObserveAuth(metrics.AuthSuccess, metrics.ReasonNone)
+
+	if info := requestInfoFromContext(r.Context()); info != nil {
+		info.username = user.Username
+	}
+
+	if err := s.service.RecordLogin(r.Context(), user); err != nil {
+		s.log(r).WithError(err).Warn("failed to record login")
 	}
 
-	if err := validation.Auth(username, password+s.service.ReturnSalt(), *user); err != nil {
-		return false, err
+	if err := s.limitUser(r, user); err != nil {
+		return nil, err
 	}
 
-	return user.Admin, nil
+	return user, nil
 }
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/KseniiaSalmina/Profiles/internal/blobstore"
+	"github.com/KseniiaSalmina/Profiles/internal/database"
+	"github.com/KseniiaSalmina/Profiles/internal/validation"
+)
+
+const avatarCacheControl = "private, max-age=300, must-revalidate"
+
+// @Summary Put avatar
+// @Security BasicAuth
+// @Tags user
+// @Description upload user's avatar, available for admin and for the user himself, only super admins can change avatars of super admins
+// @Accept image/png,image/jpeg,image/webp
+// @Param id path string true "user's id in uuid format"
+// @Param avatar body string true "image in png, jpeg or webp format"
+// @Success 200
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 413 {string} string
+// @Failure 500 {string} string
+// @Router /v1/user/{id}/avatar [put]
+func (s *Server) putAvatar(w http.ResponseWriter, r *http.Request) {
+	caller, err := s.authorization(r)
+	if err != nil {
+		s.log(r).WithError(err).Info("put avatar handler, failed authorization")
+		http.Error(w, err.Error(), authErrorStatus(w, err))
+		return
+	}
+
+	id, err := getPathUUID(r, "id")
+	if err != nil {
+		s.log(r).WithError(err).Info("put avatar handler, failed to get id")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !caller.Admin && caller.ID != id {
+		s.log(r).Info("put avatar handler, user is not admin and not owner of profile")
+		http.Error(w, validation.ErrIsNotAdmin.Error(), http.StatusForbidden)
+		return
+	}
+	defer r.Body.Close()
+
+	if err := s.service.SetAvatar(r.Context(), organizationFromContext(r.Context()), id, r.Body, caller.SuperAdmin); err != nil {
+		s.log(r).WithError(err).Info("put avatar handler, failed to set avatar")
+		var statusCode int
+		switch {
+		case errors.Is(err, validation.ErrImageTooLarge):
+			statusCode = http.StatusRequestEntityTooLarge
+		case errors.Is(err, database.ErrUserDoesNotExist), errors.Is(err, validation.ErrUnsupportedImage), errors.Is(err, validation.ErrIncorrectImageSize):
+			statusCode = http.StatusBadRequest
+		default:
+			statusCode = userErrorStatus(err, http.StatusInternalServerError)
+		}
+		http.Error(w, err.Error(), statusCode)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// @Summary Get avatar
+// @Security BasicAuth
+// @Tags user
+// @Description return user's avatar in png format
+// @Produce image/png
+// @Param id path string true "user's id in uuid format"
+// @Param size query int false "thumbnail size in pixels, original image if not set"
+// @Success 200 {file} binary
+// @Success 304
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 404 {string} string
+// @Router /v1/user/{id}/avatar [get]
+func (s *Server) getAvatar(w http.ResponseWriter, r *http.Request) {
+	if _, err := s.authorization(r); err != nil {
+		s.log(r).WithError(err).Info("get avatar handler, failed authorization")
+		http.Error(w, err.Error(), authErrorStatus(w, err))
+		return
+	}
+
+	id, err := getPathUUID(r, "id")
+	if err != nil {
+		s.log(r).WithError(err).Info("get avatar handler, failed to get id")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var size int
+	if sizeStr := r.FormValue("size"); sizeStr != "" {
+		size, err = strconv.Atoi(sizeStr)
+		if err != nil || size <= 0 {
+			s.log(r).Info("get avatar handler, incorrect size")
+			http.Error(w, validation.ErrIncorrectAvatarSize.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	avatar, modTime, err := s.service.GetAvatar(r.Context(), organizationFromContext(r.Context()), id, size)
+	if err != nil {
+		s.log(r).WithError(err).Info("get avatar handler, failed to get avatar")
+		statusCode := serviceErrorStatus(err, http.StatusBadRequest)
+		if errors.Is(err, blobstore.ErrBlobDoesNotExist) {
+			statusCode = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), statusCode)
+		return
+	}
+	defer avatar.Close()
+
+	w.Header().Set("Content-Type", validation.ImagePNG)
+	w.Header().Set("Cache-Control", avatarCacheControl)
+	w.Header().Set("ETag", fmt.Sprintf(`"%s-%d-%x"`, id, size, modTime.UnixNano()))
+
+	http.ServeContent(w, r, "", modTime, avatar)
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_avatar(t1 *testing.T) {
+	server := prepareServer()
+
+	type args struct {
+		method   string
+		url      string
+		username string
+		password string
+		body     []byte
+		etag     bool
+	}
+	type res struct {
+		statusCode int
+		width      int
+	}
+	tests := []struct {
+		name string
+		args args
+		want res
+	}{
+		{name: "owner uploads avatar", args: args{method: "PUT", url: "/user/db783cb2-8037-4b75-8c01-ab9065e568e3/avatar",
+			username: "testUser3", password: "password", body: testImage(200, 100)}, want: res{statusCode: http.StatusOK}},
+		{name: "not owner and not admin", args: args{method: "PUT", url: "/user/a7073076-8602-4b95-8c19-0cd24aa511c9/avatar",
+			username: "testUser3", password: "password", body: testImage(10, 10)}, want: res{statusCode: http.StatusForbidden}},
+		{name: "not an image", args: args{method: "PUT", url: "/user/db783cb2-8037-4b75-8c01-ab9065e568e3/avatar",
+			username: "username", password: "password", body: []byte("plain text")}, want: res{statusCode: http.StatusBadRequest}},
+		{name: "image is too wide", args: args{method: "PUT", url: "/user/db783cb2-8037-4b75-8c01-ab9065e568e3/avatar",
+			username: "username", password: "password", body: testImage(2000, 10)}, want: res{statusCode: http.StatusBadRequest}},
+		{name: "file is too large", args: args{method: "PUT", url: "/user/db783cb2-8037-4b75-8c01-ab9065e568e3/avatar",
+			username: "username", password: "password", body: bytes.Repeat([]byte{0}, int(serviceCfg.AvatarMaxSize)+1)}, want: res{statusCode: http.StatusRequestEntityTooLarge}},
+		{name: "get original", args: args{method: "GET", url: "/user/db783cb2-8037-4b75-8c01-ab9065e568e3/avatar",
+			username: "username", password: "password"}, want: res{statusCode: http.StatusOK, width: 200}},
+		{name: "get thumbnail", args: args{method: "GET", url: "/user/db783cb2-8037-4b75-8c01-ab9065e568e3/avatar?size=64",
+			username: "username", password: "password"}, want: res{statusCode: http.StatusOK, width: 64}},
+		{name: "not configured size", args: args{method: "GET", url: "/user/db783cb2-8037-4b75-8c01-ab9065e568e3/avatar?size=65",
+			username: "username", password: "password"}, want: res{statusCode: http.StatusBadRequest}},
+		{name: "not modified", args: args{method: "GET", url: "/user/db783cb2-8037-4b75-8c01-ab9065e568e3/avatar?size=16",
+			username: "username", password: "password", etag: true}, want: res{statusCode: http.StatusNotModified}},
+		{name: "no avatar", args: args{method: "GET", url: "/user/a7073076-8602-4b95-8c19-0cd24aa511c9/avatar",
+			username: "username", password: "password"}, want: res{statusCode: http.StatusNotFound}},
+	}
+
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			req := httptest.NewRequest(tt.args.method, tt.args.url, bytes.NewReader(tt.args.body))
+			req.SetBasicAuth(tt.args.username, tt.args.password)
+
+			if tt.args.etag {
+				first := httptest.NewRecorder()
+				server.httpServer.Handler.ServeHTTP(first, req)
+				req = httptest.NewRequest(tt.args.method, tt.args.url, nil)
+				req.SetBasicAuth(tt.args.username, tt.args.password)
+				req.Header.Set("If-None-Match", first.Header().Get("ETag"))
+			}
+
+			w := httptest.NewRecorder()
+			server.httpServer.Handler.ServeHTTP(w, req)
+			assert.Equal(t1, tt.want.statusCode, w.Code)
+
+			if tt.want.width != 0 {
+				assert.True(t1, strings.HasPrefix(w.Header().Get("Cache-Control"), "private"))
+				cfg, err := png.DecodeConfig(w.Body)
+				if err != nil {
+					t1.Fatalf("can not decode: %v", err.Error())
+				}
+				assert.Equal(t1, tt.want.width, cfg.Width)
+			}
+		})
+	}
+}
+
+func testImage(width, height int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		img.Set(x, 0, color.RGBA{R: 255, A: 255})
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		log.Fatal("can not encode test image")
+	}
+
+	return buf.Bytes()
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/KseniiaSalmina/Profiles/internal/api/models"
+	"github.com/KseniiaSalmina/Profiles/internal/service"
+	"github.com/KseniiaSalmina/Profiles/internal/validation"
+)
+
+var (
+	ErrEmptyBatch            = errors.New("batch has no operations")
+	ErrTooManyOperations     = errors.New("too many operations in batch")
+	ErrNonPositiveBatchLimit = errors.New("batch operations limit should be positive")
+	ErrBatchCreateWithID     = errors.New("id of created user is generated")
+)
+
+// @Summary Batch user operations
+// @Security BasicAuth
+// @Tags admin
+// @Description apply ordered create, patch and delete operations in one storage transaction.
+// @Description All-or-nothing batch (default) is rolled back on the first failed operation, otherwise only valid operations are applied.
+// @Accept json,application/yaml,application/msgpack
+// @Return json
+// @Produce json,application/yaml,application/msgpack
+// @Param batch body models.BatchRequest true "operations, user holds profile of created user or changes of patched one"
+// @Param Idempotency-Key header string false "retries with the same key replay the first response"
+// @Success 200 {object} models.BatchResponse
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 413 {string} string
+// @Failure 500 {string} string
+// @Router /v1/user/batch [post]
+func (s *Server) postUserBatch(w http.ResponseWriter, r *http.Request) {
+	caller, err := s.authorization(r)
+	if err != nil {
+		s.log(r).WithError(err).Info("post user batch handler, failed authorization")
+		http.Error(w, err.Error(), authErrorStatus(w, err))
+		return
+	}
+
+	if !caller.Admin {
+		s.log(r).Info("post user batch handler, user is not admin")
+		http.Error(w, validation.ErrIsNotAdmin.Error(), http.StatusForbidden)
+		return
+	}
+
+	var batch models.BatchRequest
+	if err := decodeBody(r, &batch); err != nil {
+		s.log(r).WithError(err).Info("post user batch handler, failed to unmarshall request body")
+		http.Error(w, err.Error(), decodeErrorStatus(err))
+		return
+	}
+	defer r.Body.Close()
+
+	if len(batch.Operations) == 0 {
+		s.log(r).Info("post user batch handler, empty batch")
+		http.Error(w, ErrEmptyBatch.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if limit := s.settings.Load().batchMaxOps; len(batch.Operations) > limit {
+		s.log(r).WithField("operations", len(batch.Operations)).Info("post user batch handler, too many operations")
+		http.Error(w, fmt.Sprintf("%s, limit is %d", ErrTooManyOperations, limit), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	for i, op := range batch.Operations {
+		if err := checkBatchOperation(op); err != nil {
+			s.log(r).WithError(err).Info("post user batch handler, invalid operation")
+			http.Error(w, fmt.Sprintf("operation %d: %s", i+1, err), http.StatusBadRequest)
+			return
+		}
+
+		if changesSuperAdmin(op) && !caller.SuperAdmin {
+			s.log(r).Info("post user batch handler, user is not super admin")
+			http.Error(w, fmt.Sprintf("operation %d: %s", i+1, validation.ErrIsNotSuperAdmin), http.StatusForbidden)
+			return
+		}
+	}
+
+	allOrNothing := batch.AllOrNothing == nil || *batch.AllOrNothing
+	response, err := s.service.Batch(r.Context(), organizationFromContext(r.Context()), batch.Operations, allOrNothing, caller.SuperAdmin)
+	if err != nil {
+		s.log(r).WithError(err).Info("post user batch handler, failed to apply batch")
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	s.respond(w, r, http.StatusOK, response)
+}
+
+// changesSuperAdmin applies checks of super admin flag of post and patch user handlers,
+// operations on users who are super admins are checked by service.
+func changesSuperAdmin(op models.BatchOperation) bool {
+	switch op.Op {
+	case models.BatchCreate:
+		return op.User.SuperAdmin != nil && *op.User.SuperAdmin
+	case models.BatchPatch:
+		return op.User.SuperAdmin != nil
+	default:
+		return false
+	}
+}
+
+// checkBatchOperation rejects malformed operations, checks of user data are reported per operation by service.
+func checkBatchOperation(op models.BatchOperation) error {
+	switch op.Op {
+	case models.BatchCreate:
+		if op.ID != "" {
+			return ErrBatchCreateWithID
+		}
+	case models.BatchPatch, models.BatchDelete:
+		if _, err := uuid.Parse(op.ID); err != nil {
+			return fmt.Errorf("id %w", ErrNotUUID)
+		}
+	default:
+		return fmt.Errorf("%w: %q", service.ErrUnknownBatchOperation, op.Op)
+	}
+
+	return nil
+}
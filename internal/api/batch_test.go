@@ -0,0 +1,171 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/KseniiaSalmina/Profiles/internal/api/models"
+	"github.com/KseniiaSalmina/Profiles/internal/validation"
+)
+
+func TestServer_postUserBatch(t1 *testing.T) {
+	const valid = `
+		{"op": "create", "user": {"username": "alice", "email": "alice@email.com", "password": "secret"}},
+		{"op": "patch", "id": "db783cb2-8037-4b75-8c01-ab9065e568e3", "user": {"email": "carol@email.com"}},
+		{"op": "delete", "id": "28ceb514-ea0d-4ca7-a330-9763b8bd7fc4"}`
+	const operations = `[` + valid + `,
+		{"op": "create", "user": {"username": "testUser", "email": "bob@email.com", "password": "secret"}},
+		{"op": "delete", "id": "34775464-a73b-4445-8866-1e6061c3b70b"}
+	]`
+
+	tests := []struct {
+		name          string
+		body          string
+		username      string
+		wantCode      int
+		wantCommitted bool
+		wantStatuses  []string
+		wantUsernames []string
+		wantEmail     string // email of testUser3
+	}{
+		{
+			name:          "all or nothing rolls back",
+			body:          `{"operations": ` + operations + `}`,
+			username:      "username",
+			wantCode:      http.StatusOK,
+			wantStatuses:  []string{models.BatchRolledBack, models.BatchRolledBack, models.BatchRolledBack, models.BatchFailed, models.BatchSkipped},
+			wantUsernames: []string{"username", "testUser", "testUser2", "testUser3"},
+			wantEmail:     "test3@email.com",
+		},
+		{
+			name:          "best effort applies valid operations",
+			body:          `{"all_or_nothing": false, "operations": ` + operations + `}`,
+			username:      "username",
+			wantCode:      http.StatusOK,
+			wantCommitted: true,
+			wantStatuses:  []string{models.BatchOK, models.BatchOK, models.BatchOK, models.BatchFailed, models.BatchFailed},
+			wantUsernames: []string{"username", "testUser", "testUser3", "alice"},
+			wantEmail:     "carol@email.com",
+		},
+		{
+			name:          "all or nothing commits",
+			body:          `{"operations": [` + valid + `]}`,
+			username:      "username",
+			wantCode:      http.StatusOK,
+			wantCommitted: true,
+			wantStatuses:  []string{models.BatchOK, models.BatchOK, models.BatchOK},
+			wantUsernames: []string{"username", "testUser", "testUser3", "alice"},
+			wantEmail:     "carol@email.com",
+		},
+		{
+			name:          "invalid user data fails operation",
+			body:          `{"operations": [{"op": "create", "user": {"username": "alice"}}, {"op": "patch", "id": "db783cb2-8037-4b75-8c01-ab9065e568e3", "user": {}}]}`,
+			username:      "username",
+			wantCode:      http.StatusOK,
+			wantStatuses:  []string{models.BatchFailed, models.BatchSkipped},
+			wantUsernames: []string{"username", "testUser", "testUser2", "testUser3"},
+			wantEmail:     "test3@email.com",
+		},
+		{name: "empty batch", body: `{"operations": []}`, username: "username", wantCode: http.StatusBadRequest},
+		{name: "unknown operation", body: `{"operations": [{"op": "replace", "id": "db783cb2-8037-4b75-8c01-ab9065e568e3"}]}`, username: "username", wantCode: http.StatusBadRequest},
+		{name: "incorrect id", body: `{"operations": [{"op": "delete", "id": "3"}]}`, username: "username", wantCode: http.StatusBadRequest},
+		{name: "not admin", body: `{"operations": ` + operations + `}`, username: "testUser3", wantCode: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			server := prepareServer()
+			serve := func(method, url, body, username string) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(method, url, strings.NewReader(body))
+				req.SetBasicAuth(username, "password")
+				w := httptest.NewRecorder()
+				server.httpServer.Handler.ServeHTTP(w, req)
+				return w
+			}
+
+			w := serve("POST", "/user/batch", tt.body, tt.username)
+			require.Equal(t1, tt.wantCode, w.Code, w.Body.String())
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+
+			var response models.BatchResponse
+			require.NoError(t1, json.NewDecoder(w.Body).Decode(&response))
+			assert.Equal(t1, tt.wantCommitted, response.Committed)
+			statuses := make([]string, 0, len(response.Results))
+			for _, result := range response.Results {
+				statuses = append(statuses, result.Status)
+				assert.Equal(t1, result.Status == models.BatchFailed, result.Error != "")
+			}
+			assert.Equal(t1, tt.wantStatuses, statuses)
+
+			w = serve("GET", "/user?limit=100", "", "username")
+			var page models.PageUsers
+			require.NoError(t1, json.NewDecoder(w.Body).Decode(&page))
+			usernames := make([]string, 0, len(page.Users))
+			for _, user := range page.Users {
+				usernames = append(usernames, user.Username)
+			}
+			assert.Equal(t1, tt.wantUsernames, usernames)
+
+			w = serve("GET", "/user/db783cb2-8037-4b75-8c01-ab9065e568e3", "", "username")
+			var user models.UserResponse
+			require.NoError(t1, json.NewDecoder(w.Body).Decode(&user))
+			assert.Equal(t1, tt.wantEmail, user.Email)
+		})
+	}
+}
+
+func TestServer_postUserBatchLimit(t1 *testing.T) {
+	cfg := serverCfg
+	cfg.BatchMaxOperations = 2
+	server := prepareServerWithConfigs(cfg, serviceCfg)
+
+	body := `{"operations": [{"op": "delete", "id": "a7073076-8602-4b95-8c19-0cd24aa511c9"}, {"op": "delete", "id": "28ceb514-ea0d-4ca7-a330-9763b8bd7fc4"}, {"op": "delete", "id": "db783cb2-8037-4b75-8c01-ab9065e568e3"}]}`
+	req := httptest.NewRequest("POST", "/user/batch", strings.NewReader(body))
+	req.SetBasicAuth("username", "password")
+	w := httptest.NewRecorder()
+	server.httpServer.Handler.ServeHTTP(w, req)
+	assert.Equal(t1, http.StatusRequestEntityTooLarge, w.Code)
+}
+
+func TestServer_postUserBatchSuperAdmin(t1 *testing.T) {
+	server := prepareServer()
+	serve := func(body, username string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/user/batch", strings.NewReader(body))
+		req.SetBasicAuth(username, "password")
+		w := httptest.NewRecorder()
+		server.httpServer.Handler.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve(`{"operations": [{"op": "create", "user": {"username": "admin", "email": "admin@email.com", "password": "password", "admin": true}}]}`, "username")
+	require.Equal(t1, http.StatusOK, w.Code)
+	id := superAdminID(t1, server)
+
+	w = serve(`{"all_or_nothing": false, "operations": [
+		{"op": "patch", "id": "`+id+`", "user": {"password": "hijacked"}},
+		{"op": "delete", "id": "`+id+`"},
+		{"op": "patch", "id": "db783cb2-8037-4b75-8c01-ab9065e568e3", "user": {"email": "carol@email.com"}}
+	]}`, "admin")
+	require.Equal(t1, http.StatusOK, w.Code)
+
+	var response models.BatchResponse
+	require.NoError(t1, json.NewDecoder(w.Body).Decode(&response))
+	require.Len(t1, response.Results, 3)
+	for _, result := range response.Results[:2] {
+		assert.Equal(t1, models.BatchFailed, result.Status)
+		assert.Equal(t1, validation.ErrIsNotSuperAdmin.Error(), result.Error)
+	}
+	assert.Equal(t1, models.BatchOK, response.Results[2].Status)
+
+	// super admin is neither changed nor deleted
+	assert.Equal(t1, id, superAdminID(t1, server))
+	assert.Equal(t1, http.StatusOK, serve(`{"operations": [{"op": "patch", "id": "`+id+`", "user": {"email": "root@email.com"}}]}`, "username").Code)
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// statusClientClosedRequest is not a standard code, it is used for requests whose client has gone before response was ready.
+const statusClientClosedRequest = 499
+
+// timeout limits time of work done for request, service and storage stop waiting when request context is done.
+// Read and write deadlines are set per request, so reloaded timeouts apply to already open connections.
+func (s *Server) timeout(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		settings := s.settings.Load()
+
+		rc := http.NewResponseController(w)
+		if settings.readTimeout > 0 {
+			_ = rc.SetReadDeadline(time.Now().Add(settings.readTimeout))
+		}
+		if settings.writeTimeout > 0 {
+			_ = rc.SetWriteDeadline(time.Now().Add(settings.writeTimeout))
+		}
+
+		if settings.requestTimeout <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), settings.requestTimeout)
+		defer cancel()
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// serviceErrorStatus returns status of service error, errors of cancelled or timed out request get their own statuses.
+func serviceErrorStatus(err error, status int) int {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, context.Canceled):
+		return statusClientClosedRequest
+	default:
+		return status
+	}
+}
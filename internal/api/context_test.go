@@ -0,0 +1,34 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_contextCancellation(t1 *testing.T) {
+	server := prepareServer()
+
+	serve := func(ctx context.Context) int {
+		req := httptest.NewRequest("GET", "/user", nil).WithContext(ctx)
+		req.Header.Set(tenantHeader, serviceCfg.DefaultTenant)
+		req.SetBasicAuth("testUser3", "password")
+		w := httptest.NewRecorder()
+		server.httpServer.Handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t1, http.StatusOK, serve(context.Background()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t1, statusClientClosedRequest, serve(ctx))
+
+	ctx, cancel = context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	assert.Equal(t1, http.StatusServiceUnavailable, serve(ctx))
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/KseniiaSalmina/Profiles/internal/config"
+)
+
+// corsPolicy is config.CORS prepared for writing headers.
+type corsPolicy struct {
+	origins     map[string]bool
+	anyOrigin   bool
+	methods     string
+	headers     string
+	exposed     string
+	credentials bool
+	maxAge      string
+}
+
+func newCORSPolicy(cfg config.CORS) corsPolicy {
+	policy := corsPolicy{
+		origins:     make(map[string]bool, len(cfg.CORSAllowedOrigins)),
+		methods:     strings.Join(cfg.CORSAllowedMethods, ", "),
+		headers:     strings.Join(cfg.CORSAllowedHeaders, ", "),
+		exposed:     strings.Join(cfg.CORSExposedHeaders, ", "),
+		credentials: cfg.CORSAllowCredentials,
+		maxAge:      strconv.Itoa(int(cfg.CORSMaxAge.Seconds())),
+	}
+
+	for _, origin := range cfg.CORSAllowedOrigins {
+		origin = strings.TrimSpace(origin)
+		if origin == "*" {
+			policy.anyOrigin = true
+			continue
+		}
+		policy.origins[strings.TrimSuffix(origin, "/")] = true
+	}
+
+	return policy
+}
+
+func (p corsPolicy) allowed(origin string) bool {
+	return p.anyOrigin || p.origins[origin]
+}
+
+// cors answers preflight requests of allowed origins for any route and adds CORS headers to their actual requests.
+// Requests of other origins get no CORS headers, so browser does not expose responses to them.
+func (s *Server) cors(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		policy := s.settings.Load().cors
+		origin := r.Header.Get("Origin")
+		w.Header().Add("Vary", "Origin")
+		if origin == "" || !policy.allowed(origin) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if policy.anyOrigin && !policy.credentials {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		} else {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+		}
+		if policy.credentials {
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Add("Vary", "Access-Control-Request-Method")
+			w.Header().Add("Vary", "Access-Control-Request-Headers")
+			w.Header().Set("Access-Control-Allow-Methods", policy.methods)
+			w.Header().Set("Access-Control-Allow-Headers", policy.headers)
+			w.Header().Set("Access-Control-Max-Age", policy.maxAge)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		if policy.exposed != "" {
+			w.Header().Set("Access-Control-Expose-Headers", policy.exposed)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
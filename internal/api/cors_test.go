@@ -0,0 +1,80 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/KseniiaSalmina/Profiles/internal/config"
+)
+
+func TestServer_cors(t1 *testing.T) {
+	cors := config.CORS{
+		CORSAllowedOrigins:   []string{"https://admin.example.com"},
+		CORSAllowedMethods:   []string{"GET", "POST", "PATCH", "DELETE"},
+		CORSAllowedHeaders:   []string{"Content-Type", "X-CSRF-Token"},
+		CORSExposedHeaders:   []string{"X-Request-ID"},
+		CORSAllowCredentials: true,
+		CORSMaxAge:           10 * time.Minute,
+	}
+	server := prepareServer()
+	cfg := serverCfg
+	cfg.CORS = cors
+	apply, err := server.Reconfigure(cfg)
+	require.NoError(t1, err)
+	apply()
+
+	serve := func(method, url, origin string, preflight bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		if preflight {
+			req.Header.Set("Access-Control-Request-Method", "PATCH")
+			req.Header.Set("Access-Control-Request-Headers", "content-type")
+		}
+		req.SetBasicAuth("testUser3", "password")
+		w := httptest.NewRecorder()
+		server.httpServer.Handler.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, url := range []string{"/user", "/user/" + testUsers[2].ID, "/user/" + testUsers[2].ID + "/avatar", "/tenant/default/user"} {
+		w := serve("OPTIONS", url, "https://admin.example.com", true)
+		assert.Equal(t1, http.StatusNoContent, w.Code, url)
+		assert.Equal(t1, "https://admin.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t1, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+		assert.Equal(t1, "GET, POST, PATCH, DELETE", w.Header().Get("Access-Control-Allow-Methods"))
+		assert.Equal(t1, "Content-Type, X-CSRF-Token", w.Header().Get("Access-Control-Allow-Headers"))
+		assert.Equal(t1, "600", w.Header().Get("Access-Control-Max-Age"))
+	}
+
+	w := serve("GET", "/user", "https://admin.example.com", false)
+	assert.Equal(t1, http.StatusOK, w.Code)
+	assert.Equal(t1, "https://admin.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t1, "X-Request-ID", w.Header().Get("Access-Control-Expose-Headers"))
+	assert.Contains(t1, w.Header().Values("Vary"), "Origin")
+
+	w = serve("OPTIONS", "/user", "https://evil.example.com", true)
+	assert.NotEqual(t1, http.StatusNoContent, w.Code)
+	assert.Empty(t1, w.Header().Get("Access-Control-Allow-Origin"))
+
+	w = serve("GET", "/user", "https://evil.example.com", false)
+	assert.Equal(t1, http.StatusOK, w.Code)
+	assert.Empty(t1, w.Header().Get("Access-Control-Allow-Origin"))
+
+	cors.CORSAllowedOrigins = []string{"*"}
+	cors.CORSAllowCredentials = false
+	cfg.CORS = cors
+	apply, err = server.Reconfigure(cfg)
+	require.NoError(t1, err)
+	apply()
+
+	w = serve("GET", "/user", "https://any.example.com", false)
+	assert.Equal(t1, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t1, w.Header().Get("Access-Control-Allow-Credentials"))
+}
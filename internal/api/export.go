@@ -0,0 +1,349 @@
+package api
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/KseniiaSalmina/Profiles/internal/api/models"
+	"github.com/KseniiaSalmina/Profiles/internal/validation"
+)
+
+const (
+	exportFormatCSV   = "csv"
+	exportFormatJSONL = "jsonl"
+
+	// exportFlushRows is how many rows are written between flushes, write deadline is extended on every flush.
+	exportFlushRows = 100
+)
+
+var (
+	ErrUnknownExportFormat = errors.New("export format should be csv or jsonl")
+	ErrUnknownExportColumn = errors.New("unknown export column")
+)
+
+// exportColumns are exported when columns are not selected, attr.<name> columns of attribute schema follow them.
+var exportColumns = []string{"id", "username", "email", "admin", "disabled", "created_at", "updated_at", "last_login_at", "password_changed_at"}
+
+// @Summary Export users
+// @Security BasicAuth
+// @Tags admin
+// @Description stream all users of organization matching filters, password hashes are never exported
+// @Produce text/csv,application/x-ndjson
+// @Param format query string false "csv (default) or jsonl"
+// @Param columns query string false "comma separated columns: id, username, email, admin, disabled, created_at, updated_at, last_login_at, password_changed_at or attr.<name>"
+// @Param attr.name query string false "filter by attribute value, attribute name goes after 'attr.' prefix"
+// @Param created_after query string false "created at or after, RFC3339"
+// @Param created_before query string false "created before, RFC3339"
+// @Param updated_after query string false "updated at or after, RFC3339"
+// @Param updated_before query string false "updated before, RFC3339"
+// @Param last_login_after query string false "last login at or after, RFC3339"
+// @Param last_login_before query string false "last login before or never logged in, RFC3339"
+// @Param sort query string false "username, email, created_at, updated_at, last_login_at or password_changed_at, '-' prefix for descending order"
+// @Success 200 {string} string
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 500 {string} string
+// @Router /v1/user/export [get]
+func (s *Server) getUserExport(w http.ResponseWriter, r *http.Request) {
+	caller, err := s.authorization(r)
+	if err != nil {
+		s.log(r).WithError(err).Info("get user export handler, failed authorization")
+		http.Error(w, err.Error(), authErrorStatus(w, err))
+		return
+	}
+
+	if !caller.Admin {
+		s.log(r).Info("get user export handler, user is not admin")
+		http.Error(w, validation.ErrIsNotAdmin.Error(), http.StatusForbidden)
+		return
+	}
+
+	filter, err := s.getUserFilter(r)
+	if err != nil {
+		s.log(r).WithError(err).Info("get user export handler, failed to get filter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := validation.UserFilter(*filter); err != nil {
+		s.log(r).WithError(err).Info("get user export handler, invalid filter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = exportFormatCSV
+	}
+	if format != exportFormatCSV && format != exportFormatJSONL {
+		s.log(r).WithField("format", format).Info("get user export handler, unknown format")
+		http.Error(w, ErrUnknownExportFormat.Error(), http.StatusBadRequest)
+		return
+	}
+
+	columns, err := getExportColumns(r)
+	if err != nil {
+		s.log(r).WithError(err).Info("get user export handler, invalid columns")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if columns == nil && format == exportFormatCSV {
+		schema, err := s.service.GetAttributeSchema(r.Context())
+		if err != nil {
+			s.log(r).WithError(err).Info("get user export handler, failed to get attribute schema")
+			http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
+			return
+		}
+		columns = defaultExportColumns(schema)
+	}
+
+	var writer exportWriter
+	if format == exportFormatCSV {
+		writer = newCSVExport(w, columns)
+	} else {
+		writer = newJSONLinesExport(w, columns)
+	}
+
+	stream := &exportStream{w: w, rc: http.NewResponseController(w), writer: writer, writeTimeout: s.settings.Load().writeTimeout, format: format}
+	err = s.service.ExportUsers(r.Context(), organizationFromContext(r.Context()), *filter, stream.write)
+	if err == nil {
+		err = stream.finish()
+	}
+	if err != nil {
+		if !stream.started {
+			s.log(r).WithError(err).Info("get user export handler, failed to export users")
+			http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
+			return
+		}
+
+		// status is already sent, aborted connection tells client that export is incomplete
+		s.log(r).WithError(err).Warn("get user export handler, export interrupted")
+		panic(http.ErrAbortHandler)
+	}
+}
+
+func getExportColumns(r *http.Request) ([]string, error) {
+	return parseFields(r.URL.Query().Get("columns"), exportColumns, ErrUnknownExportColumn)
+}
+
+func defaultExportColumns(schema models.AttributeSchema) []string {
+	attributes := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		attributes = append(attributes, attributeColumnPrefix+name)
+	}
+	slices.Sort(attributes)
+
+	return append(slices.Clone(exportColumns), attributes...)
+}
+
+// exportStream writes headers before the first row, so errors before it are still reported with status.
+type exportStream struct {
+	w            http.ResponseWriter
+	rc           *http.ResponseController
+	writer       exportWriter
+	writeTimeout time.Duration
+	format       string
+	started      bool
+	rows         int
+}
+
+func (e *exportStream) start() error {
+	e.started = true
+
+	contentType := "text/csv; charset=utf-8"
+	if e.format == exportFormatJSONL {
+		contentType = "application/x-ndjson"
+	}
+	e.w.Header().Set("Content-Type", contentType)
+	e.w.Header().Set("Content-Disposition", `attachment; filename="users.`+e.format+`"`)
+	e.w.WriteHeader(http.StatusOK)
+
+	return e.writer.header()
+}
+
+func (e *exportStream) write(user models.UserResponse) error {
+	if !e.started {
+		if err := e.start(); err != nil {
+			return err
+		}
+	}
+
+	if err := e.writer.write(user); err != nil {
+		return err
+	}
+
+	e.rows++
+	if e.rows%exportFlushRows == 0 {
+		return e.flush()
+	}
+
+	return nil
+}
+
+func (e *exportStream) finish() error {
+	if !e.started {
+		if err := e.start(); err != nil {
+			return err
+		}
+	}
+
+	return e.flush()
+}
+
+func (e *exportStream) flush() error {
+	if err := e.writer.flush(); err != nil {
+		return err
+	}
+
+	if e.writeTimeout > 0 {
+		_ = e.rc.SetWriteDeadline(time.Now().Add(e.writeTimeout))
+	}
+
+	if err := e.rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+
+	return nil
+}
+
+type exportWriter interface {
+	header() error
+	write(user models.UserResponse) error
+	flush() error
+}
+
+type csvExport struct {
+	writer  *csv.Writer
+	columns []string
+	record  []string
+}
+
+func newCSVExport(w io.Writer, columns []string) *csvExport {
+	return &csvExport{writer: csv.NewWriter(w), columns: columns, record: make([]string, len(columns))}
+}
+
+func (e *csvExport) header() error {
+	return e.writer.Write(e.columns)
+}
+
+func (e *csvExport) write(user models.UserResponse) error {
+	for i, column := range e.columns {
+		e.record[i] = csvExportValue(user, column)
+	}
+
+	return e.writer.Write(e.record)
+}
+
+func (e *csvExport) flush() error {
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+func csvExportValue(user models.UserResponse, column string) string {
+	if name, ok := strings.CutPrefix(column, attributeColumnPrefix); ok {
+		switch value := user.Attributes[name].(type) {
+		case nil:
+			return ""
+		case string:
+			return csvText(value)
+		case float64:
+			return strconv.FormatFloat(value, 'f', -1, 64)
+		default:
+			return fmt.Sprint(value)
+		}
+	}
+
+	switch value := exportValue(user, column).(type) {
+	case string:
+		return csvText(value)
+	case bool:
+		return strconv.FormatBool(value)
+	case time.Time:
+		return value.Format(time.RFC3339)
+	case *time.Time:
+		if value == nil {
+			return ""
+		}
+		return value.Format(time.RFC3339)
+	default:
+		return ""
+	}
+}
+
+// csvFormulaPrefixes start cells which spreadsheets evaluate as formulas.
+const csvFormulaPrefixes = "=+-@\t\r"
+
+// csvText guards text cell against CSV injection, text which spreadsheet would evaluate as formula is prefixed with quote.
+// Numbers, booleans and times are formatted by the service and written as is.
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune(csvFormulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+
+	return value
+}
+
+// exportValue returns value of column which is not an attribute.
+func exportValue(user models.UserResponse, column string) any {
+	switch column {
+	case "id":
+		return user.ID
+	case "username":
+		return user.Username
+	case "email":
+		return user.Email
+	case "admin":
+		return user.Admin
+	case "disabled":
+		return user.Disabled
+	case "created_at":
+		return user.CreatedAt
+	case "updated_at":
+		return user.UpdatedAt
+	case "last_login_at":
+		return user.LastLoginAt
+	case "password_changed_at":
+		return user.PasswordChangedAt
+	default:
+		return nil
+	}
+}
+
+// jsonLinesExport writes whole profiles if columns are not selected, selected attributes are nested into "attributes".
+type jsonLinesExport struct {
+	writer  *bufio.Writer
+	encoder *json.Encoder
+	columns []string
+}
+
+func newJSONLinesExport(w io.Writer, columns []string) *jsonLinesExport {
+	writer := bufio.NewWriter(w)
+	return &jsonLinesExport{writer: writer, encoder: json.NewEncoder(writer), columns: columns}
+}
+
+func (e *jsonLinesExport) header() error {
+	return nil
+}
+
+func (e *jsonLinesExport) write(user models.UserResponse) error {
+	if e.columns == nil {
+		return e.encoder.Encode(user)
+	}
+
+	return e.encoder.Encode(userFields(user, e.columns))
+}
+
+func (e *jsonLinesExport) flush() error {
+	return e.writer.Flush()
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/KseniiaSalmina/Profiles/internal/api/models"
+)
+
+func TestServer_userExport(t1 *testing.T) {
+	server := prepareServer()
+	serve := func(method, url, body, username string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.SetBasicAuth(username, "password")
+		w := httptest.NewRecorder()
+		server.httpServer.Handler.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("PUT", "/attributes/schema", `{"properties": {"floor": {"type": "integer"}, "department": {"type": "string"}}}`, "username")
+	require.Equal(t1, http.StatusOK, w.Code)
+	w = serve("PATCH", "/user/"+testUsers[2].ID, `{"attributes": {"department": "sales", "floor": 2}}`, "username")
+	require.Equal(t1, http.StatusOK, w.Code)
+
+	t1.Run("csv with default columns", func(t1 *testing.T) {
+		w := serve("GET", "/user/export", "", "username")
+		require.Equal(t1, http.StatusOK, w.Code)
+		assert.Equal(t1, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(t1, `attachment; filename="users.csv"`, w.Header().Get("Content-Disposition"))
+		assert.NotContains(t1, w.Body.String(), "$2a$")
+
+		records, err := csv.NewReader(w.Body).ReadAll()
+		require.NoError(t1, err)
+		require.Len(t1, records, 1+len(testUsers)+1)
+		assert.Equal(t1, []string{"id", "username", "email", "admin", "disabled", "created_at", "updated_at", "last_login_at", "password_changed_at", "attr.department", "attr.floor"}, records[0])
+		assert.Equal(t1, "username", records[1][1])
+		assert.Equal(t1, "true", records[1][3], "first user is admin")
+
+		third := records[len(records)-1]
+		assert.Equal(t1, testUsers[2].ID, third[0])
+		assert.Equal(t1, "testUser3", third[1])
+		assert.Equal(t1, "false", third[3])
+		assert.Equal(t1, "sales", third[9])
+		assert.Equal(t1, "2", third[10])
+	})
+
+	t1.Run("selected columns and filter", func(t1 *testing.T) {
+		w := serve("GET", "/user/export?columns=username,attr.floor&attr.department=sales", "", "username")
+		require.Equal(t1, http.StatusOK, w.Code)
+		assert.Equal(t1, "username,attr.floor\ntestUser3,2\n", w.Body.String())
+	})
+
+	t1.Run("json lines", func(t1 *testing.T) {
+		w := serve("GET", "/user/export?format=jsonl&sort=-username", "", "username")
+		require.Equal(t1, http.StatusOK, w.Code)
+		assert.Equal(t1, "application/x-ndjson", w.Header().Get("Content-Type"))
+		assert.NotContains(t1, w.Body.String(), "hash")
+
+		var usernames []string
+		scanner := bufio.NewScanner(w.Body)
+		for scanner.Scan() {
+			var user models.UserResponse
+			require.NoError(t1, json.Unmarshal(scanner.Bytes(), &user))
+			usernames = append(usernames, user.Username)
+		}
+		assert.Equal(t1, []string{"username", "testUser3", "testUser2", "testUser"}, usernames)
+	})
+
+	t1.Run("json lines with selected columns", func(t1 *testing.T) {
+		w := serve("GET", "/user/export?format=jsonl&columns=username,admin,attr.department&attr.department=sales", "", "username")
+		require.Equal(t1, http.StatusOK, w.Code)
+		assert.JSONEq(t1, `{"username": "testUser3", "admin": false, "attributes": {"department": "sales"}}`, w.Body.String())
+	})
+
+	t1.Run("formulas are escaped", func(t1 *testing.T) {
+		w := serve("POST", "/user", `{"username": "=HYPERLINK(\"http://evil.com\",\"click\")", "password": "secret", "email": "formula@email.com", "attributes": {"department": "@SUM(A1:A2)", "floor": -1}}`, "username")
+		require.Equal(t1, http.StatusOK, w.Code)
+
+		w = serve("GET", "/user/export?columns=username,attr.department,attr.floor&attr.department=@SUM(A1:A2)", "", "username")
+		require.Equal(t1, http.StatusOK, w.Code)
+		records, err := csv.NewReader(w.Body).ReadAll()
+		require.NoError(t1, err)
+		require.Len(t1, records, 2)
+		assert.Equal(t1, []string{`'=HYPERLINK("http://evil.com","click")`, "'@SUM(A1:A2)", "-1"}, records[1])
+	})
+
+	t1.Run("no users", func(t1 *testing.T) {
+		w := serve("GET", "/user/export?columns=id&attr.department=support", "", "username")
+		require.Equal(t1, http.StatusOK, w.Code)
+		assert.Equal(t1, "id\n", w.Body.String())
+	})
+
+	for _, tt := range []struct {
+		name     string
+		url      string
+		username string
+		wantCode int
+	}{
+		{name: "unknown format", url: "/user/export?format=xlsx", username: "username", wantCode: http.StatusBadRequest},
+		{name: "unknown column", url: "/user/export?columns=pass_hash", username: "username", wantCode: http.StatusBadRequest},
+		{name: "incorrect sort", url: "/user/export?sort=password", username: "username", wantCode: http.StatusBadRequest},
+		{name: "not admin", url: "/user/export", username: "testUser3", wantCode: http.StatusForbidden},
+	} {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			assert.Equal(t1, tt.wantCode, serve("GET", tt.url, "", tt.username).Code)
+		})
+	}
+}
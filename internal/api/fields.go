@@ -0,0 +1,139 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/KseniiaSalmina/Profiles/internal/api/models"
+)
+
+const includeGroups = "groups"
+
+var (
+	ErrUnknownField    = errors.New("unknown field")
+	ErrUnknownRelation = errors.New("unknown relation, only groups can be included")
+)
+
+// responseFields can be selected by fields parameter together with attr.<name>, password hash is not a field of response at all.
+var responseFields = append(slices.Clone(exportColumns), "attributes")
+
+var includeRelations = []string{includeGroups}
+
+// userView is the shape of user responses requested by fields and include parameters.
+type userView struct {
+	fields  []string // nil selects all fields
+	include []string
+}
+
+// pageUsersView is models.PageUsers with users rendered by userView.
+type pageUsersView struct {
+	Users       []map[string]any `json:"users"`
+	PageNo      int              `json:"page_number"`
+	Limit       int              `json:"limit"`
+	PagesAmount int              `json:"pages_amount"`
+}
+
+func getUserView(r *http.Request) (*userView, error) {
+	query := r.URL.Query()
+
+	fields, err := parseFields(query.Get("fields"), responseFields, ErrUnknownField)
+	if err != nil {
+		return nil, err
+	}
+
+	var include []string
+	if value := query.Get("include"); value != "" {
+		include = strings.Split(value, ",")
+		for _, relation := range include {
+			if !slices.Contains(includeRelations, relation) {
+				return nil, fmt.Errorf("%w: %q", ErrUnknownRelation, relation)
+			}
+		}
+	}
+
+	return &userView{fields: fields, include: include}, nil
+}
+
+// parseFields splits comma separated fields, every one should be allowed or attr.<name>.
+func parseFields(value string, allowed []string, errUnknown error) ([]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	fields := strings.Split(value, ",")
+	for _, field := range fields {
+		name, isAttribute := strings.CutPrefix(field, attributeColumnPrefix)
+		if (isAttribute && name == "") || (!isAttribute && !slices.Contains(allowed, field)) {
+			return nil, fmt.Errorf("%w: %q", errUnknown, field)
+		}
+	}
+
+	return fields, nil
+}
+
+// isDefault reports whether users are rendered as models.UserResponse.
+func (v *userView) isDefault() bool {
+	return v.fields == nil && v.include == nil
+}
+
+func (s *Server) renderUser(ctx context.Context, user models.UserResponse, view *userView) (map[string]any, error) {
+	var result map[string]any
+	if view.fields != nil {
+		result = userFields(user, view.fields)
+	} else {
+		data, err := json.Marshal(user)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &result); err != nil {
+			return nil, err
+		}
+	}
+
+	if slices.Contains(view.include, includeGroups) {
+		groups, err := s.service.GetUserGroups(ctx, organizationFromContext(ctx), user.ID)
+		if err != nil {
+			return nil, err
+		}
+		result[includeGroups] = groups
+	}
+
+	return result, nil
+}
+
+// userFields selects fields of user, selected attributes are nested into "attributes".
+func userFields(user models.UserResponse, fields []string) map[string]any {
+	result := make(map[string]any, len(fields))
+
+	var attributes map[string]any
+	addAttribute := func(name string, value any) {
+		if attributes == nil {
+			attributes = make(map[string]any)
+			result["attributes"] = attributes
+		}
+		attributes[name] = value
+	}
+
+	for _, field := range fields {
+		name, isAttribute := strings.CutPrefix(field, attributeColumnPrefix)
+		switch {
+		case field == "attributes":
+			for name, value := range user.Attributes {
+				addAttribute(name, value)
+			}
+		case isAttribute:
+			if value, ok := user.Attributes[name]; ok {
+				addAttribute(name, value)
+			}
+		default:
+			result[field] = exportValue(user, field)
+		}
+	}
+
+	return result
+}
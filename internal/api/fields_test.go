@@ -0,0 +1,74 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_userFields(t1 *testing.T) {
+	server := prepareServer()
+	serve := func(method, url, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.SetBasicAuth("username", "password")
+		w := httptest.NewRecorder()
+		server.httpServer.Handler.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("PATCH", "/user/"+testUsers[2].ID, `{"attributes": {"department": "sales", "floor": 2}}`)
+	require.Equal(t1, http.StatusOK, w.Code)
+	w = serve("POST", "/group", `{"name": "sales"}`)
+	require.Equal(t1, http.StatusOK, w.Code)
+	var groupID string
+	require.NoError(t1, json.NewDecoder(w.Body).Decode(&groupID))
+	w = serve("PUT", "/group/"+groupID+"/members/"+testUsers[2].ID, "")
+	require.Equal(t1, http.StatusOK, w.Code)
+
+	t1.Run("user fields", func(t1 *testing.T) {
+		w := serve("GET", "/user/"+testUsers[2].ID+"?fields=id,username,attr.floor", "")
+		require.Equal(t1, http.StatusOK, w.Code)
+		assert.JSONEq(t1, `{"id": "`+testUsers[2].ID+`", "username": "testUser3", "attributes": {"floor": 2}}`, w.Body.String())
+	})
+
+	t1.Run("user with groups", func(t1 *testing.T) {
+		w := serve("GET", "/user/"+testUsers[2].ID+"?fields=username,attributes&include=groups", "")
+		require.Equal(t1, http.StatusOK, w.Code)
+		assert.JSONEq(t1, `{"username": "testUser3", "attributes": {"department": "sales", "floor": 2}, "groups": [{"id": "`+groupID+`", "name": "sales", "description": ""}]}`, w.Body.String())
+	})
+
+	t1.Run("whole user with groups", func(t1 *testing.T) {
+		w := serve("GET", "/user/"+testUsers[2].ID+"?include=groups", "")
+		require.Equal(t1, http.StatusOK, w.Code)
+		var user map[string]any
+		require.NoError(t1, json.NewDecoder(w.Body).Decode(&user))
+		assert.Equal(t1, "test3@email.com", user["email"])
+		assert.Contains(t1, user, "created_at")
+		assert.Len(t1, user["groups"], 1)
+	})
+
+	t1.Run("page fields", func(t1 *testing.T) {
+		w := serve("GET", "/user?fields=username&attr.department=sales", "")
+		require.Equal(t1, http.StatusOK, w.Code)
+		assert.JSONEq(t1, `{"users": [{"username": "testUser3"}], "page_number": 1, "limit": 30, "pages_amount": 1}`, w.Body.String())
+	})
+
+	for _, tt := range []struct {
+		name string
+		url  string
+	}{
+		{name: "password hash", url: "/user/" + testUsers[2].ID + "?fields=username,pass_hash"},
+		{name: "unknown field of page", url: "/user?fields=password"},
+		{name: "empty attribute name", url: "/user?fields=attr."},
+		{name: "unknown relation", url: "/user/" + testUsers[2].ID + "?include=roles"},
+	} {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			assert.Equal(t1, http.StatusBadRequest, serve("GET", tt.url, "").Code)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/KseniiaSalmina/Profiles/internal/api/models"
+)
+
+var ErrIncorrectTime = errors.New("time should be in RFC3339 format")
+
+const attributeFilterPrefix = "attr."
+
+func (s *Server) getUserFilter(r *http.Request) (*models.UserFilter, error) {
+	filter := models.UserFilter{}
+
+	for key, values := range r.URL.Query() {
+		name, ok := strings.CutPrefix(key, attributeFilterPrefix)
+		if !ok || name == "" || len(values) == 0 {
+			continue
+		}
+
+		if filter.Attributes == nil {
+			filter.Attributes = make(map[string]string)
+		}
+		filter.Attributes[name] = values[0]
+	}
+
+	bounds := []struct {
+		param string
+		field *time.Time
+	}{
+		{param: "created_after", field: &filter.CreatedAfter},
+		{param: "created_before", field: &filter.CreatedBefore},
+		{param: "updated_after", field: &filter.UpdatedAfter},
+		{param: "updated_before", field: &filter.UpdatedBefore},
+		{param: "last_login_after", field: &filter.LastLoginAfter},
+		{param: "last_login_before", field: &filter.LastLoginBefore},
+	}
+
+	for _, bound := range bounds {
+		value := r.FormValue(bound.param)
+		if value == "" {
+			continue
+		}
+
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", bound.param, ErrIncorrectTime)
+		}
+		*bound.field = t
+	}
+
+	sortBy := r.FormValue("sort")
+	filter.SortBy, filter.SortDesc = strings.TrimPrefix(sortBy, "-"), strings.HasPrefix(sortBy, "-")
+
+	return &filter, nil
+}
@@ -0,0 +1,376 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/KseniiaSalmina/Profiles/internal/api/models"
+	"github.com/KseniiaSalmina/Profiles/internal/database"
+	"github.com/KseniiaSalmina/Profiles/internal/validation"
+)
+
+// @Summary Get all groups
+// @Security BasicAuth
+// @Tags group
+// @Description return all groups
+// @Return json
+// @Produce json,application/yaml,application/msgpack,text/csv
+// @Success 200 {array} models.GroupResponse
+// @Failure 401 {string} string
+// @Router /v1/group [get]
+func (s *Server) getAllGroups(w http.ResponseWriter, r *http.Request) {
+	if _, err := s.authorization(r); err != nil {
+		s.log(r).WithError(err).Info("get all groups handler, failed authorization")
+		http.Error(w, err.Error(), authErrorStatus(w, err))
+		return
+	}
+
+	groups, err := s.service.GetAllGroups(r.Context(), organizationFromContext(r.Context()))
+	if err != nil {
+		s.log(r).WithError(err).Info("get all groups handler, failed to get groups")
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	s.respondList(w, r, http.StatusOK, groups, groups)
+}
+
+// @Summary Post group
+// @Security BasicAuth
+// @Tags admin
+// @Description create new group
+// @Accept json,application/yaml,application/msgpack
+// @Return json
+// @Produce json,application/yaml,application/msgpack
+// @Param group body models.GroupAdd true "new group, name is required"
+// @Param Idempotency-Key header string false "retries with the same key replay the first response"
+// @Success 200 {string} string
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 500 {string} string
+// @Router /v1/group [post]
+func (s *Server) postGroup(w http.ResponseWriter, r *http.Request) {
+	caller, err := s.authorization(r)
+	if err != nil {
+		s.log(r).WithError(err).Info("post group handler, failed authorization")
+		http.Error(w, err.Error(), authErrorStatus(w, err))
+		return
+	}
+
+	if !caller.Admin {
+		s.log(r).Info("post group handler, user is not admin")
+		http.Error(w, validation.ErrIsNotAdmin.Error(), http.StatusForbidden)
+		return
+	}
+
+	var group models.GroupAdd
+	if err := decodeBody(r, &group); err != nil {
+		s.log(r).WithError(err).Info("post group handler, failed unmarshall request body")
+		http.Error(w, err.Error(), decodeErrorStatus(err))
+		return
+	}
+	defer r.Body.Close()
+
+	if err := validation.GroupAdd(group); err != nil {
+		s.log(r).WithError(err).Info("post group handler, invalid group data")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	id, err := s.service.AddGroup(r.Context(), organizationFromContext(r.Context()), group)
+	if err != nil {
+		s.log(r).WithError(err).Info("post group handler, failed to add group")
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	s.respond(w, r, http.StatusOK, id)
+}
+
+// @Summary Get group by id
+// @Security BasicAuth
+// @Tags group
+// @Description return group
+// @Return json
+// @Produce json,application/yaml,application/msgpack
+// @Param id path string true "group's id in uuid format"
+// @Success 200 {object} models.GroupResponse
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 404 {string} string
+// @Router /v1/group/{id} [get]
+func (s *Server) getGroup(w http.ResponseWriter, r *http.Request) {
+	if _, err := s.authorization(r); err != nil {
+		s.log(r).WithError(err).Info("get group handler, failed authorization")
+		http.Error(w, err.Error(), authErrorStatus(w, err))
+		return
+	}
+
+	id, err := getPathUUID(r, "id")
+	if err != nil {
+		s.log(r).WithError(err).Info("get group handler, failed to get id")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	group, err := s.service.GetGroupByID(r.Context(), organizationFromContext(r.Context()), id)
+	if err != nil {
+		s.log(r).WithError(err).Info("get group handler, failed to get group by id")
+		http.Error(w, err.Error(), groupErrorStatus(err))
+		return
+	}
+
+	s.respond(w, r, http.StatusOK, group)
+}
+
+// @Summary Patch group
+// @Security BasicAuth
+// @Tags admin
+// @Description update group, empty parent_id moves group to the top level
+// @Accept json,application/yaml,application/msgpack
+// @Param id path string true "group's id in uuid format"
+// @Param group body models.GroupUpdate true "at least one update is required"
+// @Param Idempotency-Key header string false "retries with the same key replay the first response"
+// @Success 200
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 404 {string} string
+// @Failure 500 {string} string
+// @Router /v1/group/{id} [patch]
+func (s *Server) patchGroup(w http.ResponseWriter, r *http.Request) {
+	caller, err := s.authorization(r)
+	if err != nil {
+		s.log(r).WithError(err).Info("patch group handler, failed authorization")
+		http.Error(w, err.Error(), authErrorStatus(w, err))
+		return
+	}
+
+	if !caller.Admin {
+		s.log(r).Info("patch group handler, user is not admin")
+		http.Error(w, validation.ErrIsNotAdmin.Error(), http.StatusForbidden)
+		return
+	}
+
+	var group models.GroupUpdate
+	if err := decodeBody(r, &group); err != nil {
+		s.log(r).WithError(err).Info("patch group handler, failed to unmarshall request body")
+		http.Error(w, err.Error(), decodeErrorStatus(err))
+		return
+	}
+	defer r.Body.Close()
+
+	if err := validation.GroupUpdate(group); err != nil {
+		s.log(r).WithError(err).Info("patch group handler, invalid group data")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	id, err := getPathUUID(r, "id")
+	if err != nil {
+		s.log(r).WithError(err).Info("patch group handler, failed to get id")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.service.ChangeGroup(r.Context(), organizationFromContext(r.Context()), id, group); err != nil {
+		s.log(r).WithError(err).Info("patch group handler, failed to change group")
+		http.Error(w, err.Error(), groupErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// @Summary Delete group
+// @Security BasicAuth
+// @Tags admin
+// @Description delete group without subgroups, memberships are deleted with the group
+// @Param id path string true "group's id in uuid format"
+// @Success 200
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 404 {string} string
+// @Router /v1/group/{id} [delete]
+func (s *Server) deleteGroup(w http.ResponseWriter, r *http.Request) {
+	caller, err := s.authorization(r)
+	if err != nil {
+		s.log(r).WithError(err).Info("delete group handler, failed authorization")
+		http.Error(w, err.Error(), authErrorStatus(w, err))
+		return
+	}
+
+	if !caller.Admin {
+		s.log(r).Info("delete group handler, user is not admin")
+		http.Error(w, validation.ErrIsNotAdmin.Error(), http.StatusForbidden)
+		return
+	}
+
+	id, err := getPathUUID(r, "id")
+	if err != nil {
+		s.log(r).WithError(err).Info("delete group handler, failed to get id")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.service.DeleteGroup(r.Context(), organizationFromContext(r.Context()), id); err != nil {
+		s.log(r).WithError(err).Info("delete group handler, failed to delete group")
+		http.Error(w, err.Error(), groupErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// @Summary Get group members
+// @Security BasicAuth
+// @Tags group
+// @Description return members of the group including members of all nested subgroups
+// @Return json
+// @Produce json,application/yaml,application/msgpack,text/csv
+// @Param id path string true "group's id in uuid format"
+// @Success 200 {array} models.UserResponse
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 404 {string} string
+// @Router /v1/group/{id}/members [get]
+func (s *Server) getGroupMembers(w http.ResponseWriter, r *http.Request) {
+	if _, err := s.authorization(r); err != nil {
+		s.log(r).WithError(err).Info("get group members handler, failed authorization")
+		http.Error(w, err.Error(), authErrorStatus(w, err))
+		return
+	}
+
+	id, err := getPathUUID(r, "id")
+	if err != nil {
+		s.log(r).WithError(err).Info("get group members handler, failed to get id")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	users, err := s.service.GetGroupMembers(r.Context(), organizationFromContext(r.Context()), id)
+	if err != nil {
+		s.log(r).WithError(err).Info("get group members handler, failed to get members")
+		http.Error(w, err.Error(), groupErrorStatus(err))
+		return
+	}
+
+	s.respondList(w, r, http.StatusOK, users, users)
+}
+
+// @Summary Put group member
+// @Security BasicAuth
+// @Tags admin
+// @Description add user to the group
+// @Param id path string true "group's id in uuid format"
+// @Param userID path string true "user's id in uuid format"
+// @Success 200
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 404 {string} string
+// @Router /v1/group/{id}/members/{userID} [put]
+func (s *Server) putGroupMember(w http.ResponseWriter, r *http.Request) {
+	s.changeMembership(w, r, "put group member", s.service.AddMember)
+}
+
+// @Summary Delete group member
+// @Security BasicAuth
+// @Tags admin
+// @Description remove user from the group
+// @Param id path string true "group's id in uuid format"
+// @Param userID path string true "user's id in uuid format"
+// @Success 200
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 404 {string} string
+// @Router /v1/group/{id}/members/{userID} [delete]
+func (s *Server) deleteGroupMember(w http.ResponseWriter, r *http.Request) {
+	s.changeMembership(w, r, "delete group member", s.service.DeleteMember)
+}
+
+func (s *Server) changeMembership(w http.ResponseWriter, r *http.Request, handler string, change func(ctx context.Context, orgID, groupID, userID string) error) {
+	caller, err := s.authorization(r)
+	if err != nil {
+		s.log(r).WithError(err).Info(handler + " handler, failed authorization")
+		http.Error(w, err.Error(), authErrorStatus(w, err))
+		return
+	}
+
+	if !caller.Admin {
+		s.log(r).Info(handler + " handler, user is not admin")
+		http.Error(w, validation.ErrIsNotAdmin.Error(), http.StatusForbidden)
+		return
+	}
+
+	groupID, err := getPathUUID(r, "id")
+	if err != nil {
+		s.log(r).WithError(err).Info(handler + " handler, failed to get group id")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	userID, err := getPathUUID(r, "userID")
+	if err != nil {
+		s.log(r).WithError(err).Info(handler + " handler, failed to get user id")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := change(r.Context(), organizationFromContext(r.Context()), groupID, userID); err != nil {
+		s.log(r).WithError(err).Info(handler + " handler, failed to change membership")
+		http.Error(w, err.Error(), groupErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// @Summary Get user's groups
+// @Security BasicAuth
+// @Tags user
+// @Description return groups the user belongs to, directly or through nested subgroups
+// @Return json
+// @Produce json,application/yaml,application/msgpack,text/csv
+// @Param id path string true "user's id in uuid format"
+// @Success 200 {array} models.GroupResponse
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 404 {string} string
+// @Router /v1/user/{id}/groups [get]
+func (s *Server) getUserGroups(w http.ResponseWriter, r *http.Request) {
+	if _, err := s.authorization(r); err != nil {
+		s.log(r).WithError(err).Info("get user groups handler, failed authorization")
+		http.Error(w, err.Error(), authErrorStatus(w, err))
+		return
+	}
+
+	id, err := getPathUUID(r, "id")
+	if err != nil {
+		s.log(r).WithError(err).Info("get user groups handler, failed to get id")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	groups, err := s.service.GetUserGroups(r.Context(), organizationFromContext(r.Context()), id)
+	if err != nil {
+		s.log(r).WithError(err).Info("get user groups handler, failed to get groups")
+		http.Error(w, err.Error(), groupErrorStatus(err))
+		return
+	}
+
+	s.respondList(w, r, http.StatusOK, groups, groups)
+}
+
+func groupErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, database.ErrGroupDoesNotExist), errors.Is(err, database.ErrUserDoesNotExist):
+		return http.StatusNotFound
+	default:
+		return serviceErrorStatus(err, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KseniiaSalmina/Profiles/internal/api/models"
+)
+
+func TestServer_groups(t1 *testing.T) {
+	server := prepareServer()
+
+	serve := func(method, url, body, username string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.SetBasicAuth(username, "password")
+		w := httptest.NewRecorder()
+		server.httpServer.Handler.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("POST", "/group", `{"name":"company"}`, "username")
+	assert.Equal(t1, http.StatusOK, w.Code)
+	var parentID string
+	assert.NoError(t1, json.NewDecoder(w.Body).Decode(&parentID))
+
+	w = serve("POST", "/group", `{"name":"dev","parent_id":"`+parentID+`"}`, "username")
+	assert.Equal(t1, http.StatusOK, w.Code)
+	var childID string
+	assert.NoError(t1, json.NewDecoder(w.Body).Decode(&childID))
+
+	w = serve("POST", "/group", `{"name":"sales"}`, "testUser3")
+	assert.Equal(t1, http.StatusForbidden, w.Code)
+
+	w = serve("PATCH", "/group/"+parentID, `{"parent_id":"`+childID+`"}`, "username")
+	assert.Equal(t1, http.StatusBadRequest, w.Code)
+
+	w = serve("PUT", "/group/"+childID+"/members/db783cb2-8037-4b75-8c01-ab9065e568e3", "", "username")
+	assert.Equal(t1, http.StatusOK, w.Code)
+
+	w = serve("PUT", "/group/"+childID+"/members/34775464-a73b-4445-8866-1e6061c3b70b", "", "username")
+	assert.Equal(t1, http.StatusNotFound, w.Code)
+
+	w = serve("GET", "/group/"+parentID+"/members", "", "testUser3")
+	assert.Equal(t1, http.StatusOK, w.Code)
+	var members []models.UserResponse
+	assert.NoError(t1, json.NewDecoder(w.Body).Decode(&members))
+	assert.Len(t1, members, 1)
+
+	w = serve("GET", "/user/db783cb2-8037-4b75-8c01-ab9065e568e3/groups", "", "testUser3")
+	assert.Equal(t1, http.StatusOK, w.Code)
+	var groups []models.GroupResponse
+	assert.NoError(t1, json.NewDecoder(w.Body).Decode(&groups))
+	assert.Equal(t1, []models.GroupResponse{
+		{ID: parentID, Name: "company"},
+		{ID: childID, Name: "dev", ParentID: parentID},
+	}, groups)
+
+	w = serve("DELETE", "/group/"+childID+"/members/db783cb2-8037-4b75-8c01-ab9065e568e3", "", "username")
+	assert.Equal(t1, http.StatusOK, w.Code)
+
+	w = serve("DELETE", "/group/"+parentID, "", "username")
+	assert.Equal(t1, http.StatusBadRequest, w.Code)
+
+	w = serve("DELETE", "/group/"+childID, "", "username")
+	assert.Equal(t1, http.StatusOK, w.Code)
+
+	w = serve("GET", "/group/"+childID, "", "username")
+	assert.Equal(t1, http.StatusNotFound, w.Code)
+}
@@ -1,7 +1,8 @@
 package api
 
 import (
-	"encoding/json"
+	"errors"
+	"mime"
 	"net/http"
 
 	"github.com/google/uuid"
@@ -16,95 +17,140 @@ import (
 // @Tags user
 // @Description return page of users' profiles
 // @Return json
+// @Produce json,application/yaml,application/msgpack,text/csv
 // @Param page query int false "page number"
 // @Param limit query int false "limit of records by page"
+// @Param attr.name query string false "filter by attribute value, attribute name goes after 'attr.' prefix"
+// @Param created_after query string false "created at or after, RFC3339"
+// @Param created_before query string false "created before, RFC3339"
+// @Param updated_after query string false "updated at or after, RFC3339"
+// @Param updated_before query string false "updated before, RFC3339"
+// @Param last_login_after query string false "last login at or after, RFC3339"
+// @Param last_login_before query string false "last login before or never logged in, RFC3339"
+// @Param sort query string false "username, email, created_at, updated_at, last_login_at or password_changed_at, '-' prefix for descending order"
+// @Param fields query string false "comma separated fields: id, username, email, admin, disabled, created_at, updated_at, last_login_at, password_changed_at, attributes or attr.<name>"
+// @Param include query string false "related data: groups"
 // @Success 200 {object} models.PageUsers
 // @Failure 400 {string} string
 // @Failure 401 {string} string
-// @Router /user [get]
+// @Router /v1/user [get]
 func (s *Server) getAllUsers(w http.ResponseWriter, r *http.Request) {
-	var statusCode int
-	defer s.logging(&statusCode, r)
-
 	if _, err := s.authorization(r); err != nil {
-		s.logger.WithError(err).Info("get all users handler, failed authorization")
-		statusCode = http.StatusUnauthorized
-		http.Error(w, err.Error(), http.StatusUnauthorized)
+		s.log(r).WithError(err).Info("get all users handler, failed authorization")
+		http.Error(w, err.Error(), authErrorStatus(w, err))
+		return
+	}
+
+	view, err := getUserView(r)
+	if err != nil {
+		s.log(r).WithError(err).Info("get all users handler, invalid fields or include")
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	pageInfo, err := s.getPageInfo(r)
 	if err != nil {
-		s.logger.WithError(err).Info("get all users handler, failed to get page info")
-		statusCode = http.StatusBadRequest
+		s.log(r).WithError(err).Info("get all users handler, failed to get page info")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	filter, err := s.getUserFilter(r)
+	if err != nil {
+		s.log(r).WithError(err).Info("get all users handler, failed to get filter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	users := s.service.GetAllUsers(pageInfo.Limit, pageInfo.Offset, pageInfo.PageNo)
+	if err := validation.UserFilter(*filter); err != nil {
+		s.log(r).WithError(err).Info("get all users handler, invalid filter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	statusCode = http.StatusOK
-	_ = json.NewEncoder(w).Encode(users)
+	users, err := s.service.GetAllUsers(r.Context(), organizationFromContext(r.Context()), pageInfo.Limit, pageInfo.Offset, pageInfo.PageNo, *filter)
+	if err != nil {
+		s.log(r).WithError(err).Info("get all users handler, failed to get users")
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	if view.isDefault() {
+		s.respondList(w, r, http.StatusOK, users, users.Users)
+		return
+	}
+
+	page := pageUsersView{Users: make([]map[string]any, 0, len(users.Users)), PageNo: users.PageNo, Limit: users.Limit, PagesAmount: users.PagesAmount}
+	for _, user := range users.Users {
+		rendered, err := s.renderUser(r.Context(), user, view)
+		if err != nil {
+			s.log(r).WithError(err).Info("get all users handler, failed to render user")
+			http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
+			return
+		}
+		page.Users = append(page.Users, rendered)
+	}
+
+	s.respondList(w, r, http.StatusOK, page, page.Users)
 }
 
 // @Summary Post user
 // @Security BasicAuth
 // @Tags admin
 // @Description create new user
-// @Accept json
+// @Accept json,application/yaml,application/msgpack
 // @Return json
+// @Produce json,application/yaml,application/msgpack
 // @Param user body models.UserAdd true "new user's profile, username, password and email is required"
+// @Param Idempotency-Key header string false "retries with the same key replay the first response"
 // @Success 200 {string} string
 // @Failure 400 {string} string
 // @Failure 401 {string} string
 // @Failure 403 {string} string
 // @Failure 500 {string} string
-// @Router /user [post]
+// @Router /v1/user [post]
 func (s *Server) postUser(w http.ResponseWriter, r *http.Request) {
-	var statusCode int
-	defer s.logging(&statusCode, r)
-
-	isAdmin, err := s.authorization(r)
+	caller, err := s.authorization(r)
 	if err != nil {
-		s.logger.WithError(err).Info("post user handler, failed authorization")
-		statusCode = http.StatusUnauthorized
-		http.Error(w, err.Error(), http.StatusUnauthorized)
+		s.log(r).WithError(err).Info("post user handler, failed authorization")
+		http.Error(w, err.Error(), authErrorStatus(w, err))
 		return
 	}
 
-	if !isAdmin {
-		s.logger.Info("post user handler, user is not admin")
-		statusCode = http.StatusForbidden
+	if !caller.Admin {
+		s.log(r).Info("post user handler, user is not admin")
 		http.Error(w, validation.ErrIsNotAdmin.Error(), http.StatusForbidden)
 		return
 	}
 
 	var user models.UserAdd
-	if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
-		s.logger.WithError(err).Info("post user handler, failed unmarshall request body")
-		statusCode = http.StatusInternalServerError
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := decodeBody(r, &user); err != nil {
+		s.log(r).WithError(err).Info("post user handler, failed unmarshall request body")
+		http.Error(w, err.Error(), decodeErrorStatus(err))
 		return
 	}
 	defer r.Body.Close()
 
 	if err := validation.UserAdd(user); err != nil {
-		s.logger.WithError(err).Info("post user handler, invalid user data")
-		statusCode = http.StatusBadRequest
+		s.log(r).WithError(err).Info("post user handler, invalid user data")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	id, err := s.service.AddUser(user)
+	if user.SuperAdmin && !caller.SuperAdmin {
+		s.log(r).Info("post user handler, user is not super admin")
+		http.Error(w, validation.ErrIsNotSuperAdmin.Error(), http.StatusForbidden)
+		return
+	}
+
+	id, err := s.service.AddUser(r.Context(), organizationFromContext(r.Context()), user)
 	if err != nil {
-		s.logger.WithError(err).Info("post user handler, failed to add user")
-		statusCode = http.StatusBadRequest
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		s.log(r).WithError(err).Info("post user handler, failed to add user")
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
 		return
 	}
 
-	statusCode = http.StatusOK
-	_ = json.NewEncoder(w).Encode(id)
+	s.respond(w, r, http.StatusOK, id)
 }
 
 // @Summary Get user by id
@@ -112,180 +158,254 @@ func (s *Server) postUser(w http.ResponseWriter, r *http.Request) {
 // @Tags user
 // @Description return user's profile
 // @Return json
+// @Produce json,application/yaml,application/msgpack
 // @Param id path string true "user's id in uuid format"
+// @Param fields query string false "comma separated fields: id, username, email, admin, disabled, created_at, updated_at, last_login_at, password_changed_at, attributes or attr.<name>"
+// @Param include query string false "related data: groups"
 // @Success 200 {object} models.UserResponse
 // @Failure 400 {string} string
 // @Failure 401 {string} string
-// @Router /user/{id} [get]
+// @Router /v1/user/{id} [get]
 func (s *Server) getUser(w http.ResponseWriter, r *http.Request) {
-	var statusCode int
-	defer s.logging(&statusCode, r)
-
 	if _, err := s.authorization(r); err != nil {
-		s.logger.WithError(err).Info("get user handler, failed authorization")
-		statusCode = http.StatusUnauthorized
-		http.Error(w, err.Error(), http.StatusUnauthorized)
+		s.log(r).WithError(err).Info("get user handler, failed authorization")
+		http.Error(w, err.Error(), authErrorStatus(w, err))
 		return
 	}
 
 	id, ok := bunrouter.ParamsFromContext(r.Context()).Get("id")
 	if !ok {
-		s.logger.Info("get user handler, failed to get id")
-		statusCode = http.StatusBadRequest
+		s.log(r).Info("get user handler, failed to get id")
 		http.Error(w, "id is required", http.StatusBadRequest)
 		return
 	}
 
 	_, err := uuid.Parse(id)
 	if err != nil {
-		s.logger.WithError(err).Info("get user handler, failed to parse uuid")
-		statusCode = http.StatusBadRequest
+		s.log(r).WithError(err).Info("get user handler, failed to parse uuid")
 		http.Error(w, "id should be in uuid format", http.StatusBadRequest)
 		return
 	}
 
-	user, err := s.service.GetUserByID(id)
+	view, err := getUserView(r)
 	if err != nil {
-		s.logger.WithError(err).Info("get user handler, failed to get user by id")
-		statusCode = http.StatusBadRequest
+		s.log(r).WithError(err).Info("get user handler, invalid fields or include")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	statusCode = http.StatusOK
-	_ = json.NewEncoder(w).Encode(user)
+	user, err := s.service.GetUserByID(r.Context(), organizationFromContext(r.Context()), id)
+	if err != nil {
+		s.log(r).WithError(err).Info("get user handler, failed to get user by id")
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	if view.isDefault() {
+		s.respond(w, r, http.StatusOK, user)
+		return
+	}
+
+	rendered, err := s.renderUser(r.Context(), *user, view)
+	if err != nil {
+		s.log(r).WithError(err).Info("get user handler, failed to render user")
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	s.respond(w, r, http.StatusOK, rendered)
 
 }
 
 // @Summary Patch user
 // @Security BasicAuth
 // @Tags admin
-// @Description update user's profile
-// @Accept json
+// @Description update user's profile. application/json body is models.UserUpdate, merge patch (RFC 7396) and JSON patch (RFC 6902)
+// @Description are applied to models.UserReplace of the current profile, failed JSON patch test returns 409.
+// @Accept json,application/yaml,application/msgpack,application/merge-patch+json,application/json-patch+json
 // @Param id path string true "user's id in uuid format"
 // @Param user body models.UserUpdate true "at least one update is required"
+// @Param Idempotency-Key header string false "retries with the same key replay the first response"
 // @Success 200
 // @Failure 400 {string} string
 // @Failure 401 {string} string
 // @Failure 403 {string} string
+// @Failure 409 {string} string
+// @Failure 415 {string} string
 // @Failure 500 {string} string
-// @Router /user/{id} [patch]
+// @Router /v1/user/{id} [patch]
 func (s *Server) patchUser(w http.ResponseWriter, r *http.Request) {
-	var statusCode int
-	defer s.logging(&statusCode, r)
-
-	isAdmin, err := s.authorization(r)
+	caller, err := s.authorization(r)
 	if err != nil {
-		s.logger.WithError(err).Info("patch user handler, failed authorization")
-		statusCode = http.StatusUnauthorized
-		http.Error(w, err.Error(), http.StatusUnauthorized)
+		s.log(r).WithError(err).Info("patch user handler, failed authorization")
+		http.Error(w, err.Error(), authErrorStatus(w, err))
 		return
 	}
 
-	if !isAdmin {
-		s.logger.Info("patch user handler, user is not admin")
-		statusCode = http.StatusForbidden
+	if !caller.Admin {
+		s.log(r).Info("patch user handler, user is not admin")
 		http.Error(w, validation.ErrIsNotAdmin.Error(), http.StatusForbidden)
 		return
 	}
 
+	// patch documents are applied to the user, other types are decoded as fields to change
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == mergePatchType || mediaType == jsonPatchType {
+		s.patchUserDocument(w, r, caller, mediaType)
+		return
+	}
+
 	var user models.UserUpdate
-	if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
-		s.logger.WithError(err).Info("patch user handler, failed to unmarshall request body")
-		statusCode = http.StatusInternalServerError
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := decodeBody(r, &user); err != nil {
+		s.log(r).WithError(err).Info("patch user handler, failed to unmarshall request body")
+		http.Error(w, err.Error(), decodeErrorStatus(err))
 		return
 	}
 	defer r.Body.Close()
 
 	if err := validation.UserUpdate(user); err != nil {
-		s.logger.WithError(err).Info("patch user handler, invalid user data")
-		statusCode = http.StatusBadRequest
+		s.log(r).WithError(err).Info("patch user handler, invalid user data")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
+	if user.SuperAdmin != nil && !caller.SuperAdmin {
+		s.log(r).Info("patch user handler, user is not super admin")
+		http.Error(w, validation.ErrIsNotSuperAdmin.Error(), http.StatusForbidden)
+		return
+	}
+
 	id, ok := bunrouter.ParamsFromContext(r.Context()).Get("id")
 	if !ok {
-		s.logger.Info("patch user handler, failed to get id")
-		statusCode = http.StatusBadRequest
+		s.log(r).Info("patch user handler, failed to get id")
 		http.Error(w, "id is required", http.StatusBadRequest)
 		return
 	}
 
 	_, err = uuid.Parse(id)
 	if err != nil {
-		s.logger.WithError(err).Info("patch user handler, failed to parse uuid")
-		statusCode = http.StatusBadRequest
+		s.log(r).WithError(err).Info("patch user handler, failed to parse uuid")
 		http.Error(w, "id should be in uuid format", http.StatusBadRequest)
 		return
 	}
 
-	if err := s.service.ChangeUser(id, user); err != nil {
-		s.logger.WithError(err).Info("patch user handler, failed to change user")
-		statusCode = http.StatusBadRequest
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if err := s.service.ChangeUser(r.Context(), organizationFromContext(r.Context()), id, user, caller.SuperAdmin); err != nil {
+		s.log(r).WithError(err).Info("patch user handler, failed to change user")
+		http.Error(w, err.Error(), userErrorStatus(err, http.StatusBadRequest))
 		return
 	}
 
-	statusCode = http.StatusOK
 	w.WriteHeader(http.StatusOK)
 
 }
 
+// @Summary Replace user
+// @Security BasicAuth
+// @Tags admin
+// @Description replace whole user's profile, fields which are not set are cleared, password is kept if not set
+// @Accept json,application/yaml,application/msgpack
+// @Param id path string true "user's id in uuid format"
+// @Param user body models.UserReplace true "new profile, username and email is required"
+// @Success 200
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 409 {string} string
+// @Failure 500 {string} string
+// @Router /v1/user/{id} [put]
+func (s *Server) putUser(w http.ResponseWriter, r *http.Request) {
+	caller, err := s.authorization(r)
+	if err != nil {
+		s.log(r).WithError(err).Info("put user handler, failed authorization")
+		http.Error(w, err.Error(), authErrorStatus(w, err))
+		return
+	}
+
+	if !caller.Admin {
+		s.log(r).Info("put user handler, user is not admin")
+		http.Error(w, validation.ErrIsNotAdmin.Error(), http.StatusForbidden)
+		return
+	}
+
+	var user models.UserReplace
+	if err := decodeBody(r, &user); err != nil {
+		s.log(r).WithError(err).Info("put user handler, failed to unmarshall request body")
+		http.Error(w, err.Error(), decodeErrorStatus(err))
+		return
+	}
+	defer r.Body.Close()
+
+	id, err := getPathUUID(r, "id")
+	if err != nil {
+		s.log(r).WithError(err).Info("put user handler, failed to get id")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = s.service.ReplaceUser(r.Context(), organizationFromContext(r.Context()), id, replacement(caller, func(models.UserReplace) (models.UserReplace, error) {
+		return user, nil
+	}))
+	if err != nil {
+		s.log(r).WithError(err).Info("put user handler, failed to replace user")
+		http.Error(w, err.Error(), replaceErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
 // @Summary Delete user
 // @Security BasicAuth
 // @Tags admin
 // @Description delete user's profile
-// @Accept json
+// @Accept json,application/yaml,application/msgpack
 // @Param id path string true "user's id in uuid format"
 // @Success 200
 // @Failure 400 {string} string
 // @Failure 401 {string} string
 // @Failure 403 {string} string
-// @Router /user/{id} [delete]
+// @Router /v1/user/{id} [delete]
 func (s *Server) deleteUser(w http.ResponseWriter, r *http.Request) {
-	var statusCode int
-	defer s.logging(&statusCode, r)
-
-	isAdmin, err := s.authorization(r)
+	caller, err := s.authorization(r)
 	if err != nil {
-		s.logger.WithError(err).Info("delete user handler, failed authorization")
-		statusCode = http.StatusUnauthorized
-		http.Error(w, err.Error(), http.StatusUnauthorized)
+		s.log(r).WithError(err).Info("delete user handler, failed authorization")
+		http.Error(w, err.Error(), authErrorStatus(w, err))
 		return
 	}
 
-	if !isAdmin {
-		s.logger.Info("delete user handler, user is not admin")
-		statusCode = http.StatusForbidden
+	if !caller.Admin {
+		s.log(r).Info("delete user handler, user is not admin")
 		http.Error(w, validation.ErrIsNotAdmin.Error(), http.StatusForbidden)
 		return
 	}
 
 	id, ok := bunrouter.ParamsFromContext(r.Context()).Get("id")
 	if !ok {
-		s.logger.Info("delete user handler, failed to get id")
-		statusCode = http.StatusBadRequest
+		s.log(r).Info("delete user handler, failed to get id")
 		http.Error(w, "id is required", http.StatusBadRequest)
 		return
 	}
 
 	_, err = uuid.Parse(id)
 	if err != nil {
-		s.logger.WithError(err).Info("delete user handler, failed to parse uuid")
-		statusCode = http.StatusBadRequest
+		s.log(r).WithError(err).Info("delete user handler, failed to parse uuid")
 		http.Error(w, "id should be in uuid format", http.StatusBadRequest)
 		return
 	}
 
-	if err := s.service.DeleteUser(id); err != nil {
-		s.logger.WithError(err).Info("delete user handler, failed to delete user")
-		statusCode = http.StatusBadRequest
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if err := s.service.DeleteUser(r.Context(), organizationFromContext(r.Context()), id, caller.SuperAdmin); err != nil {
+		s.log(r).WithError(err).Info("delete user handler, failed to delete user")
+		http.Error(w, err.Error(), userErrorStatus(err, http.StatusBadRequest))
 		return
 	}
 
-	statusCode = http.StatusOK
 	w.WriteHeader(http.StatusOK)
 }
+
+// userErrorStatus reports changes of super admins by other users as forbidden.
+func userErrorStatus(err error, status int) int {
+	if errors.Is(err, validation.ErrIsNotSuperAdmin) {
+		return http.StatusForbidden
+	}
+
+	return serviceErrorStatus(err, status)
+}
@@ -1,21 +1,29 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 
 	"github.com/KseniiaSalmina/Profiles/internal/api/models"
+	"github.com/KseniiaSalmina/Profiles/internal/blobstore"
 	"github.com/KseniiaSalmina/Profiles/internal/config"
 	"github.com/KseniiaSalmina/Profiles/internal/database"
 	"github.com/KseniiaSalmina/Profiles/internal/logger"
+	"github.com/KseniiaSalmina/Profiles/internal/metrics"
+	"github.com/KseniiaSalmina/Profiles/internal/notifier"
 	"github.com/KseniiaSalmina/Profiles/internal/service"
+	"github.com/KseniiaSalmina/Profiles/internal/validation"
 )
 
 var serverCfg = config.Server{
@@ -23,6 +31,12 @@ var serverCfg = config.Server{
 	ReadTimeout:  5 * time.Second,
 	WriteTimeout: 5 * time.Second,
 	IdleTimeout:  30 * time.Second,
+
+	IdempotencyTTL:         time.Hour,
+	LegacyRoutes:           true,
+	LegacyRoutesSunset:     "2027-04-30",
+	LegacyRoutesDeprecated: "2026-10-19",
+	BatchMaxOperations:     100,
 }
 
 var serviceCfg = config.Service{
@@ -30,6 +44,13 @@ var serviceCfg = config.Service{
 	AdminUsername: "username",
 	AdminPassword: "password",
 	AdminEmail:    "test@email.com",
+	DefaultTenant: "default",
+	Avatar: config.Avatar{
+		AvatarMaxSize:   1 << 20,
+		AvatarMaxWidth:  1024,
+		AvatarMaxHeight: 1024,
+		AvatarSizes:     []int{16, 64},
+	},
 }
 
 var loggercfg = config.Logger{
@@ -58,25 +79,65 @@ var testUsers = []database.User{
 }
 
 func prepareServer() *Server {
+	return prepareServerWithConfig(serviceCfg)
+}
+
+func prepareServerWithConfig(serviceCfg config.Service) *Server {
+	return prepareServerWithConfigs(serverCfg, serviceCfg)
+}
+
+func prepareServerWithConfigs(serverCfg config.Server, serviceCfg config.Service) *Server {
+	return prepareServerWithContext(context.Background(), serverCfg, serviceCfg)
+}
+
+// prepareServerWithContext prepares server whose service stops background work when ctx is done.
+func prepareServerWithContext(ctx context.Context, serverCfg config.Server, serviceCfg config.Service) *Server {
 	db := database.NewDatabase()
-	service, err := service.NewService(serviceCfg, db)
+
+	dir, err := os.MkdirTemp("", "profiles-blobs-")
+	if err != nil {
+		log.Fatal("failed to prepare blob store directory")
+	}
+	blobStore, err := blobstore.NewLocal(dir)
+	if err != nil {
+		log.Fatal("failed to prepare blob store")
+	}
+
+	logger, err := logger.NewLogger(loggercfg)
+	if err != nil {
+		log.Fatal("failed to prepare logger")
+	}
+
+	m := metrics.NewMetrics()
+	m.RegisterUserCount(func() int {
+		count, _ := db.CountUsers(context.Background(), database.UserFilter{})
+		return count
+	})
+
+	service, err := service.NewService(ctx, serviceCfg, metrics.NewStorage(db, m), blobStore, notifier.NewLog(logger))
 	if err != nil {
 		log.Fatal("failed to prepare service")
 	}
 
 	prepareDB(db)
 
-	logger, err := logger.NewLogger(loggercfg)
+	server, err := NewServer(serverCfg, service, logger, m)
 	if err != nil {
-		log.Fatal("failed to prepare logger")
+		log.Fatal("failed to prepare server")
 	}
 
-	return NewServer(serverCfg, service, logger)
+	return server
 }
 
 func prepareDB(db *database.Database) {
+	org, err := db.GetOrganizationByName(context.Background(), serviceCfg.DefaultTenant)
+	if err != nil {
+		log.Fatal("failed to get default organization")
+	}
+
 	for _, user := range testUsers {
-		err := db.AddUser(user)
+		user.OrgID = org.ID
+		err := db.AddUser(context.Background(), user)
 		if err != nil {
 			log.Fatalf("failed to add user: %v, %s", user.ID, err.Error())
 		}
@@ -467,6 +528,100 @@ func TestServer_deleteUser(t1 *testing.T) {
 	}
 }
 
+func TestServer_superAdminTarget(t1 *testing.T) {
+	server := prepareServer()
+	serve := func(method, url, body, username string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.SetBasicAuth(username, "password")
+		w := httptest.NewRecorder()
+		server.httpServer.Handler.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("POST", "/user", `{"username":"admin","password":"password","email":"admin@email.com","admin":true}`, "username")
+	assert.Equal(t1, http.StatusOK, w.Code)
+	superAdminID := superAdminID(t1, server)
+
+	tests := []struct {
+		name     string
+		method   string
+		body     string
+		username string
+		wantCode int
+	}{
+		{name: "admin changes password", method: "PATCH", body: `{"password":"hijacked"}`, username: "admin", wantCode: http.StatusForbidden},
+		{name: "admin disables", method: "PATCH", body: `{"disabled":true}`, username: "admin", wantCode: http.StatusForbidden},
+		{name: "admin deletes", method: "DELETE", username: "admin", wantCode: http.StatusForbidden},
+		{name: "super admin changes email", method: "PATCH", body: `{"email":"root@email.com"}`, username: "username", wantCode: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			assert.Equal(t1, tt.wantCode, serve(tt.method, "/user/"+superAdminID, tt.body, tt.username).Code)
+		})
+	}
+
+	w = serve("GET", "/user/"+superAdminID, "", "username")
+	assert.Equal(t1, http.StatusOK, w.Code)
+	assert.Contains(t1, w.Body.String(), `"disabled":false`)
+
+	avatar := string(testImage(10, 10))
+	assert.Equal(t1, http.StatusForbidden, serve("PUT", "/user/"+superAdminID+"/avatar", avatar, "admin").Code)
+	assert.Equal(t1, http.StatusOK, serve("PUT", "/user/"+superAdminID+"/avatar", avatar, "username").Code)
+}
+
+func TestServer_patchUserConcurrentAttributes(t1 *testing.T) {
+	// hashing of password is slow, so concurrent patches overlap
+	const patches = 10
+
+	server := prepareServer()
+	serve := func(method, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/user/"+testUsers[2].ID, strings.NewReader(body))
+		req.SetBasicAuth("username", "password")
+		w := httptest.NewRecorder()
+		server.httpServer.Handler.ServeHTTP(w, req)
+		return w
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < patches; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			w := serve("PATCH", fmt.Sprintf(`{"password": "password", "attributes": {"a%d": %d}}`, i, i))
+			assert.Equal(t1, http.StatusOK, w.Code, w.Body.String())
+		}(i)
+	}
+	wg.Wait()
+
+	w := serve("GET", "")
+	assert.Equal(t1, http.StatusOK, w.Code)
+	var user models.UserResponse
+	assert.NoError(t1, json.NewDecoder(w.Body).Decode(&user))
+	assert.Len(t1, user.Attributes, patches, "every concurrent change is kept")
+}
+
+// superAdminID returns id of super admin created on start of server.
+func superAdminID(t1 *testing.T, server *Server) string {
+	req := httptest.NewRequest("GET", "/user?limit=100", nil)
+	req.SetBasicAuth("username", "password")
+	w := httptest.NewRecorder()
+	server.httpServer.Handler.ServeHTTP(w, req)
+
+	var page models.PageUsers
+	if err := json.NewDecoder(w.Body).Decode(&page); err != nil {
+		t1.Fatal(err)
+	}
+	for _, user := range page.Users {
+		if user.Username == "username" {
+			return user.ID
+		}
+	}
+
+	t1.Fatal("super admin is not found")
+	return ""
+}
+
 func deleteUserPrepareReq() []*http.Request {
 	requests := make([]*http.Request, 0, 4)
 
@@ -504,3 +659,38 @@ func deleteUserPrepareReq() []*http.Request {
 
 	return requests
 }
+
+func TestServer_passwordPolicy(t1 *testing.T) {
+	cfg := serviceCfg
+	cfg.PasswordMinLength = 8
+	server := prepareServerWithConfigs(serverCfg, cfg)
+	serve := func(method, url, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.SetBasicAuth("username", "password")
+		w := httptest.NewRecorder()
+		server.httpServer.Handler.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("POST", "/user", `{"username":"alice","password":"short","email":"alice@email.com"}`)
+	assert.Equal(t1, http.StatusBadRequest, w.Code)
+	assert.Contains(t1, w.Body.String(), validation.ErrWeakPassword.Error())
+	assert.Equal(t1, http.StatusBadRequest, serve("PATCH", "/user/db783cb2-8037-4b75-8c01-ab9065e568e3", `{"password":"short"}`).Code)
+
+	w = serve("POST", "/user/batch", `{"all_or_nothing": false, "operations": [
+		{"op": "create", "user": {"username": "alice", "email": "alice@email.com", "password": "short"}},
+		{"op": "patch", "id": "db783cb2-8037-4b75-8c01-ab9065e568e3", "user": {"password": "long enough"}}
+	]}`)
+	assert.Equal(t1, http.StatusOK, w.Code)
+	var response models.BatchResponse
+	assert.NoError(t1, json.NewDecoder(w.Body).Decode(&response))
+	if assert.Len(t1, response.Results, 2) {
+		assert.Equal(t1, models.BatchFailed, response.Results[0].Status)
+		assert.Contains(t1, response.Results[0].Error, validation.ErrWeakPassword.Error())
+		assert.Equal(t1, models.BatchOK, response.Results[1].Status)
+	}
+
+	cfg.PasswordMinLength = 1
+	server.service.(*service.Service).SetPasswordPolicy(cfg.PasswordPolicy)
+	assert.Equal(t1, http.StatusOK, serve("POST", "/user", `{"username":"alice","password":"short","email":"alice@email.com"}`).Code)
+}
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/KseniiaSalmina/Profiles/internal/api/models"
+	"github.com/KseniiaSalmina/Profiles/internal/validation"
+)
+
+const healthCheckTimeout = 2 * time.Second
+
+var ErrShuttingDown = errors.New("server is shutting down")
+
+// HealthChecker is implemented by components the service can't work without, storage backends in the first place.
+type HealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+type healthCheck struct {
+	name    string
+	checker HealthChecker
+}
+
+// AddHealthCheck registers component checked by readiness and detailed health endpoints.
+func (s *Server) AddHealthCheck(name string, checker HealthChecker) {
+	s.healthChecks = append(s.healthChecks, healthCheck{name: name, checker: checker})
+}
+
+// Drain makes readiness fail, so that orchestrator stops sending new requests before server is shut down.
+func (s *Server) Drain() {
+	s.draining.Store(true)
+}
+
+func (s *Server) checkHealth(ctx context.Context) models.Health {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	health := models.Health{
+		Status:     models.HealthOK,
+		Components: make(map[string]models.ComponentHealth, len(s.healthChecks)+1),
+	}
+
+	var serverErr error
+	if s.draining.Load() {
+		serverErr = ErrShuttingDown
+	}
+	health.Components["server"] = componentHealth(serverErr, 0)
+
+	for _, check := range s.healthChecks {
+		start := time.Now()
+		err := check.checker.HealthCheck(ctx)
+		health.Components[check.name] = componentHealth(err, time.Since(start))
+	}
+
+	for _, component := range health.Components {
+		if component.Status != models.HealthOK {
+			health.Status = models.HealthFail
+		}
+	}
+
+	return health
+}
+
+func componentHealth(err error, duration time.Duration) models.ComponentHealth {
+	component := models.ComponentHealth{Status: models.HealthOK, Duration: duration.String()}
+	if err != nil {
+		component.Status = models.HealthFail
+		component.Error = err.Error()
+	}
+
+	return component
+}
+
+// @Summary Liveness
+// @Tags health
+// @Description report that process is alive, does not check any component
+// @Success 200 {string} string
+// @Router /healthz [get]
+func (s *Server) getLiveness(w http.ResponseWriter, r *http.Request) {
+	_, _ = w.Write([]byte(models.HealthOK))
+}
+
+// @Summary Readiness
+// @Tags health
+// @Description report that service is ready to serve requests: all components are reachable and server is not shutting down
+// @Success 200 {string} string
+// @Failure 503 {string} string "names of failed components"
+// @Router /readyz [get]
+func (s *Server) getReadiness(w http.ResponseWriter, r *http.Request) {
+	health := s.checkHealth(r.Context())
+	if health.Status == models.HealthOK {
+		_, _ = w.Write([]byte(models.HealthOK))
+		return
+	}
+
+	failed := make([]string, 0)
+	for name, component := range health.Components {
+		if component.Status != models.HealthOK {
+			failed = append(failed, name)
+		}
+	}
+	slices.Sort(failed)
+
+	s.log(r).WithField("components", failed).Warn("readiness handler, service is not ready")
+	http.Error(w, "not ready: "+strings.Join(failed, ", "), http.StatusServiceUnavailable)
+}
+
+// @Summary Detailed health
+// @Security BasicAuth
+// @Tags admin
+// @Description return state of every component with check duration and error
+// @Return json
+// @Produce json,application/yaml,application/msgpack
+// @Success 200 {object} models.Health
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 503 {object} models.Health
+// @Router /v1/health [get]
+func (s *Server) getHealth(w http.ResponseWriter, r *http.Request) {
+	caller, err := s.authorization(r)
+	if err != nil {
+		s.log(r).WithError(err).Info("get health handler, failed authorization")
+		http.Error(w, err.Error(), authErrorStatus(w, err))
+		return
+	}
+
+	if !caller.Admin {
+		s.log(r).Info("get health handler, user is not admin")
+		http.Error(w, validation.ErrIsNotAdmin.Error(), http.StatusForbidden)
+		return
+	}
+
+	health := s.checkHealth(r.Context())
+
+	statusCode := http.StatusOK
+	if health.Status != models.HealthOK {
+		statusCode = http.StatusServiceUnavailable
+	}
+	s.respond(w, r, statusCode, health)
+}
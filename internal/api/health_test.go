@@ -0,0 +1,68 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KseniiaSalmina/Profiles/internal/api/models"
+)
+
+type testHealthChecker struct {
+	err error
+}
+
+func (c *testHealthChecker) HealthCheck(ctx context.Context) error {
+	return c.err
+}
+
+func TestServer_health(t1 *testing.T) {
+	server := prepareServer()
+	storage := &testHealthChecker{}
+	server.AddHealthCheck("storage", storage)
+
+	serve := func(url, username, password string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", url, nil)
+		if username != "" {
+			req.SetBasicAuth(username, password)
+		}
+		w := httptest.NewRecorder()
+		server.httpServer.Handler.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t1, http.StatusOK, serve("/healthz", "", "").Code)
+	assert.Equal(t1, http.StatusOK, serve("/readyz", "", "").Code)
+	assert.Equal(t1, http.StatusUnauthorized, serve("/health", "", "").Code)
+	assert.Equal(t1, http.StatusForbidden, serve("/health", "testUser3", "password").Code)
+
+	w := serve("/health", "username", "password")
+	assert.Equal(t1, http.StatusOK, w.Code)
+	var health models.Health
+	assert.NoError(t1, json.NewDecoder(w.Body).Decode(&health))
+	assert.Equal(t1, models.HealthOK, health.Status)
+	assert.Equal(t1, models.HealthOK, health.Components["storage"].Status)
+
+	storage.err = errors.New("storage is down")
+	w = serve("/readyz", "", "")
+	assert.Equal(t1, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t1, w.Body.String(), "storage")
+
+	w = serve("/health", "username", "password")
+	assert.Equal(t1, http.StatusServiceUnavailable, w.Code)
+	assert.NoError(t1, json.NewDecoder(w.Body).Decode(&health))
+	assert.Equal(t1, models.HealthFail, health.Status)
+	assert.Equal(t1, "storage is down", health.Components["storage"].Error)
+
+	storage.err = nil
+	server.Drain()
+	w = serve("/readyz", "", "")
+	assert.Equal(t1, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t1, w.Body.String(), "server")
+	assert.Equal(t1, http.StatusOK, serve("/healthz", "", "").Code)
+}
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/KseniiaSalmina/Profiles/internal/idempotency"
+)
+
+const (
+	idempotencyKeyHeader     = "Idempotency-Key"
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+	// maxIdempotentBodySize limits body read to fingerprint request, bigger requests with key are rejected.
+	maxIdempotentBodySize = 10 << 20
+)
+
+var (
+	ErrIncorrectIdempotencyKey = errors.New("idempotency key should be from 1 to 255 characters")
+	ErrIdempotentBodyTooLarge  = errors.New("request body with idempotency key should be at most 10 MiB")
+)
+
+// replayedHeaders are saved with response, other headers belong to the request being served.
+var replayedHeaders = []string{"Content-Type", "Location"}
+
+// SetIdempotencyStore sets store of saved responses, responses are kept in memory by default.
+func (s *Server) SetIdempotencyStore(store idempotency.Store) {
+	s.idempotencyStore = store
+}
+
+// idempotent saves the first response to request with Idempotency-Key header and replays it to retries of the caller.
+// Key is scoped by organization and authorized caller, the same key with another method, path, query, body
+// or negotiated response format is rejected.
+// Responses which are not final for the caller, like server errors and rate limits, are not saved, so retry runs again.
+// Caller is authorized before key is reserved and passed to the handler in context, so credentials are checked once.
+func (s *Server) idempotent(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(idempotencyKeyHeader)
+		ttl := s.settings.Load().idempotencyTTL
+		if key == "" || ttl <= 0 {
+			next(w, r)
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			s.log(r).Info("idempotency, incorrect key")
+			http.Error(w, ErrIncorrectIdempotencyKey.Error(), http.StatusBadRequest)
+			return
+		}
+
+		caller, err := s.authorization(r)
+		if err != nil {
+			s.log(r).WithError(err).Info("idempotency, failed authorization")
+			http.Error(w, err.Error(), authErrorStatus(w, err))
+			return
+		}
+		r = r.WithContext(context.WithValue(r.Context(), callerKey{}, caller))
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentBodySize))
+		if err != nil {
+			s.log(r).WithError(err).Info("idempotency, failed to read request body")
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				http.Error(w, ErrIdempotentBodyTooLarge.Error(), http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		storeKey := hashKey(organizationFromContext(r.Context()) + "\n" + caller.ID + "\n" + key)
+		// saved body is encoded in negotiated format, so retry accepting another format is another request
+		format, _ := r.Context().Value(formatKey{}).(string)
+		fingerprint := hashKey(r.Method + " " + r.URL.Path + "?" + r.URL.RawQuery + "\n" + format + "\n" + string(body))
+
+		response, err := s.idempotencyStore.Start(r.Context(), storeKey, fingerprint, time.Now().Add(ttl))
+		switch {
+		case errors.Is(err, idempotency.ErrInProgress):
+			s.log(r).Info("idempotency, request is in progress")
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		case errors.Is(err, idempotency.ErrMismatch):
+			s.log(r).Info("idempotency, key is used for another request")
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		case err != nil:
+			s.log(r).WithError(err).Warn("idempotency, failed to check key")
+			http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
+			return
+		case response != nil:
+			s.log(r).Info("idempotency, response is replayed")
+			for name, values := range response.Header {
+				w.Header()[name] = values
+			}
+			w.Header().Set(idempotentReplayedHeader, "true")
+			w.WriteHeader(response.Status)
+			_, _ = w.Write(response.Body)
+			return
+		}
+
+		// store is updated even if request context is canceled, otherwise key stays reserved until it expires
+		ctx := context.WithoutCancel(r.Context())
+		rec := &responseRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+		served := false
+		defer func() {
+			if !served {
+				_ = s.idempotencyStore.Cancel(ctx, storeKey)
+			}
+		}()
+
+		next(rec, r)
+		served = true
+
+		if !finalStatus(rec.statusCode) {
+			if err := s.idempotencyStore.Cancel(ctx, storeKey); err != nil {
+				s.log(r).WithError(err).Warn("idempotency, failed to release key")
+			}
+			return
+		}
+
+		header := make(http.Header)
+		for _, name := range replayedHeaders {
+			if values := w.Header().Values(name); len(values) != 0 {
+				header[name] = values
+			}
+		}
+		saved := idempotency.Response{Status: rec.statusCode, Header: header, Body: rec.body.Bytes()}
+		if err := s.idempotencyStore.Finish(ctx, storeKey, saved); err != nil {
+			s.log(r).WithError(err).Warn("idempotency, failed to save response")
+		}
+	}
+}
+
+// finalStatus reports whether retry of the request would get the same response.
+func finalStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusUnauthorized, http.StatusTooManyRequests, statusClientClosedRequest:
+		return false
+	default:
+		return statusCode < http.StatusInternalServerError
+	}
+}
+
+// responseRecorder copies response to the client and keeps it for retries.
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(statusCode int) {
+	if !r.wroteHeader {
+		r.statusCode = statusCode
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Unwrap lets http.ResponseController reach the original writer.
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
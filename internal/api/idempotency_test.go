@@ -0,0 +1,120 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_idempotent(t1 *testing.T) {
+	const alice = `{"username": "alice", "password": "secret", "email": "alice@email.com"}`
+
+	server := prepareServer()
+	serve := func(method, url, body, username, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.SetBasicAuth(username, "password")
+		if key != "" {
+			req.Header.Set(idempotencyKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		server.httpServer.Handler.ServeHTTP(w, req)
+		return w
+	}
+	countUsers := func() int {
+		w := serve("GET", "/user?limit=100", "", "username", "")
+		return strings.Count(w.Body.String(), `"username"`)
+	}
+	w := serve("POST", "/user", `{"username": "admin", "password": "password", "email": "admin@email.com", "admin": true}`, "username", "")
+	require.Equal(t1, http.StatusOK, w.Code)
+	users := countUsers()
+
+	first := serve("POST", "/user", alice, "username", "create-alice")
+	require.Equal(t1, http.StatusOK, first.Code, first.Body.String())
+	assert.Empty(t1, first.Header().Get(idempotentReplayedHeader))
+
+	t1.Run("retry is replayed", func(t1 *testing.T) {
+		w := serve("POST", "/user", alice, "username", "create-alice")
+		assert.Equal(t1, http.StatusOK, w.Code)
+		assert.Equal(t1, first.Body.String(), w.Body.String())
+		assert.Equal(t1, "true", w.Header().Get(idempotentReplayedHeader))
+		assert.Equal(t1, users+1, countUsers())
+	})
+
+	t1.Run("key of another request", func(t1 *testing.T) {
+		w := serve("POST", "/user", `{"username": "bob", "password": "secret", "email": "bob@email.com"}`, "username", "create-alice")
+		assert.Equal(t1, http.StatusUnprocessableEntity, w.Code)
+		w = serve("PATCH", "/user/"+testUsers[2].ID, alice, "username", "create-alice")
+		assert.Equal(t1, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t1.Run("key of another response format", func(t1 *testing.T) {
+		req := httptest.NewRequest("POST", "/user", strings.NewReader(alice))
+		req.SetBasicAuth("username", "password")
+		req.Header.Set(idempotencyKeyHeader, "create-alice")
+		req.Header.Set("Accept", "application/yaml")
+		w := httptest.NewRecorder()
+		server.httpServer.Handler.ServeHTTP(w, req)
+		assert.Equal(t1, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t1.Run("key of another caller", func(t1 *testing.T) {
+		w := serve("POST", "/user", alice, "admin", "create-alice")
+		assert.Equal(t1, http.StatusBadRequest, w.Code, "user is not unique")
+		assert.Empty(t1, w.Header().Get(idempotentReplayedHeader))
+	})
+
+	t1.Run("server error is not saved", func(t1 *testing.T) {
+		w := serve("POST", "/user", `{"username": `, "username", "create-bob")
+		require.Equal(t1, http.StatusInternalServerError, w.Code)
+		w = serve("POST", "/user", `{"username": "bob", "password": "secret", "email": "bob@email.com"}`, "username", "create-bob")
+		assert.Equal(t1, http.StatusOK, w.Code)
+		assert.Empty(t1, w.Header().Get(idempotentReplayedHeader))
+	})
+
+	t1.Run("patch", func(t1 *testing.T) {
+		w := serve("PATCH", "/user/"+testUsers[2].ID, `{"email": "carol@email.com"}`, "username", "patch-carol")
+		require.Equal(t1, http.StatusOK, w.Code)
+		w = serve("PATCH", "/user/"+testUsers[2].ID, `{"email": "carol@email.com"}`, "username", "patch-carol")
+		assert.Equal(t1, http.StatusOK, w.Code)
+		assert.Equal(t1, "true", w.Header().Get(idempotentReplayedHeader))
+	})
+
+	t1.Run("unauthorized request does not reserve key", func(t1 *testing.T) {
+		w := serve("POST", "/user", alice, "unknown", "create-dave")
+		require.Equal(t1, http.StatusUnauthorized, w.Code)
+		w = serve("POST", "/user", `{"username": "dave", "password": "secret", "email": "dave@email.com"}`, "username", "create-dave")
+		assert.Equal(t1, http.StatusOK, w.Code)
+		assert.Empty(t1, w.Header().Get(idempotentReplayedHeader))
+	})
+
+	t1.Run("too large body", func(t1 *testing.T) {
+		w := serve("POST", "/user", strings.Repeat(" ", maxIdempotentBodySize+1)+alice, "username", "create-large")
+		assert.Equal(t1, http.StatusRequestEntityTooLarge, w.Code)
+		w = serve("POST", "/user", strings.Repeat(" ", maxIdempotentBodySize+1)+alice, "unknown", "create-large")
+		assert.Equal(t1, http.StatusUnauthorized, w.Code, "body is not read before authorization")
+	})
+
+	t1.Run("too long key", func(t1 *testing.T) {
+		w := serve("POST", "/user", alice, "username", strings.Repeat("k", maxIdempotencyKeyLength+1))
+		assert.Equal(t1, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestServer_idempotentDisabled(t1 *testing.T) {
+	cfg := serverCfg
+	cfg.IdempotencyTTL = 0
+	server := prepareServerWithConfigs(cfg, serviceCfg)
+
+	for _, wantCode := range []int{http.StatusOK, http.StatusBadRequest} {
+		req := httptest.NewRequest("POST", "/user", strings.NewReader(`{"username": "alice", "password": "secret", "email": "alice@email.com"}`))
+		req.SetBasicAuth("username", "password")
+		req.Header.Set(idempotencyKeyHeader, "create-alice")
+		w := httptest.NewRecorder()
+		server.httpServer.Handler.ServeHTTP(w, req)
+		assert.Equal(t1, wantCode, w.Code)
+	}
+}
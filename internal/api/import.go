@@ -0,0 +1,474 @@
+package api
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/KseniiaSalmina/Profiles/internal/api/models"
+	"github.com/KseniiaSalmina/Profiles/internal/config"
+	"github.com/KseniiaSalmina/Profiles/internal/service"
+	"github.com/KseniiaSalmina/Profiles/internal/validation"
+)
+
+// Best effort import is streamed: each row is created as soon as it is read. All-or-nothing import validates
+// every row before the first user is created and background import outlives request body, so they are kept
+// in memory until processed, size of body and number of rows bound memory of one import.
+const (
+	// maxImportSyncRows is the largest import processed during request, bigger imports run in background.
+	maxImportSyncRows = 20
+	// importRowCost is expected time to create one user, most of it is hashing of password.
+	importRowCost = config.PasswordHashCost
+	maxImportRows = 10000
+	maxImportLine = 1 << 20
+	maxImportSize = 10 << 20
+
+	attributeColumnPrefix = "attr."
+)
+
+var (
+	ErrUnsupportedImportFormat = errors.New("import should be text/csv or application/x-ndjson")
+	ErrUnknownImportMode       = errors.New("import mode should be all_or_nothing or best_effort")
+	ErrUnknownImportColumn     = errors.New("unknown import column")
+	ErrTooManyImportRows       = errors.New("too many rows in import")
+	ErrImportTooLarge          = errors.New("import should be at most 10 MiB")
+)
+
+// @Summary Import users
+// @Security BasicAuth
+// @Tags admin
+// @Description create users from CSV with header (username, email, password, admin, super_admin and attr.<name> columns) or from JSON Lines of user objects.
+// @Description Best effort import without async=true is processed row by row while it is read, reading stops on request timeout and the report contains the error.
+// @Description All-or-nothing imports of more than 20 rows (fewer if request timeout is short) or imports with async=true run in background and return 202 with Location of import status.
+// @Description Import is limited to 10000 rows, imports kept in memory are limited to 10 MiB.
+// @Accept text/csv,application/x-ndjson
+// @Return json
+// @Produce json,application/yaml,application/msgpack
+// @Param mode query string false "all_or_nothing (default) creates users only if all rows are valid, best_effort creates valid rows"
+// @Param dry_run query bool false "only validate rows"
+// @Param async query bool false "run import in background"
+// @Param users body string true "users in CSV or JSON Lines"
+// @Param Idempotency-Key header string false "retries with the same key replay the first response"
+// @Success 200 {object} models.ImportJob
+// @Success 202 {object} models.ImportJob
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 413 {string} string
+// @Failure 415 {string} string
+// @Failure 500 {string} string
+// @Failure 503 {string} string
+// @Router /v1/user/import [post]
+func (s *Server) postUserImport(w http.ResponseWriter, r *http.Request) {
+	caller, err := s.authorization(r)
+	if err != nil {
+		s.log(r).WithError(err).Info("post user import handler, failed authorization")
+		http.Error(w, err.Error(), authErrorStatus(w, err))
+		return
+	}
+
+	if !caller.Admin {
+		s.log(r).Info("post user import handler, user is not admin")
+		http.Error(w, validation.ErrIsNotAdmin.Error(), http.StatusForbidden)
+		return
+	}
+
+	opts, async, err := getImportOptions(r)
+	if err != nil {
+		s.log(r).WithError(err).Info("post user import handler, invalid options")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	stream := opts.Mode == models.ImportBestEffort && !async
+	defer r.Body.Close()
+	if !stream {
+		r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	var reader importReader
+	switch mediaType {
+	case "text/csv":
+		var schema models.AttributeSchema
+		if schema, err = s.service.GetAttributeSchema(r.Context()); err != nil {
+			s.log(r).WithError(err).Info("post user import handler, failed to get attribute schema")
+			http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
+			return
+		}
+		if reader, err = newCSVImportReader(r.Body, schema); err != nil {
+			s.log(r).WithError(err).Info("post user import handler, failed to read csv header")
+			http.Error(w, err.Error(), importReadErrorStatus(err))
+			return
+		}
+	case "application/x-ndjson", "application/jsonl":
+		reader = newJSONLinesImportReader(r.Body)
+	default:
+		s.log(r).WithField("content_type", mediaType).Info("post user import handler, unsupported format")
+		http.Error(w, ErrUnsupportedImportFormat.Error(), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	source := func() (models.ImportRow, error) {
+		row, err := reader.next()
+		if err == nil {
+			checkImportRow(&row, caller.SuperAdmin)
+		}
+		return row, err
+	}
+
+	orgID := organizationFromContext(r.Context())
+	if stream {
+		job, err := s.service.StreamImport(r.Context(), orgID, source, opts)
+		if err != nil {
+			s.log(r).WithError(err).Info("post user import handler, failed to import users")
+			http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, job)
+		return
+	}
+
+	rows, err := readImportRows(source)
+	if err != nil {
+		s.log(r).WithError(err).Info("post user import handler, failed to read import")
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			err = ErrImportTooLarge
+		}
+		http.Error(w, err.Error(), importReadErrorStatus(err))
+		return
+	}
+
+	if async || len(rows) > importSyncRows(s.settings.Load().requestTimeout) {
+		job, err := s.service.StartImport(r.Context(), orgID, rows, opts)
+		if err != nil {
+			s.log(r).WithError(err).Info("post user import handler, failed to start import")
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		// relative reference keeps tenant path prefix of the request
+		w.Header().Set("Location", "import/"+job.ID)
+		s.respond(w, r, http.StatusAccepted, job)
+		return
+	}
+
+	job, err := s.service.ImportUsers(r.Context(), orgID, rows, opts)
+	if err != nil {
+		s.log(r).WithError(err).Info("post user import handler, failed to import users")
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	s.respond(w, r, http.StatusOK, job)
+}
+
+// @Summary Get user import
+// @Security BasicAuth
+// @Tags admin
+// @Description return progress and per-row results of import
+// @Return json
+// @Produce json,application/yaml,application/msgpack
+// @Param id path string true "import id in uuid format"
+// @Success 200 {object} models.ImportJob
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 404 {string} string
+// @Router /v1/user/import/{id} [get]
+func (s *Server) getUserImport(w http.ResponseWriter, r *http.Request) {
+	caller, err := s.authorization(r)
+	if err != nil {
+		s.log(r).WithError(err).Info("get user import handler, failed authorization")
+		http.Error(w, err.Error(), authErrorStatus(w, err))
+		return
+	}
+
+	if !caller.Admin {
+		s.log(r).Info("get user import handler, user is not admin")
+		http.Error(w, validation.ErrIsNotAdmin.Error(), http.StatusForbidden)
+		return
+	}
+
+	id, err := getPathUUID(r, "id")
+	if err != nil {
+		s.log(r).WithError(err).Info("get user import handler, failed to get id")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	job, err := s.service.GetImport(r.Context(), organizationFromContext(r.Context()), id)
+	if err != nil {
+		s.log(r).WithError(err).Info("get user import handler, failed to get import")
+		statusCode := http.StatusInternalServerError
+		if errors.Is(err, service.ErrImportDoesNotExist) {
+			statusCode = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), serviceErrorStatus(err, statusCode))
+		return
+	}
+
+	s.respond(w, r, http.StatusOK, job)
+}
+
+// importSyncRows limits import processed during request, so it takes at most half of request timeout.
+func importSyncRows(requestTimeout time.Duration) int {
+	if requestTimeout <= 0 {
+		return maxImportSyncRows
+	}
+
+	return min(maxImportSyncRows, int(requestTimeout/2/importRowCost))
+}
+
+func getImportOptions(r *http.Request) (models.ImportOptions, bool, error) {
+	query := r.URL.Query()
+	opts := models.ImportOptions{Mode: query.Get("mode")}
+
+	switch opts.Mode {
+	case "":
+		opts.Mode = models.ImportAllOrNothing
+	case models.ImportAllOrNothing, models.ImportBestEffort:
+	default:
+		return opts, false, ErrUnknownImportMode
+	}
+
+	var err error
+	if value := query.Get("dry_run"); value != "" {
+		if opts.DryRun, err = strconv.ParseBool(value); err != nil {
+			return opts, false, fmt.Errorf("failed to get dry_run: %w", err)
+		}
+	}
+
+	var async bool
+	if value := query.Get("async"); value != "" {
+		if async, err = strconv.ParseBool(value); err != nil {
+			return opts, false, fmt.Errorf("failed to get async: %w", err)
+		}
+	}
+
+	return opts, async, nil
+}
+
+// checkImportRow applies checks of post user handler to parsed row.
+func checkImportRow(row *models.ImportRow, superAdmin bool) {
+	if row.Err != nil {
+		return
+	}
+
+	if err := validation.UserAdd(row.User); err != nil {
+		row.Err = err
+		return
+	}
+
+	if row.User.SuperAdmin && !superAdmin {
+		row.Err = validation.ErrIsNotSuperAdmin
+	}
+}
+
+// importReader returns parsed rows one by one, io.EOF means there are no more rows.
+// Malformed row fails only itself, error of reading fails whole import.
+type importReader interface {
+	next() (models.ImportRow, error)
+}
+
+// readImportRows reads whole import for processing after it is read.
+func readImportRows(source service.ImportSource) ([]models.ImportRow, error) {
+	rows := make([]models.ImportRow, 0)
+	for {
+		row, err := source()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+}
+
+func importReadErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrImportTooLarge), errors.Is(err, ErrTooManyImportRows):
+		return http.StatusRequestEntityTooLarge
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// csvImportReader reads records one by one, attribute cells are converted to types of attribute schema.
+type csvImportReader struct {
+	reader *csv.Reader
+	header []string
+	schema models.AttributeSchema
+	rows   int
+}
+
+// newCSVImportReader reads header, unknown column fails whole import.
+func newCSVImportReader(body io.Reader, schema models.AttributeSchema) (*csvImportReader, error) {
+	reader := csv.NewReader(body)
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, ErrImportTooLarge
+		}
+		return nil, fmt.Errorf("failed to read csv header: %w", err)
+	}
+	header = append([]string(nil), header...)
+
+	for _, column := range header {
+		switch column {
+		case "username", "email", "password", "admin", "super_admin":
+		default:
+			if !strings.HasPrefix(column, attributeColumnPrefix) {
+				return nil, fmt.Errorf("%w: %q", ErrUnknownImportColumn, column)
+			}
+		}
+	}
+
+	return &csvImportReader{reader: reader, header: header, schema: schema}, nil
+}
+
+func (c *csvImportReader) next() (models.ImportRow, error) {
+	var row models.ImportRow
+
+	record, err := c.reader.Read()
+	if errors.Is(err, io.EOF) {
+		return row, io.EOF
+	}
+
+	if c.rows == maxImportRows {
+		return row, fmt.Errorf("%w, limit is %d", ErrTooManyImportRows, maxImportRows)
+	}
+	c.rows++
+
+	switch {
+	case errors.Is(err, csv.ErrFieldCount):
+		row.Err = err
+	case err != nil:
+		return row, fmt.Errorf("failed to read csv: %w", err)
+	default:
+		row.User, row.Err = csvUser(c.header, record, c.schema)
+	}
+
+	return row, nil
+}
+
+func csvUser(header, record []string, schema models.AttributeSchema) (models.UserAdd, error) {
+	var user models.UserAdd
+	var err error
+
+	for i, column := range header {
+		value := record[i]
+		switch column {
+		case "username":
+			user.Username = value
+		case "email":
+			user.Email = value
+		case "password":
+			user.Password = value
+		case "admin":
+			user.Admin, err = csvBool(column, value)
+		case "super_admin":
+			user.SuperAdmin, err = csvBool(column, value)
+		default:
+			if value == "" {
+				continue
+			}
+			if user.Attributes == nil {
+				user.Attributes = make(map[string]any)
+			}
+			name := strings.TrimPrefix(column, attributeColumnPrefix)
+			user.Attributes[name], err = csvAttribute(name, value, schema)
+		}
+		if err != nil {
+			return user, err
+		}
+	}
+
+	return user, nil
+}
+
+func csvBool(column, value string) (bool, error) {
+	if value == "" {
+		return false, nil
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse %s: %w", column, err)
+	}
+
+	return b, nil
+}
+
+// csvAttribute converts cell to type of attribute, attributes outside of schema stay strings.
+func csvAttribute(name, value string, schema models.AttributeSchema) (any, error) {
+	var result any
+	var err error
+
+	switch schema.Properties[name].Type {
+	case "number", "integer":
+		result, err = strconv.ParseFloat(value, 64)
+	case "boolean":
+		result, err = strconv.ParseBool(value)
+	default:
+		result = value
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w %q: %w", validation.ErrInvalidAttribute, name, err)
+	}
+
+	return result, nil
+}
+
+// jsonLinesImportReader reads user object per line, empty lines are skipped.
+type jsonLinesImportReader struct {
+	scanner *bufio.Scanner
+	rows    int
+}
+
+func newJSONLinesImportReader(body io.Reader) *jsonLinesImportReader {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxImportLine)
+
+	return &jsonLinesImportReader{scanner: scanner}
+}
+
+func (j *jsonLinesImportReader) next() (models.ImportRow, error) {
+	var row models.ImportRow
+
+	for j.scanner.Scan() {
+		line := j.scanner.Bytes()
+		if len(strings.TrimSpace(string(line))) == 0 {
+			continue
+		}
+
+		if j.rows == maxImportRows {
+			return row, fmt.Errorf("%w, limit is %d", ErrTooManyImportRows, maxImportRows)
+		}
+		j.rows++
+
+		if err := json.Unmarshal(line, &row.User); err != nil {
+			row.Err = fmt.Errorf("failed to unmarshal user: %w", err)
+		}
+		return row, nil
+	}
+
+	if err := j.scanner.Err(); err != nil {
+		return row, fmt.Errorf("failed to read json lines: %w", err)
+	}
+
+	return row, io.EOF
+}
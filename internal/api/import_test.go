@@ -0,0 +1,329 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/KseniiaSalmina/Profiles/internal/api/models"
+	"github.com/KseniiaSalmina/Profiles/internal/service"
+)
+
+func TestServer_userImport(t1 *testing.T) {
+	const csvUsers = "username,email,password,admin,attr.floor\n" +
+		"alice,alice@email.com,secret,true,2\n" +
+		"bob,bob@email.com,secret,,\n" +
+		"testUser3,carol@email.com,secret,,\n" +
+		"dave,not an email,secret,,\n"
+
+	tests := []struct {
+		name        string
+		url         string
+		contentType string
+		body        string
+		username    string
+		wantCode    int
+		wantJob     models.ImportJob
+		wantErrors  []int // rows with error
+		wantCreated []string
+	}{
+		{
+			name:        "all or nothing aborts",
+			url:         "/user/import",
+			contentType: "text/csv",
+			body:        csvUsers,
+			username:    "username",
+			wantCode:    http.StatusOK,
+			wantJob:     models.ImportJob{Status: models.ImportDone, Mode: models.ImportAllOrNothing, Total: 4, Processed: 4, Failed: 2},
+			wantErrors:  []int{3, 4},
+		},
+		{
+			name:        "best effort creates valid rows",
+			url:         "/user/import?mode=best_effort",
+			contentType: "text/csv; charset=utf-8",
+			body:        csvUsers,
+			username:    "username",
+			wantCode:    http.StatusOK,
+			wantJob:     models.ImportJob{Status: models.ImportDone, Mode: models.ImportBestEffort, Total: 4, Processed: 4, Succeeded: 2, Failed: 2},
+			wantErrors:  []int{3, 4},
+			wantCreated: []string{"alice", "bob"},
+		},
+		{
+			name:        "dry run",
+			url:         "/user/import?mode=best_effort&dry_run=true",
+			contentType: "text/csv",
+			body:        csvUsers,
+			username:    "username",
+			wantCode:    http.StatusOK,
+			wantJob:     models.ImportJob{Status: models.ImportDone, Mode: models.ImportBestEffort, DryRun: true, Total: 4, Processed: 4, Succeeded: 2, Failed: 2},
+			wantErrors:  []int{3, 4},
+		},
+		{
+			name:        "json lines",
+			url:         "/user/import",
+			contentType: "application/x-ndjson",
+			body:        `{"username": "alice", "email": "alice@email.com", "password": "secret", "attributes": {"floor": 2}}` + "\n\n" + `{"username": "bob", "email": "bob@email.com", "password": "secret"}` + "\n",
+			username:    "username",
+			wantCode:    http.StatusOK,
+			wantJob:     models.ImportJob{Status: models.ImportDone, Mode: models.ImportAllOrNothing, Total: 2, Processed: 2, Succeeded: 2},
+			wantCreated: []string{"alice", "bob"},
+		},
+		{
+			name:        "malformed json line",
+			url:         "/user/import?mode=best_effort",
+			contentType: "application/x-ndjson",
+			body:        `{"username": "alice", "email": "alice@email.com", "password": "secret"}` + "\n" + `{"username": ` + "\n",
+			username:    "username",
+			wantCode:    http.StatusOK,
+			wantJob:     models.ImportJob{Status: models.ImportDone, Mode: models.ImportBestEffort, Total: 2, Processed: 2, Succeeded: 1, Failed: 1},
+			wantErrors:  []int{2},
+			wantCreated: []string{"alice"},
+		},
+		{
+			name:        "duplicate username and super admin",
+			url:         "/user/import?mode=best_effort",
+			contentType: "text/csv",
+			body:        "username,email,password,super_admin\nalice,alice@email.com,secret,\nalice,alice2@email.com,secret,\nbob,bob@email.com,secret,true\n",
+			username:    "admin",
+			wantCode:    http.StatusOK,
+			wantJob:     models.ImportJob{Status: models.ImportDone, Mode: models.ImportBestEffort, Total: 3, Processed: 3, Succeeded: 1, Failed: 2},
+			wantErrors:  []int{2, 3},
+			wantCreated: []string{"alice"},
+		},
+		{name: "unknown column", url: "/user/import", contentType: "text/csv", body: "username,phone\n", username: "username", wantCode: http.StatusBadRequest},
+		{name: "unknown mode", url: "/user/import?mode=some", contentType: "text/csv", body: csvUsers, username: "username", wantCode: http.StatusBadRequest},
+		{name: "unsupported format", url: "/user/import", contentType: "application/json", body: "[]", username: "username", wantCode: http.StatusUnsupportedMediaType},
+		{name: "not admin", url: "/user/import", contentType: "text/csv", body: csvUsers, username: "testUser3", wantCode: http.StatusForbidden},
+		{name: "too large", url: "/user/import", contentType: "text/csv", body: "username,email,password\n" + strings.Repeat("a", maxImportSize), username: "username", wantCode: http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			server := prepareServer()
+			serve := func(method, url, contentType, body, username string) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(method, url, strings.NewReader(body))
+				req.Header.Set("Content-Type", contentType)
+				req.SetBasicAuth(username, "password")
+				w := httptest.NewRecorder()
+				server.httpServer.Handler.ServeHTTP(w, req)
+				return w
+			}
+			serve("POST", "/user", "application/json", `{"username": "admin", "password": "password", "email": "admin@email.com", "admin": true}`, "username")
+
+			w := serve("POST", tt.url, tt.contentType, tt.body, tt.username)
+			require.Equal(t1, tt.wantCode, w.Code, w.Body.String())
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+
+			var job models.ImportJob
+			require.NoError(t1, json.NewDecoder(w.Body).Decode(&job))
+			assert.Equal(t1, tt.wantJob.Status, job.Status)
+			assert.Equal(t1, tt.wantJob.Mode, job.Mode)
+			assert.Equal(t1, tt.wantJob.DryRun, job.DryRun)
+			assert.Equal(t1, tt.wantJob.Total, job.Total)
+			assert.Equal(t1, tt.wantJob.Processed, job.Processed)
+			assert.Equal(t1, tt.wantJob.Succeeded, job.Succeeded)
+			assert.Equal(t1, tt.wantJob.Failed, job.Failed)
+			assert.NotNil(t1, job.FinishedAt)
+			assert.Equal(t1, tt.wantJob.Mode == models.ImportAllOrNothing && tt.wantJob.Failed != 0, job.Error != "")
+
+			var failed []int
+			for _, row := range job.Rows {
+				if row.Error != "" {
+					failed = append(failed, row.Row)
+				}
+			}
+			assert.Equal(t1, tt.wantErrors, failed)
+
+			var created []string
+			for _, row := range job.Rows {
+				if row.ID == "" {
+					continue
+				}
+				created = append(created, row.Username)
+
+				w := serve("GET", "/user/"+row.ID, "", "", "username")
+				assert.Equal(t1, http.StatusOK, w.Code)
+				var user models.UserResponse
+				require.NoError(t1, json.NewDecoder(w.Body).Decode(&user))
+				assert.Equal(t1, row.Username, user.Username)
+			}
+			assert.Equal(t1, tt.wantCreated, created)
+
+			w = serve("GET", "/user?limit=100", "", "", "username")
+			var page models.PageUsers
+			require.NoError(t1, json.NewDecoder(w.Body).Decode(&page))
+			assert.Len(t1, page.Users, len(testUsers)+2+len(tt.wantCreated))
+		})
+	}
+}
+
+func TestServer_userImportAsync(t1 *testing.T) {
+	server := prepareServer()
+	serve := func(method, url, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "text/csv")
+		req.SetBasicAuth("username", "password")
+		w := httptest.NewRecorder()
+		server.httpServer.Handler.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("POST", "/tenant/default/user/import?async=true", "username,email,password\nalice,alice@email.com,secret\n")
+	require.Equal(t1, http.StatusAccepted, w.Code)
+	var job models.ImportJob
+	require.NoError(t1, json.NewDecoder(w.Body).Decode(&job))
+	assert.Equal(t1, "import/"+job.ID, w.Header().Get("Location"))
+	assert.Equal(t1, 1, job.Total)
+
+	assert.Eventually(t1, func() bool {
+		w := serve("GET", "/tenant/default/user/import/"+job.ID, "")
+		if w.Code != http.StatusOK {
+			return false
+		}
+		require.NoError(t1, json.NewDecoder(w.Body).Decode(&job))
+		return job.Status == models.ImportDone
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t1, 1, job.Succeeded)
+	assert.NotEmpty(t1, job.Rows[0].ID)
+
+	req := httptest.NewRequest("POST", "/organization", strings.NewReader(`{"name": "acme"}`))
+	req.SetBasicAuth("username", "password")
+	server.httpServer.Handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t1, http.StatusNotFound, serve("GET", "/tenant/acme/user/import/"+job.ID, "").Code, "import belongs to other organization")
+	assert.Equal(t1, http.StatusNotFound, serve("GET", "/user/import/34775464-a73b-4445-8866-1e6061c3b70b", "").Code)
+}
+
+func TestServer_userImportStream(t1 *testing.T) {
+	server := prepareServer()
+	serve := func(method, url, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "text/csv")
+		req.SetBasicAuth("username", "password")
+		w := httptest.NewRecorder()
+		server.httpServer.Handler.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("POST", "/user/import?mode=best_effort", "username,email,password\nalice,alice@email.com,secret\nbob,\"bob@email.com,secret\n")
+	require.Equal(t1, http.StatusOK, w.Code)
+	var job models.ImportJob
+	require.NoError(t1, json.NewDecoder(w.Body).Decode(&job))
+	assert.Equal(t1, models.ImportDone, job.Status)
+	assert.Contains(t1, job.Error, "failed to read csv")
+	assert.Equal(t1, 1, job.Total)
+	assert.Equal(t1, 1, job.Succeeded)
+	require.Len(t1, job.Rows, 1)
+	assert.Equal(t1, http.StatusOK, serve("GET", "/user/"+job.Rows[0].ID, "").Code, "rows read before error are created")
+}
+
+func TestServer_userImportJobs(t1 *testing.T) {
+	server := prepareServer()
+	serve := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/user/import?async=true", strings.NewReader(body))
+		req.Header.Set("Content-Type", "text/csv")
+		req.SetBasicAuth("username", "password")
+		w := httptest.NewRecorder()
+		server.httpServer.Handler.ServeHTTP(w, req)
+		return w
+	}
+	body := func(prefix string) string {
+		var b strings.Builder
+		b.WriteString("username,email,password\n")
+		for i := 0; i < 10; i++ {
+			fmt.Fprintf(&b, "%s%d,%s%d@email.com,secret\n", prefix, i, prefix, i)
+		}
+		return b.String()
+	}
+
+	// imports of 10 rows take most of second, so the limit is reached before the first of them is done
+	accepted := 0
+	var w *httptest.ResponseRecorder
+	for i := 0; i < 10; i++ {
+		w = serve(body(fmt.Sprintf("user%d_", i)))
+		if w.Code != http.StatusAccepted {
+			break
+		}
+		accepted++
+	}
+	assert.Equal(t1, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t1, w.Body.String(), service.ErrTooManyImports.Error())
+	assert.NotZero(t1, accepted)
+
+	server.service.(*service.Service).WaitImports()
+	w = serve(body("late"))
+	assert.Equal(t1, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t1, w.Body.String(), service.ErrImportsStopped.Error())
+
+	req := httptest.NewRequest("GET", "/user?limit=200", nil)
+	req.SetBasicAuth("username", "password")
+	w = httptest.NewRecorder()
+	server.httpServer.Handler.ServeHTTP(w, req)
+	var page models.PageUsers
+	require.NoError(t1, json.NewDecoder(w.Body).Decode(&page))
+	assert.Len(t1, page.Users, len(testUsers)+1+accepted*10, "WaitImports returns after running imports are done")
+}
+
+func TestServer_userImportShutdown(t1 *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	server := prepareServerWithContext(ctx, serverCfg, serviceCfg)
+	serve := func(method, url, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "text/csv")
+		req.SetBasicAuth("username", "password")
+		w := httptest.NewRecorder()
+		server.httpServer.Handler.ServeHTTP(w, req)
+		return w
+	}
+
+	var body strings.Builder
+	body.WriteString("username,email,password\n")
+	for i := 0; i < 20; i++ {
+		fmt.Fprintf(&body, "user%d,user%d@email.com,secret\n", i, i)
+	}
+	w := serve("POST", "/user/import?async=true", body.String())
+	require.Equal(t1, http.StatusAccepted, w.Code)
+	var job models.ImportJob
+	require.NoError(t1, json.NewDecoder(w.Body).Decode(&job))
+
+	cancel()
+	server.service.(*service.Service).WaitImports()
+
+	w = serve("GET", "/user/import/"+job.ID, "")
+	require.Equal(t1, http.StatusOK, w.Code)
+	require.NoError(t1, json.NewDecoder(w.Body).Decode(&job))
+	assert.Equal(t1, models.ImportDone, job.Status)
+	assert.Contains(t1, job.Error, context.Canceled.Error())
+	assert.Less(t1, job.Processed, job.Total)
+
+	assert.Equal(t1, http.StatusServiceUnavailable, serve("POST", "/user/import?async=true", body.String()).Code)
+}
+
+func TestImportSyncRows(t1 *testing.T) {
+	tests := []struct {
+		name           string
+		requestTimeout time.Duration
+		want           int
+	}{
+		{name: "no timeout", requestTimeout: 0, want: maxImportSyncRows},
+		{name: "long timeout", requestTimeout: time.Minute, want: maxImportSyncRows},
+		{name: "short timeout", requestTimeout: time.Second, want: 5},
+		{name: "timeout shorter than one row", requestTimeout: 100 * time.Millisecond, want: 0},
+	}
+
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			assert.Equal(t1, tt.want, importSyncRows(tt.requestTimeout))
+		})
+	}
+}
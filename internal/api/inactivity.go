@@ -0,0 +1,41 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/KseniiaSalmina/Profiles/internal/validation"
+)
+
+// @Summary Inactivity report
+// @Security BasicAuth
+// @Tags admin
+// @Description dry run of inactivity policy, return users of organization who would be warned or disabled on the next check
+// @Return json
+// @Produce json,application/yaml,application/msgpack,text/csv
+// @Success 200 {array} models.InactivityAction
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Router /v1/inactivity/report [get]
+func (s *Server) getInactivityReport(w http.ResponseWriter, r *http.Request) {
+	caller, err := s.authorization(r)
+	if err != nil {
+		s.log(r).WithError(err).Info("get inactivity report handler, failed authorization")
+		http.Error(w, err.Error(), authErrorStatus(w, err))
+		return
+	}
+
+	if !caller.Admin {
+		s.log(r).Info("get inactivity report handler, user is not admin")
+		http.Error(w, validation.ErrIsNotAdmin.Error(), http.StatusForbidden)
+		return
+	}
+
+	report, err := s.service.InactivityReport(r.Context(), organizationFromContext(r.Context()))
+	if err != nil {
+		s.log(r).WithError(err).Info("get inactivity report handler, failed to get report")
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	s.respondList(w, r, http.StatusOK, report, report)
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KseniiaSalmina/Profiles/internal/api/models"
+	"github.com/KseniiaSalmina/Profiles/internal/config"
+	"github.com/KseniiaSalmina/Profiles/internal/service"
+)
+
+func TestServer_inactivity(t1 *testing.T) {
+	cfg := serviceCfg
+	cfg.Inactivity = config.Inactivity{
+		InactivityWarnAfterDays:    30,
+		InactivityDisableAfterDays: 90,
+		InactivityExemptAdmins:     true,
+		InactivityAllowlist:        []string{"testUser2"},
+	}
+	server := prepareServerWithConfig(cfg)
+	svc := server.service.(*service.Service)
+	clock := &testClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	svc.SetClock(clock)
+
+	serve := func(method, url, body, username, password string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.SetBasicAuth(username, password)
+		w := httptest.NewRecorder()
+		server.httpServer.Handler.ServeHTTP(w, req)
+		return w
+	}
+
+	report := func() map[string]string {
+		w := serve("GET", "/inactivity/report", "", "username", "password")
+		assert.Equal(t1, http.StatusOK, w.Code)
+		var report []models.InactivityAction
+		assert.NoError(t1, json.NewDecoder(w.Body).Decode(&report))
+
+		actions := make(map[string]string)
+		for _, action := range report {
+			actions[action.Username] = action.Action
+		}
+		return actions
+	}
+
+	w := serve("POST", "/user", `{"email":"new@email.com","username":"newUser","password":"new"}`, "username", "password")
+	assert.Equal(t1, http.StatusOK, w.Code)
+
+	w = serve("GET", "/inactivity/report", "", "testUser3", "password")
+	assert.Equal(t1, http.StatusForbidden, w.Code)
+
+	clock.now = clock.now.Add(40 * 24 * time.Hour)
+	assert.Equal(t1, map[string]string{
+		"testUser":  models.InactivityDisable,
+		"testUser3": models.InactivityWarn,
+		"newUser":   models.InactivityWarn,
+	}, report())
+
+	applied, err := svc.ApplyInactivityPolicy(context.Background())
+	assert.NoError(t1, err)
+	assert.Len(t1, applied, 3)
+	assert.Empty(t1, report())
+
+	clock.now = clock.now.Add(60 * 24 * time.Hour)
+	assert.Equal(t1, map[string]string{
+		"testUser3": models.InactivityDisable,
+		"newUser":   models.InactivityDisable,
+	}, report())
+
+	_, err = svc.ApplyInactivityPolicy(context.Background())
+	assert.NoError(t1, err)
+
+	w = serve("GET", "/user", "", "testUser3", "password")
+	assert.Equal(t1, http.StatusUnauthorized, w.Code)
+
+	w = serve("PATCH", "/user/db783cb2-8037-4b75-8c01-ab9065e568e3", `{"disabled":false}`, "username", "password")
+	assert.Equal(t1, http.StatusOK, w.Code)
+
+	w = serve("GET", "/user", "", "testUser3", "password")
+	assert.Equal(t1, http.StatusOK, w.Code)
+}
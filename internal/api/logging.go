@@ -1,17 +1,205 @@
 package api
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
+	"github.com/uptrace/bunrouter"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
-func (s *Server) logging(statusCode *int, r *http.Request) {
-	s.logger.WithFields(logrus.Fields{
-		"request_time":   time.Now().Format("2006-01-02 15:04:05"),
-		"request_method": r.Method,
-		"request_url":    r.URL,
-		"responce_code":  *statusCode,
-	}).Debug("http request served")
+const (
+	requestIDHeader    = "X-Request-ID"
+	forwardedForHeader = "X-Forwarded-For"
+	maxRequestIDLength = 128
+)
+
+var (
+	ErrIncorrectTrustedProxy = errors.New("incorrect trusted proxy address")
+	ErrNegativeTimeout       = errors.New("timeout can't be negative")
+)
+
+type requestInfoKey struct{}
+
+// requestInfo is filled by router and authorization while request is served, it is logged when request is done.
+type requestInfo struct {
+	id       string
+	route    string
+	username string
+}
+
+// logging assigns request id, propagating one sent by client, and logs one line per served request.
+func (s *Server) logging(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		id := r.Header.Get(requestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+		w.Header().Set(requestIDHeader, id)
+
+		span := trace.SpanFromContext(r.Context())
+		span.SetAttributes(attribute.String("request.id", id))
+
+		info := &requestInfo{id: id}
+		rec := &statusRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+		next.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), requestInfoKey{}, info)))
+
+		fields := logrus.Fields{
+			"request_id":    id,
+			"method":        r.Method,
+			"path":          r.URL.Path,
+			"route":         info.route,
+			"response_code": rec.statusCode,
+			"duration":      time.Since(start).String(),
+			"bytes":         rec.bytes,
+			"client_ip":     s.clientIP(r),
+			"username":      info.username,
+		}
+		if span.SpanContext().HasTraceID() {
+			fields["trace_id"] = span.SpanContext().TraceID().String()
+		}
+
+		s.logger.WithFields(fields).Info("http request served")
+	})
+}
+
+// route saves matched route template, it is unknown until router matches request.
+func (s *Server) route(next bunrouter.HandlerFunc) bunrouter.HandlerFunc {
+	return func(w http.ResponseWriter, req bunrouter.Request) error {
+		if info := requestInfoFromContext(req.Context()); info != nil {
+			info.route = req.Route()
+		}
+
+		span := trace.SpanFromContext(req.Context())
+		span.SetName(req.Method + " " + req.Route())
+		span.SetAttributes(semconv.HTTPRoute(req.Route()))
+
+		return next(w, req)
+	}
+}
+
+// log returns logger entry carrying request id, every handler log line should be written through it.
+func (s *Server) log(r *http.Request) *logrus.Entry {
+	entry := s.logger.WithContext(r.Context())
+	if info := requestInfoFromContext(r.Context()); info != nil {
+		entry = entry.WithField("request_id", info.id)
+	}
+
+	return entry
+}
+
+func requestInfoFromContext(ctx context.Context) *requestInfo {
+	info, _ := ctx.Value(requestInfoKey{}).(*requestInfo)
+	return info
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for _, c := range id {
+		if c < '!' || c > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// clientIP returns address of the client, X-Forwarded-For is trusted only when request came through trusted proxies.
+func (s *Server) clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	if !s.trustedProxy(host) {
+		return host
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values(forwardedForHeader), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+		host = hop
+		if !s.trustedProxy(hop) {
+			break
+		}
+	}
+
+	return host
+}
+
+func (s *Server) trustedProxy(host string) bool {
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+
+	for _, network := range s.settings.Load().trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseTrustedProxies accepts both single addresses and CIDR networks.
+func parseTrustedProxies(proxies []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if !strings.Contains(proxy, "/") {
+			if ip := net.ParseIP(proxy); ip != nil && ip.To4() != nil {
+				proxy += "/32"
+			} else {
+				proxy += "/128"
+			}
+		}
+
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrIncorrectTrustedProxy, proxy)
+		}
+		networks = append(networks, network)
+	}
+
+	return networks, nil
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	statusCode int
+	bytes      int
+}
+
+func (r *statusRecorder) WriteHeader(statusCode int) {
+	r.statusCode = statusCode
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Unwrap lets http.ResponseController reach the original writer.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += n
+	return n, err
 }
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_logging(t1 *testing.T) {
+	server := prepareServer()
+
+	var buf bytes.Buffer
+	server.logger.SetOutput(&buf)
+	server.logger.SetFormatter(&logrus.JSONFormatter{})
+
+	cfg := serverCfg
+	cfg.TrustedProxies = []string{"10.0.0.0/8", "192.168.1.1"}
+	apply, err := server.Reconfigure(cfg)
+	assert.NoError(t1, err)
+	apply()
+
+	serve := func(url, requestID, remoteAddr, forwardedFor string) (*httptest.ResponseRecorder, []map[string]any) {
+		buf.Reset()
+		req := httptest.NewRequest("GET", url, nil)
+		req.SetBasicAuth("testUser3", "password")
+		req.RemoteAddr = remoteAddr
+		if requestID != "" {
+			req.Header.Set(requestIDHeader, requestID)
+		}
+		if forwardedFor != "" {
+			req.Header.Set(forwardedForHeader, forwardedFor)
+		}
+		w := httptest.NewRecorder()
+		server.httpServer.Handler.ServeHTTP(w, req)
+
+		var lines []map[string]any
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			fields := make(map[string]any)
+			assert.NoError(t1, json.Unmarshal([]byte(line), &fields))
+			lines = append(lines, fields)
+		}
+		return w, lines
+	}
+
+	w, lines := serve("/user/"+testUsers[2].ID, "client-id-1", "10.1.2.3:4000", "203.0.113.7, 192.168.1.1")
+	assert.Equal(t1, http.StatusOK, w.Code)
+	assert.Equal(t1, "client-id-1", w.Header().Get(requestIDHeader))
+
+	access := lines[len(lines)-1]
+	assert.Equal(t1, "info", access["level"])
+	assert.Equal(t1, "client-id-1", access["request_id"])
+	assert.Equal(t1, "/user/:id", access["route"])
+	assert.Equal(t1, "testUser3", access["username"])
+	assert.Equal(t1, "203.0.113.7", access["client_ip"])
+	assert.Equal(t1, float64(http.StatusOK), access["response_code"])
+	assert.Equal(t1, float64(w.Body.Len()), access["bytes"])
+	assert.NotEmpty(t1, access["duration"])
+
+	w, lines = serve("/user/"+testUsers[2].ID, "bad id", "203.0.113.9:4000", "198.51.100.1")
+	assert.Equal(t1, http.StatusOK, w.Code)
+	assert.NotEqual(t1, "bad id", w.Header().Get(requestIDHeader))
+	assert.Equal(t1, w.Header().Get(requestIDHeader), lines[len(lines)-1]["request_id"])
+	assert.Equal(t1, "203.0.113.9", lines[len(lines)-1]["client_ip"])
+
+	w, lines = serve("/user/wrong", "client-id-2", "10.1.2.3:4000", "")
+	assert.Equal(t1, http.StatusBadRequest, w.Code)
+	for _, line := range lines {
+		assert.Equal(t1, "client-id-2", line["request_id"])
+	}
+	assert.Len(t1, lines, 2)
+	assert.Equal(t1, "10.1.2.3", lines[1]["client_ip"])
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/uptrace/bunrouter"
+
+	"github.com/KseniiaSalmina/Profiles/internal/validation"
+)
+
+const metricsPath = "/metrics"
+
+// instrument records count and latency of requests labeled with route template, so ids in path do not produce new series.
+func (s *Server) instrument(next bunrouter.HandlerFunc) bunrouter.HandlerFunc {
+	return func(w http.ResponseWriter, req bunrouter.Request) error {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+
+		err := next(rec, req)
+
+		s.metrics.ObserveRequest(req.Route(), req.Method, rec.statusCode, time.Since(start))
+		return err
+	}
+}
+
+// @Summary Metrics
+// @Security BasicAuth
+// @Tags admin
+// @Description return metrics in Prometheus text format to super admin, served without authorization on SERVER_METRICS_LISTEN if it is set
+// @Produce plain
+// @Success 200 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Router /metrics [get]
+func (s *Server) getMetrics(w http.ResponseWriter, r *http.Request) {
+	caller, err := s.authorization(r)
+	if err != nil {
+		s.log(r).WithError(err).Info("get metrics handler, failed authorization")
+		http.Error(w, err.Error(), authErrorStatus(w, err))
+		return
+	}
+
+	// metrics cover all organizations, so admins of one organization can't read them
+	if !caller.SuperAdmin {
+		s.log(r).Info("get metrics handler, user is not super admin")
+		http.Error(w, validation.ErrIsNotSuperAdmin.Error(), http.StatusForbidden)
+		return
+	}
+
+	s.metrics.Handler().ServeHTTP(w, r)
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_metrics(t1 *testing.T) {
+	server := prepareServer()
+
+	serve := func(method, url, username, password string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, nil)
+		if username != "" {
+			req.SetBasicAuth(username, password)
+		}
+		w := httptest.NewRecorder()
+		server.httpServer.Handler.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t1, http.StatusOK, serve("GET", "/user/"+testUsers[2].ID, "testUser3", "password").Code)
+	assert.Equal(t1, http.StatusUnauthorized, serve("GET", "/user", "testUser3", "wrong").Code)
+	assert.Equal(t1, http.StatusUnauthorized, serve("GET", "/user", "nobody", "password").Code)
+	assert.Equal(t1, http.StatusUnauthorized, serve("GET", "/user", "", "").Code)
+
+	w := serve("GET", "/metrics", "username", "password")
+	assert.Equal(t1, http.StatusOK, w.Code)
+
+	body := w.Body.String()
+	for _, line := range []string{
+		`profiles_http_requests_total{method="GET",route="/user/:id",status="200"} 1`,
+		`profiles_http_requests_total{method="GET",route="/user",status="401"} 3`,
+		`profiles_auth_attempts_total{reason="",result="success"} 2`,
+		`profiles_auth_attempts_total{reason="wrong_password",result="failure"} 1`,
+		`profiles_auth_attempts_total{reason="unknown_user",result="failure"} 1`,
+		`profiles_auth_attempts_total{reason="no_credentials",result="failure"} 1`,
+		`profiles_auth_bcrypt_duration_seconds_count 3`,
+		`profiles_storage_operation_duration_seconds_count{operation="GetUserByID"} 1`,
+		`profiles_users 4`,
+	} {
+		assert.Contains(t1, body, line)
+	}
+
+	assert.Equal(t1, http.StatusUnauthorized, serve("GET", "/metrics", "", "").Code)
+	assert.Equal(t1, http.StatusForbidden, serve("GET", "/metrics", "testUser3", "password").Code)
+}
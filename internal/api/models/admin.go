@@ -0,0 +1,5 @@
+package models
+
+type LogLevel struct {
+	Level string `json:"level"` // panic, fatal, error, warn, info, debug or trace
+}
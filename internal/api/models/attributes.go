@@ -0,0 +1,14 @@
+package models
+
+type AttributeSchema struct {
+	Type       string                       `json:"type,omitempty"` // "object" or empty
+	Properties map[string]AttributeProperty `json:"properties"`
+	Required   []string                     `json:"required,omitempty"`
+}
+
+type AttributeProperty struct {
+	Type      string `json:"type"` // string, number, integer or boolean
+	Enum      []any  `json:"enum,omitempty"`
+	Pattern   string `json:"pattern,omitempty"`
+	MaxLength int    `json:"maxLength,omitempty"`
+}
@@ -0,0 +1,35 @@
+package models
+
+const (
+	BatchCreate = "create"
+	BatchPatch  = "patch"
+	BatchDelete = "delete"
+
+	BatchOK         = "ok"
+	BatchFailed     = "failed"
+	BatchRolledBack = "rolled_back" // succeeded, but batch was not committed
+	BatchSkipped    = "skipped"     // not run, because all-or-nothing batch failed earlier
+)
+
+type BatchRequest struct {
+	AllOrNothing *bool            `json:"all_or_nothing"` // true if not set
+	Operations   []BatchOperation `json:"operations"`
+}
+
+type BatchOperation struct {
+	Op   string     `json:"op"`           // create, patch or delete
+	ID   string     `json:"id,omitempty"` // user to patch or delete
+	User UserUpdate `json:"user"`         // profile of created user or changes of patched one
+}
+
+type BatchResponse struct {
+	Committed bool          `json:"committed"`
+	Results   []BatchResult `json:"results"`
+}
+
+type BatchResult struct {
+	Op     string `json:"op"`
+	ID     string `json:"id,omitempty"` // created, patched or deleted user
+	Status string `json:"status"`       // ok, failed, rolled_back or skipped
+	Error  string `json:"error,omitempty"`
+}
@@ -0,0 +1,20 @@
+package models
+
+type GroupAdd struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	ParentID    string `json:"parent_id"`
+}
+
+type GroupUpdate struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	ParentID    *string `json:"parent_id"` // empty string moves group to the top level
+}
+
+type GroupResponse struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	ParentID    string `json:"parent_id,omitempty"`
+}
@@ -0,0 +1,17 @@
+package models
+
+const (
+	HealthOK   = "ok"
+	HealthFail = "fail"
+)
+
+type Health struct {
+	Status     string                     `json:"status"` // ok or fail
+	Components map[string]ComponentHealth `json:"components"`
+}
+
+type ComponentHealth struct {
+	Status   string `json:"status"` // ok or fail
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
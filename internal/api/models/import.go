@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+const (
+	ImportAllOrNothing = "all_or_nothing"
+	ImportBestEffort   = "best_effort"
+
+	ImportRunning = "running"
+	ImportDone    = "done"
+)
+
+type ImportOptions struct {
+	Mode   string // all_or_nothing or best_effort
+	DryRun bool   // rows are only validated
+}
+
+// ImportRow is a parsed row of import file, row with error is not created.
+type ImportRow struct {
+	User UserAdd
+	Err  error
+}
+
+type ImportJob struct {
+	ID         string            `json:"id"`
+	Status     string            `json:"status"` // running or done
+	Mode       string            `json:"mode"`   // all_or_nothing or best_effort
+	DryRun     bool              `json:"dry_run"`
+	Total      int               `json:"total"`
+	Processed  int               `json:"processed"`
+	Succeeded  int               `json:"succeeded"` // created users or valid rows of dry run
+	Failed     int               `json:"failed"`
+	Error      string            `json:"error,omitempty"` // why nothing was created
+	StartedAt  time.Time         `json:"started_at"`
+	FinishedAt *time.Time        `json:"finished_at,omitempty"`
+	Rows       []ImportRowResult `json:"rows"`
+}
+
+type ImportRowResult struct {
+	Row      int    `json:"row"` // starts from 1, header of csv is not counted
+	Username string `json:"username"`
+	ID       string `json:"id,omitempty"` // id of created user
+	Error    string `json:"error,omitempty"`
+}
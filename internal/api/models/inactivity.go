@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+const (
+	InactivityWarn    = "warn"
+	InactivityDisable = "disable"
+)
+
+type InactivityAction struct {
+	UserID         string     `json:"user_id"`
+	Username       string     `json:"username"`
+	Email          string     `json:"email"`
+	Action         string     `json:"action"` // warn or disable
+	LastActivityAt time.Time  `json:"last_activity_at"`
+	InactiveDays   int        `json:"inactive_days"`
+	DisableAt      *time.Time `json:"disable_at,omitempty"` // for warnings when disabling is enabled
+}
@@ -0,0 +1,14 @@
+package models
+
+type OrganizationAdd struct {
+	Name string `json:"name"`
+}
+
+type OrganizationUpdate struct {
+	Name string `json:"name"`
+}
+
+type OrganizationResponse struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
@@ -1,15 +1,47 @@
 package models
 
+import "time"
+
 type UserAdd struct {
-	Email    string `json:"email"`
-	Username string `json:"username"`
-	Password string `json:"password"`
-	Admin    bool   `json:"admin"`
+	Email      string         `json:"email"`
+	Username   string         `json:"username"`
+	Password   string         `json:"password"`
+	Admin      bool           `json:"admin"`
+	SuperAdmin bool           `json:"super_admin"` // only in default organization, implies admin
+	Attributes map[string]any `json:"attributes,omitempty"`
 }
 
 type UserUpdate struct {
-	Email    *string `json:"email"`
-	Username *string `json:"username"`
-	Password *string `json:"password"`
-	Admin    *bool   `json:"admin"`
+	Email      *string        `json:"email"`
+	Username   *string        `json:"username"`
+	Password   *string        `json:"password"`
+	Admin      *bool          `json:"admin"`
+	SuperAdmin *bool          `json:"super_admin"`
+	Disabled   *bool          `json:"disabled"`
+	Attributes map[string]any `json:"attributes,omitempty"` // merged into existing attributes, null value removes attribute
+}
+
+// UserReplace is the whole editable profile, it is the body of PUT /user/:id and the document patched by PATCH /user/:id.
+type UserReplace struct {
+	Email      string         `json:"email"`
+	Username   string         `json:"username"`
+	Password   *string        `json:"password,omitempty"` // current password is kept if not set
+	Admin      bool           `json:"admin"`
+	SuperAdmin bool           `json:"super_admin"`
+	Disabled   bool           `json:"disabled"`
+	Attributes map[string]any `json:"attributes"` // replaces all attributes
+}
+
+type UserFilter struct {
+	Attributes map[string]string
+
+	CreatedAfter    time.Time
+	CreatedBefore   time.Time
+	UpdatedAfter    time.Time
+	UpdatedBefore   time.Time
+	LastLoginAfter  time.Time
+	LastLoginBefore time.Time
+
+	SortBy   string
+	SortDesc bool
 }
@@ -1,10 +1,19 @@
 package models
 
+import "time"
+
 type UserResponse struct {
-	ID       string `json:"id"`
-	Email    string `json:"email"`
-	Username string `json:"username"`
-	Admin    bool   `json:"admin"`
+	ID         string         `json:"id"`
+	Email      string         `json:"email"`
+	Username   string         `json:"username"`
+	Admin      bool           `json:"admin"`
+	Disabled   bool           `json:"disabled"`
+	Attributes map[string]any `json:"attributes,omitempty"`
+
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+	LastLoginAt       *time.Time `json:"last_login_at,omitempty"`
+	PasswordChangedAt time.Time  `json:"password_changed_at"`
 }
 
 type PageUsers struct {
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+type SessionAdd struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type SessionResponse struct {
+	Username  string    `json:"username"`
+	CSRFToken string    `json:"csrf_token"` // required in X-CSRF-Token header of POST, PUT, PATCH and DELETE requests
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -0,0 +1,427 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	mediaJSON    = "application/json"
+	mediaYAML    = "application/yaml"
+	mediaMsgPack = "application/msgpack"
+	mediaCSV     = "text/csv"
+)
+
+var (
+	ErrNotAcceptable        = errors.New("response can be application/json, application/yaml, application/msgpack or text/csv for lists")
+	ErrUnsupportedMediaType = errors.New("request body should be application/json, application/yaml or application/msgpack")
+)
+
+// mediaAliases are other names of supported types used by clients.
+var mediaAliases = map[string]string{
+	"application/x-yaml":      mediaYAML,
+	"text/yaml":               mediaYAML,
+	"application/x-msgpack":   mediaMsgPack,
+	"application/vnd.msgpack": mediaMsgPack,
+}
+
+// Offers are in order of preference, the first one is used if client accepts any type.
+var (
+	responseFormats = []string{mediaJSON, mediaYAML, mediaMsgPack}
+	listFormats     = []string{mediaJSON, mediaYAML, mediaMsgPack, mediaCSV}
+)
+
+type formatKey struct{}
+
+// negotiated chooses format of response by Accept header before handler runs, so request whose response
+// can't be accepted changes nothing.
+func (s *Server) negotiated(next http.HandlerFunc) http.HandlerFunc {
+	return s.negotiate(next, responseFormats)
+}
+
+// negotiatedList also offers CSV, handler should respond with respondList.
+func (s *Server) negotiatedList(next http.HandlerFunc) http.HandlerFunc {
+	return s.negotiate(next, listFormats)
+}
+
+func (s *Server) negotiate(next http.HandlerFunc, offers []string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept")
+
+		format, ok := acceptedFormat(r.Header.Get("Accept"), offers)
+		if !ok {
+			s.log(r).WithField("accept", r.Header.Get("Accept")).Info("content negotiation, no acceptable format")
+			http.Error(w, ErrNotAcceptable.Error(), http.StatusNotAcceptable)
+			return
+		}
+
+		next(w, r.WithContext(context.WithValue(r.Context(), formatKey{}, format)))
+	}
+}
+
+// acceptedFormat returns offer with the highest quality in Accept header, the most specific media range sets quality of offer.
+func acceptedFormat(accept string, offers []string) (string, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return offers[0], true
+	}
+
+	type mediaRange struct {
+		mediaType string
+		quality   float64
+	}
+	ranges := make([]mediaRange, 0)
+	for _, value := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(value)
+		if err != nil {
+			continue
+		}
+		if alias, ok := mediaAliases[mediaType]; ok {
+			mediaType = alias
+		}
+
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		ranges = append(ranges, mediaRange{mediaType: mediaType, quality: quality})
+	}
+
+	best, bestQuality := "", 0.0
+	for _, offer := range offers {
+		quality, specificity := 0.0, -1
+		for _, rng := range ranges {
+			var matched int
+			switch {
+			case rng.mediaType == offer:
+				matched = 2
+			case strings.HasSuffix(rng.mediaType, "/*") && strings.HasPrefix(offer, strings.TrimSuffix(rng.mediaType, "*")):
+				matched = 1
+			case rng.mediaType == "*/*":
+				matched = 0
+			default:
+				continue
+			}
+			if matched > specificity {
+				quality, specificity = rng.quality, matched
+			}
+		}
+		if quality > bestQuality {
+			best, bestQuality = offer, quality
+		}
+	}
+
+	return best, best != ""
+}
+
+// respond writes value in format chosen by negotiated, handlers which are not negotiated respond with JSON.
+func (s *Server) respond(w http.ResponseWriter, r *http.Request, statusCode int, value any) {
+	s.respondList(w, r, statusCode, value, nil)
+}
+
+// respondList writes rows instead of value if CSV is chosen, e.g. users of a page.
+func (s *Server) respondList(w http.ResponseWriter, r *http.Request, statusCode int, value, rows any) {
+	format, _ := r.Context().Value(formatKey{}).(string)
+	if format == "" {
+		format = mediaJSON
+	}
+	if format == mediaCSV {
+		value = rows
+	}
+	if view := versionFromContext(r.Context()).view; view != nil {
+		value = view(value)
+	}
+
+	body, err := encodeResponse(format, value)
+	if err != nil {
+		s.log(r).WithError(err).Warn("content negotiation, failed to encode response")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	contentType := format
+	if format == mediaCSV {
+		contentType += "; charset=utf-8"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(statusCode)
+	_, _ = w.Write(body)
+}
+
+// decodeBody decodes request body by its Content-Type, body without type is JSON.
+// Field names and types of every format are the same as of JSON.
+func decodeBody(r *http.Request, v any) error {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if alias, ok := mediaAliases[mediaType]; ok {
+		mediaType = alias
+	}
+
+	var doc any
+	switch mediaType {
+	case "", mediaJSON:
+		return json.NewDecoder(r.Body).Decode(v)
+	case mediaYAML:
+		if err := yaml.NewDecoder(r.Body).Decode(&doc); err != nil {
+			return err
+		}
+	case mediaMsgPack:
+		if err := msgpack.NewDecoder(r.Body).Decode(&doc); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("%w, got %q", ErrUnsupportedMediaType, mediaType)
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// decodeErrorStatus keeps status of malformed body, unsupported type is reported with 415.
+func decodeErrorStatus(err error) int {
+	if errors.Is(err, ErrUnsupportedMediaType) {
+		return http.StatusUnsupportedMediaType
+	}
+
+	return http.StatusInternalServerError
+}
+
+func encodeResponse(format string, value any) ([]byte, error) {
+	var buf bytes.Buffer
+	if format == mediaJSON {
+		err := json.NewEncoder(&buf).Encode(value)
+		return buf.Bytes(), err
+	}
+
+	// other formats are built from JSON of value, so they have the same field names, order and values
+	doc, err := orderedJSON(value)
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case mediaYAML:
+		return yaml.Marshal(yamlNode(doc))
+	case mediaMsgPack:
+		encoder := msgpack.NewEncoder(&buf)
+		encoder.SetSortMapKeys(true)
+		err = encoder.Encode(msgpackValue(doc))
+		return buf.Bytes(), err
+	case mediaCSV:
+		err = writeCSV(&buf, doc)
+		return buf.Bytes(), err
+	default:
+		return nil, fmt.Errorf("%w, got %q", ErrNotAcceptable, format)
+	}
+}
+
+type jsonMember struct {
+	key   string
+	value any
+}
+
+// jsonObject keeps order of fields, other values are []any, string, json.Number, bool or nil.
+type jsonObject []jsonMember
+
+func orderedJSON(value any) (any, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return readOrdered(decoder)
+}
+
+func readOrdered(decoder *json.Decoder) (any, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	delim, ok := token.(json.Delim)
+	if !ok {
+		return token, nil
+	}
+
+	if delim == '{' {
+		object := make(jsonObject, 0)
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := readOrdered(decoder)
+			if err != nil {
+				return nil, err
+			}
+			object = append(object, jsonMember{key: key.(string), value: value})
+		}
+		_, err = decoder.Token()
+		return object, err
+	}
+
+	array := make([]any, 0)
+	for decoder.More() {
+		value, err := readOrdered(decoder)
+		if err != nil {
+			return nil, err
+		}
+		array = append(array, value)
+	}
+	_, err = decoder.Token()
+	return array, err
+}
+
+func yamlNode(value any) *yaml.Node {
+	switch value := value.(type) {
+	case jsonObject:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		for _, member := range value {
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: member.key}, yamlNode(member.value))
+		}
+		return node
+	case []any:
+		node := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, item := range value {
+			node.Content = append(node.Content, yamlNode(item))
+		}
+		return node
+	case string:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(value.String(), ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value.String()}
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(value)}
+	default:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+	}
+}
+
+// msgpackValue keeps integers as integers, JSON has only one number type.
+func msgpackValue(value any) any {
+	switch value := value.(type) {
+	case jsonObject:
+		object := make(map[string]any, len(value))
+		for _, member := range value {
+			object[member.key] = msgpackValue(member.value)
+		}
+		return object
+	case []any:
+		array := make([]any, 0, len(value))
+		for _, item := range value {
+			array = append(array, msgpackValue(item))
+		}
+		return array
+	case json.Number:
+		if i, err := value.Int64(); err == nil {
+			return i
+		}
+		f, _ := value.Float64()
+		return f
+	default:
+		return value
+	}
+}
+
+// writeCSV writes row per object of list, nested objects are flattened into "parent.field" columns.
+// Columns are in order of their first appearance.
+func writeCSV(buf *bytes.Buffer, doc any) error {
+	items, _ := doc.([]any)
+
+	columns := make([]string, 0)
+	rows := make([]map[string]string, 0, len(items))
+	for _, item := range items {
+		row := make(map[string]string)
+		flattenCSV("", item, row, &columns)
+		rows = append(rows, row)
+	}
+
+	if len(rows) == 0 {
+		return nil
+	}
+
+	writer := csv.NewWriter(buf)
+	if err := writer.Write(columns); err != nil {
+		return err
+	}
+	record := make([]string, len(columns))
+	for _, row := range rows {
+		for i, column := range columns {
+			record[i] = row[column]
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+
+	return writer.Error()
+}
+
+func flattenCSV(prefix string, value any, row map[string]string, columns *[]string) {
+	if object, ok := value.(jsonObject); ok {
+		for _, member := range object {
+			flattenCSV(prefix+member.key+".", member.value, row, columns)
+		}
+		return
+	}
+
+	column := strings.TrimSuffix(prefix, ".")
+	if !slices.Contains(*columns, column) {
+		*columns = append(*columns, column)
+	}
+
+	switch value := value.(type) {
+	case string:
+		row[column] = csvText(value)
+	case json.Number:
+		row[column] = value.String()
+	case bool:
+		row[column] = strconv.FormatBool(value)
+	case []any:
+		data, _ := json.Marshal(plainJSON(value))
+		row[column] = string(data)
+	}
+}
+
+// plainJSON converts ordered values back to values which encoding/json marshals.
+func plainJSON(value any) any {
+	switch value := value.(type) {
+	case jsonObject:
+		object := make(map[string]any, len(value))
+		for _, member := range value {
+			object[member.key] = plainJSON(member.value)
+		}
+		return object
+	case []any:
+		array := make([]any, 0, len(value))
+		for _, item := range value {
+			array = append(array, plainJSON(item))
+		}
+		return array
+	default:
+		return value
+	}
+}
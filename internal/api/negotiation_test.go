@@ -0,0 +1,149 @@
+package api
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+	"gopkg.in/yaml.v3"
+)
+
+func TestAcceptedFormat(t1 *testing.T) {
+	tests := []struct {
+		name   string
+		accept string
+		offers []string
+		want   string
+	}{
+		{name: "no header", accept: "", offers: listFormats, want: mediaJSON},
+		{name: "any type", accept: "*/*", offers: listFormats, want: mediaJSON},
+		{name: "exact type", accept: "application/yaml", offers: listFormats, want: mediaYAML},
+		{name: "alias", accept: "application/x-msgpack", offers: listFormats, want: mediaMsgPack},
+		{name: "quality", accept: "application/json;q=0.5, text/csv", offers: listFormats, want: mediaCSV},
+		{name: "specific range overrides wildcard", accept: "application/*;q=0.8, application/json;q=0.1", offers: listFormats, want: mediaYAML},
+		{name: "refused type", accept: "application/json;q=0, */*;q=0.5", offers: responseFormats, want: mediaYAML},
+		{name: "csv of single value", accept: "text/csv", offers: responseFormats, want: ""},
+		{name: "unsupported type", accept: "text/html", offers: listFormats, want: ""},
+	}
+
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			format, ok := acceptedFormat(tt.accept, tt.offers)
+			assert.Equal(t1, tt.want, format)
+			assert.Equal(t1, tt.want != "", ok)
+		})
+	}
+}
+
+func TestServer_negotiation(t1 *testing.T) {
+	server := prepareServer()
+	serve := func(method, url, contentType, accept string, body []byte) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, bytes.NewReader(body))
+		req.SetBasicAuth("username", "password")
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		w := httptest.NewRecorder()
+		server.httpServer.Handler.ServeHTTP(w, req)
+		return w
+	}
+
+	t1.Run("yaml user", func(t1 *testing.T) {
+		w := serve("GET", "/user/"+testUsers[2].ID, "", "application/yaml", nil)
+		require.Equal(t1, http.StatusOK, w.Code)
+		assert.Equal(t1, mediaYAML, w.Header().Get("Content-Type"))
+		assert.True(t1, strings.HasPrefix(w.Body.String(), "id: "+testUsers[2].ID+"\n"))
+
+		var user map[string]any
+		require.NoError(t1, yaml.Unmarshal(w.Body.Bytes(), &user))
+		assert.Equal(t1, "test3@email.com", user["email"])
+		assert.Equal(t1, false, user["admin"])
+	})
+
+	t1.Run("msgpack page", func(t1 *testing.T) {
+		w := serve("GET", "/user", "", "application/vnd.msgpack", nil)
+		require.Equal(t1, http.StatusOK, w.Code)
+		assert.Equal(t1, mediaMsgPack, w.Header().Get("Content-Type"))
+
+		var page struct {
+			Users []struct {
+				Username string `msgpack:"username"`
+			} `msgpack:"users"`
+			Limit int `msgpack:"limit"`
+		}
+		require.NoError(t1, msgpack.Unmarshal(w.Body.Bytes(), &page))
+		assert.Equal(t1, 30, page.Limit)
+		assert.NotEmpty(t1, page.Users)
+	})
+
+	t1.Run("csv page", func(t1 *testing.T) {
+		w := serve("GET", "/user?sort=username", "", "text/csv", nil)
+		require.Equal(t1, http.StatusOK, w.Code)
+		assert.Equal(t1, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+
+		records, err := csv.NewReader(w.Body).ReadAll()
+		require.NoError(t1, err)
+		require.Len(t1, records, 5)
+		assert.Equal(t1, []string{"id", "email", "username", "admin"}, records[0][:4])
+		assert.Equal(t1, []string{testUsers[2].ID, "test3@email.com", "testUser3", "false"}, records[3][:4])
+	})
+
+	t1.Run("csv formulas are escaped", func(t1 *testing.T) {
+		w := serve("POST", "/group", "application/json", "", []byte(`{"name": "=HYPERLINK(\"http://evil.com\")", "description": "+1"}`))
+		require.Equal(t1, http.StatusOK, w.Code)
+
+		w = serve("GET", "/group", "", "text/csv", nil)
+		require.Equal(t1, http.StatusOK, w.Code)
+		assert.Contains(t1, w.Body.String(), `'=HYPERLINK(""http://evil.com"")`)
+		assert.Contains(t1, w.Body.String(), `,'+1`)
+	})
+
+	t1.Run("yaml body", func(t1 *testing.T) {
+		body := "username: yaml\npassword: password\nemail: yaml@email.com\n"
+		w := serve("POST", "/user", "application/x-yaml", "", []byte(body))
+		require.Equal(t1, http.StatusOK, w.Code)
+		assert.Equal(t1, mediaJSON, w.Header().Get("Content-Type"))
+		var id string
+		require.NoError(t1, json.NewDecoder(w.Body).Decode(&id))
+		assert.NotEmpty(t1, id)
+	})
+
+	t1.Run("msgpack body", func(t1 *testing.T) {
+		body, err := msgpack.Marshal(map[string]any{"username": "msgpack", "password": "password", "email": "msgpack@email.com"})
+		require.NoError(t1, err)
+		w := serve("POST", "/user", "application/msgpack", "application/msgpack", body)
+		require.Equal(t1, http.StatusOK, w.Code)
+		var id string
+		require.NoError(t1, msgpack.Unmarshal(w.Body.Bytes(), &id))
+		assert.NotEmpty(t1, id)
+	})
+
+	for _, tt := range []struct {
+		name        string
+		method      string
+		url         string
+		contentType string
+		accept      string
+		body        string
+		wantCode    int
+	}{
+		{name: "csv of single user", method: "GET", url: "/user/" + testUsers[2].ID, accept: "text/csv", wantCode: http.StatusNotAcceptable},
+		{name: "unsupported response", method: "GET", url: "/group", accept: "text/html", wantCode: http.StatusNotAcceptable},
+		{name: "unsupported body", method: "POST", url: "/group", contentType: "text/plain", body: "name", wantCode: http.StatusUnsupportedMediaType},
+		{name: "malformed yaml body", method: "POST", url: "/group", contentType: "application/yaml", body: "name: [", wantCode: http.StatusInternalServerError},
+	} {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			assert.Equal(t1, tt.wantCode, serve(tt.method, tt.url, tt.contentType, tt.accept, []byte(tt.body)).Code)
+		})
+	}
+}
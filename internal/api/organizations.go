@@ -0,0 +1,249 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/KseniiaSalmina/Profiles/internal/api/models"
+	"github.com/KseniiaSalmina/Profiles/internal/database"
+	"github.com/KseniiaSalmina/Profiles/internal/validation"
+)
+
+// @Summary Get all organizations
+// @Security BasicAuth
+// @Tags super admin
+// @Description return all organizations
+// @Return json
+// @Produce json,application/yaml,application/msgpack,text/csv
+// @Success 200 {array} models.OrganizationResponse
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Router /v1/organization [get]
+func (s *Server) getAllOrganizations(w http.ResponseWriter, r *http.Request) {
+	caller, err := s.authorization(r)
+	if err != nil {
+		s.log(r).WithError(err).Info("get all organizations handler, failed authorization")
+		http.Error(w, err.Error(), authErrorStatus(w, err))
+		return
+	}
+
+	if !caller.SuperAdmin {
+		s.log(r).Info("get all organizations handler, user is not super admin")
+		http.Error(w, validation.ErrIsNotSuperAdmin.Error(), http.StatusForbidden)
+		return
+	}
+
+	orgs, err := s.service.GetAllOrganizations(r.Context())
+	if err != nil {
+		s.log(r).WithError(err).Info("get all organizations handler, failed to get organizations")
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	s.respondList(w, r, http.StatusOK, orgs, orgs)
+}
+
+// @Summary Post organization
+// @Security BasicAuth
+// @Tags super admin
+// @Description create new organization, its name is used as tenant in "/tenant/{name}" path prefix and X-Tenant header
+// @Accept json,application/yaml,application/msgpack
+// @Return json
+// @Produce json,application/yaml,application/msgpack
+// @Param organization body models.OrganizationAdd true "new organization"
+// @Param Idempotency-Key header string false "retries with the same key replay the first response"
+// @Success 200 {string} string
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 500 {string} string
+// @Router /v1/organization [post]
+func (s *Server) postOrganization(w http.ResponseWriter, r *http.Request) {
+	caller, err := s.authorization(r)
+	if err != nil {
+		s.log(r).WithError(err).Info("post organization handler, failed authorization")
+		http.Error(w, err.Error(), authErrorStatus(w, err))
+		return
+	}
+
+	if !caller.SuperAdmin {
+		s.log(r).Info("post organization handler, user is not super admin")
+		http.Error(w, validation.ErrIsNotSuperAdmin.Error(), http.StatusForbidden)
+		return
+	}
+
+	var org models.OrganizationAdd
+	if err := decodeBody(r, &org); err != nil {
+		s.log(r).WithError(err).Info("post organization handler, failed unmarshall request body")
+		http.Error(w, err.Error(), decodeErrorStatus(err))
+		return
+	}
+	defer r.Body.Close()
+
+	if err := validation.OrganizationAdd(org); err != nil {
+		s.log(r).WithError(err).Info("post organization handler, invalid organization data")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	id, err := s.service.AddOrganization(r.Context(), org)
+	if err != nil {
+		s.log(r).WithError(err).Info("post organization handler, failed to add organization")
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	s.respond(w, r, http.StatusOK, id)
+}
+
+// @Summary Get organization by id
+// @Security BasicAuth
+// @Tags super admin
+// @Description return organization
+// @Return json
+// @Produce json,application/yaml,application/msgpack
+// @Param id path string true "organization's id in uuid format"
+// @Success 200 {object} models.OrganizationResponse
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 404 {string} string
+// @Router /v1/organization/{id} [get]
+func (s *Server) getOrganization(w http.ResponseWriter, r *http.Request) {
+	caller, err := s.authorization(r)
+	if err != nil {
+		s.log(r).WithError(err).Info("get organization handler, failed authorization")
+		http.Error(w, err.Error(), authErrorStatus(w, err))
+		return
+	}
+
+	if !caller.SuperAdmin {
+		s.log(r).Info("get organization handler, user is not super admin")
+		http.Error(w, validation.ErrIsNotSuperAdmin.Error(), http.StatusForbidden)
+		return
+	}
+
+	id, err := getPathUUID(r, "id")
+	if err != nil {
+		s.log(r).WithError(err).Info("get organization handler, failed to get id")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	org, err := s.service.GetOrganizationByID(r.Context(), id)
+	if err != nil {
+		s.log(r).WithError(err).Info("get organization handler, failed to get organization by id")
+		http.Error(w, err.Error(), organizationErrorStatus(err))
+		return
+	}
+
+	s.respond(w, r, http.StatusOK, org)
+}
+
+// @Summary Patch organization
+// @Security BasicAuth
+// @Tags super admin
+// @Description rename organization
+// @Accept json,application/yaml,application/msgpack
+// @Param id path string true "organization's id in uuid format"
+// @Param organization body models.OrganizationUpdate true "new name"
+// @Param Idempotency-Key header string false "retries with the same key replay the first response"
+// @Success 200
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 404 {string} string
+// @Failure 500 {string} string
+// @Router /v1/organization/{id} [patch]
+func (s *Server) patchOrganization(w http.ResponseWriter, r *http.Request) {
+	caller, err := s.authorization(r)
+	if err != nil {
+		s.log(r).WithError(err).Info("patch organization handler, failed authorization")
+		http.Error(w, err.Error(), authErrorStatus(w, err))
+		return
+	}
+
+	if !caller.SuperAdmin {
+		s.log(r).Info("patch organization handler, user is not super admin")
+		http.Error(w, validation.ErrIsNotSuperAdmin.Error(), http.StatusForbidden)
+		return
+	}
+
+	var org models.OrganizationUpdate
+	if err := decodeBody(r, &org); err != nil {
+		s.log(r).WithError(err).Info("patch organization handler, failed to unmarshall request body")
+		http.Error(w, err.Error(), decodeErrorStatus(err))
+		return
+	}
+	defer r.Body.Close()
+
+	if err := validation.OrganizationUpdate(org); err != nil {
+		s.log(r).WithError(err).Info("patch organization handler, invalid organization data")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	id, err := getPathUUID(r, "id")
+	if err != nil {
+		s.log(r).WithError(err).Info("patch organization handler, failed to get id")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.service.ChangeOrganization(r.Context(), id, org); err != nil {
+		s.log(r).WithError(err).Info("patch organization handler, failed to change organization")
+		http.Error(w, err.Error(), organizationErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// @Summary Delete organization
+// @Security BasicAuth
+// @Tags super admin
+// @Description delete organization without users, default organization can not be deleted
+// @Param id path string true "organization's id in uuid format"
+// @Success 200
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 404 {string} string
+// @Router /v1/organization/{id} [delete]
+func (s *Server) deleteOrganization(w http.ResponseWriter, r *http.Request) {
+	caller, err := s.authorization(r)
+	if err != nil {
+		s.log(r).WithError(err).Info("delete organization handler, failed authorization")
+		http.Error(w, err.Error(), authErrorStatus(w, err))
+		return
+	}
+
+	if !caller.SuperAdmin {
+		s.log(r).Info("delete organization handler, user is not super admin")
+		http.Error(w, validation.ErrIsNotSuperAdmin.Error(), http.StatusForbidden)
+		return
+	}
+
+	id, err := getPathUUID(r, "id")
+	if err != nil {
+		s.log(r).WithError(err).Info("delete organization handler, failed to get id")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.service.DeleteOrganization(r.Context(), id); err != nil {
+		s.log(r).WithError(err).Info("delete organization handler, failed to delete organization")
+		http.Error(w, err.Error(), organizationErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func organizationErrorStatus(err error) int {
+	if errors.Is(err, database.ErrOrganizationDoesNotExist) {
+		return http.StatusNotFound
+	}
+
+	return serviceErrorStatus(err, http.StatusBadRequest)
+}
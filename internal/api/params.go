@@ -0,0 +1,26 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bunrouter"
+)
+
+var ErrNoPathParam = errors.New("is required")
+var ErrNotUUID = errors.New("should be in uuid format")
+
+func getPathUUID(r *http.Request, name string) (string, error) {
+	id, ok := bunrouter.ParamsFromContext(r.Context()).Get(name)
+	if !ok {
+		return "", fmt.Errorf("%s %w", name, ErrNoPathParam)
+	}
+
+	if _, err := uuid.Parse(id); err != nil {
+		return "", fmt.Errorf("%s %w", name, ErrNotUUID)
+	}
+
+	return id, nil
+}
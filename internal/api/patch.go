@@ -0,0 +1,125 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+
+	"github.com/KseniiaSalmina/Profiles/internal/api/models"
+	"github.com/KseniiaSalmina/Profiles/internal/database"
+	"github.com/KseniiaSalmina/Profiles/internal/service"
+	"github.com/KseniiaSalmina/Profiles/internal/validation"
+)
+
+const (
+	mergePatchType = "application/merge-patch+json" // RFC 7396
+	jsonPatchType  = "application/json-patch+json"  // RFC 6902
+)
+
+var ErrInvalidPatch = errors.New("failed to apply patch")
+
+// patchUserDocument applies merge patch or JSON patch to models.UserReplace of the current profile.
+func (s *Server) patchUserDocument(w http.ResponseWriter, r *http.Request, caller *database.User, mediaType string) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.log(r).WithError(err).Info("patch user handler, failed to read request body")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	var apply func(doc []byte) ([]byte, error)
+	if mediaType == jsonPatchType {
+		patch, err := jsonpatch.DecodePatch(body)
+		if err != nil {
+			s.log(r).WithError(err).Info("patch user handler, invalid json patch")
+			http.Error(w, fmt.Sprintf("%s: %s", ErrInvalidPatch, err), http.StatusBadRequest)
+			return
+		}
+		apply = patch.Apply
+	} else {
+		if !json.Valid(body) {
+			s.log(r).Info("patch user handler, invalid merge patch")
+			http.Error(w, ErrInvalidPatch.Error()+": merge patch is not valid json", http.StatusBadRequest)
+			return
+		}
+		apply = func(doc []byte) ([]byte, error) {
+			return jsonpatch.MergePatch(doc, body)
+		}
+	}
+
+	id, err := getPathUUID(r, "id")
+	if err != nil {
+		s.log(r).WithError(err).Info("patch user handler, failed to get id")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = s.service.ReplaceUser(r.Context(), organizationFromContext(r.Context()), id, replacement(caller, func(current models.UserReplace) (models.UserReplace, error) {
+		doc, err := json.Marshal(current)
+		if err != nil {
+			return models.UserReplace{}, err
+		}
+
+		patched, err := apply(doc)
+		if err != nil {
+			return models.UserReplace{}, fmt.Errorf("%w: %w", ErrInvalidPatch, err)
+		}
+
+		var user models.UserReplace
+		decoder := json.NewDecoder(bytes.NewReader(patched))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&user); err != nil {
+			return models.UserReplace{}, fmt.Errorf("%w: %w", ErrInvalidPatch, err)
+		}
+
+		return user, nil
+	}))
+	if err != nil {
+		s.log(r).WithError(err).Info("patch user handler, failed to patch user")
+		http.Error(w, err.Error(), replaceErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// replacement validates profile built by build, the checks are the same for PUT and patches of every format.
+func replacement(caller *database.User, build func(current models.UserReplace) (models.UserReplace, error)) func(models.UserReplace) (models.UserReplace, error) {
+	return func(current models.UserReplace) (models.UserReplace, error) {
+		if current.SuperAdmin && !caller.SuperAdmin {
+			return models.UserReplace{}, validation.ErrIsNotSuperAdmin
+		}
+
+		user, err := build(current)
+		if err != nil {
+			return models.UserReplace{}, err
+		}
+
+		if err := validation.UserReplace(user); err != nil {
+			return models.UserReplace{}, err
+		}
+
+		if user.SuperAdmin != current.SuperAdmin && !caller.SuperAdmin {
+			return models.UserReplace{}, validation.ErrIsNotSuperAdmin
+		}
+
+		return user, nil
+	}
+}
+
+func replaceErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, validation.ErrIsNotSuperAdmin):
+		return http.StatusForbidden
+	case errors.Is(err, jsonpatch.ErrTestFailed), errors.Is(err, service.ErrConcurrentChange):
+		return http.StatusConflict
+	default:
+		return serviceErrorStatus(err, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,162 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/KseniiaSalmina/Profiles/internal/api/models"
+)
+
+func TestServer_replaceUser(t1 *testing.T) {
+	url := "/user/" + testUsers[2].ID
+
+	tests := []struct {
+		name        string
+		method      string
+		contentType string
+		body        string
+		username    string
+		wantCode    int
+		wantUser    models.UserResponse
+		password    string // password of testUser3 after request
+	}{
+		{
+			name:     "put replaces whole profile",
+			method:   "PUT",
+			body:     `{"username": "carol", "email": "carol@email.com", "disabled": false}`,
+			username: "username",
+			wantCode: http.StatusOK,
+			wantUser: models.UserResponse{Username: "carol", Email: "carol@email.com"},
+			password: "password",
+		},
+		{
+			name:     "put changes password",
+			method:   "PUT",
+			body:     `{"username": "testUser3", "email": "test3@email.com", "password": "secret", "attributes": {"department": "support"}}`,
+			username: "username",
+			wantCode: http.StatusOK,
+			wantUser: models.UserResponse{Username: "testUser3", Email: "test3@email.com", Attributes: map[string]any{"department": "support"}},
+			password: "secret",
+		},
+		{
+			name:        "merge patch removes attribute",
+			method:      "PATCH",
+			contentType: mergePatchType,
+			body:        `{"admin": true, "attributes": {"floor": null, "room": "12"}}`,
+			username:    "username",
+			wantCode:    http.StatusOK,
+			wantUser:    models.UserResponse{Username: "testUser3", Email: "test3@email.com", Admin: true, Attributes: map[string]any{"department": "sales", "room": "12"}},
+			password:    "password",
+		},
+		{
+			name:        "json patch with test",
+			method:      "PATCH",
+			contentType: jsonPatchType,
+			body:        `[{"op": "test", "path": "/email", "value": "test3@email.com"}, {"op": "replace", "path": "/email", "value": "carol@email.com"}, {"op": "remove", "path": "/attributes/floor"}, {"op": "add", "path": "/password", "value": "secret"}]`,
+			username:    "username",
+			wantCode:    http.StatusOK,
+			wantUser:    models.UserResponse{Username: "testUser3", Email: "carol@email.com", Attributes: map[string]any{"department": "sales"}},
+			password:    "secret",
+		},
+		{
+			name:        "failed json patch test",
+			method:      "PATCH",
+			contentType: jsonPatchType + "; charset=utf-8",
+			body:        `[{"op": "test", "path": "/email", "value": "carol@email.com"}, {"op": "replace", "path": "/username", "value": "carol"}]`,
+			username:    "username",
+			wantCode:    http.StatusConflict,
+		},
+		{name: "merge patch clears required field", method: "PATCH", contentType: mergePatchType, body: `{"email": null}`, username: "username", wantCode: http.StatusBadRequest},
+		{name: "merge patch of unknown field", method: "PATCH", contentType: mergePatchType, body: `{"id": "1"}`, username: "username", wantCode: http.StatusBadRequest},
+		{name: "malformed json patch", method: "PATCH", contentType: jsonPatchType, body: `{"op": "remove"}`, username: "username", wantCode: http.StatusBadRequest},
+		{name: "json patch of missing path", method: "PATCH", contentType: jsonPatchType, body: `[{"op": "replace", "path": "/phone", "value": "1"}]`, username: "username", wantCode: http.StatusBadRequest},
+		{name: "unsupported patch format", method: "PATCH", contentType: "text/plain", body: `email=carol@email.com`, username: "username", wantCode: http.StatusUnsupportedMediaType},
+		{name: "put without email", method: "PUT", body: `{"username": "carol"}`, username: "username", wantCode: http.StatusBadRequest},
+		{name: "put super admin by admin", method: "PUT", body: `{"username": "testUser3", "email": "test3@email.com", "super_admin": true}`, username: "admin", wantCode: http.StatusForbidden},
+		{name: "put by not admin", method: "PUT", body: `{"username": "carol", "email": "carol@email.com"}`, username: "testUser3", wantCode: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			server := prepareServer()
+			serve := func(method, url, contentType, body, username, password string) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(method, url, strings.NewReader(body))
+				if contentType != "" {
+					req.Header.Set("Content-Type", contentType)
+				}
+				req.SetBasicAuth(username, password)
+				w := httptest.NewRecorder()
+				server.httpServer.Handler.ServeHTTP(w, req)
+				return w
+			}
+			w := serve("POST", "/user", "", `{"username": "admin", "password": "password", "email": "admin@email.com", "admin": true}`, "username", "password")
+			require.Equal(t1, http.StatusOK, w.Code)
+			w = serve("PATCH", url, "", `{"attributes": {"department": "sales", "floor": 2}}`, "username", "password")
+			require.Equal(t1, http.StatusOK, w.Code)
+
+			w = serve(tt.method, url, tt.contentType, tt.body, tt.username, "password")
+			require.Equal(t1, tt.wantCode, w.Code, w.Body.String())
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+
+			w = serve("GET", url, "", "", "username", "password")
+			var user models.UserResponse
+			require.NoError(t1, json.NewDecoder(w.Body).Decode(&user))
+			assert.Equal(t1, tt.wantUser.Username, user.Username)
+			assert.Equal(t1, tt.wantUser.Email, user.Email)
+			assert.Equal(t1, tt.wantUser.Admin, user.Admin)
+			assert.Equal(t1, tt.wantUser.Attributes, user.Attributes)
+
+			w = serve("GET", url, "", "", user.Username, tt.password)
+			assert.Equal(t1, http.StatusOK, w.Code, "user logs in with password")
+		})
+	}
+}
+
+func TestServer_replaceSuperAdmin(t1 *testing.T) {
+	server := prepareServer()
+	serve := func(method, url, contentType, body, username string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		req.SetBasicAuth(username, "password")
+		w := httptest.NewRecorder()
+		server.httpServer.Handler.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("POST", "/user", "", `{"username": "admin", "password": "password", "email": "admin@email.com", "admin": true}`, "username")
+	require.Equal(t1, http.StatusOK, w.Code)
+	url := "/user/" + superAdminID(t1, server)
+
+	tests := []struct {
+		name        string
+		method      string
+		contentType string
+		body        string
+		username    string
+		wantCode    int
+	}{
+		{name: "put keeping super admin flag", method: "PUT", body: `{"username": "username", "email": "root@email.com", "password": "hijacked", "admin": true, "super_admin": true}`, username: "admin", wantCode: http.StatusForbidden},
+		{name: "merge patch", method: "PATCH", contentType: mergePatchType, body: `{"disabled": true}`, username: "admin", wantCode: http.StatusForbidden},
+		{name: "json patch", method: "PATCH", contentType: jsonPatchType, body: `[{"op": "add", "path": "/password", "value": "hijacked"}]`, username: "admin", wantCode: http.StatusForbidden},
+		{name: "merge patch by super admin", method: "PATCH", contentType: mergePatchType, body: `{"email": "root@email.com"}`, username: "username", wantCode: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			assert.Equal(t1, tt.wantCode, serve(tt.method, url, tt.contentType, tt.body, tt.username).Code)
+		})
+	}
+
+	// password of super admin is not changed
+	assert.Equal(t1, http.StatusOK, serve("GET", url, "", "", "username").Code)
+}
@@ -0,0 +1,204 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/KseniiaSalmina/Profiles/internal/config"
+	"github.com/KseniiaSalmina/Profiles/internal/database"
+	"github.com/KseniiaSalmina/Profiles/internal/ratelimit"
+)
+
+const apiKeyHeader = "X-API-Key"
+
+// Budgets are prefixes of bucket keys and label values of rate limit metric.
+const (
+	budgetRead        = "read"
+	budgetWrite       = "write"
+	budgetAuthFailure = "auth_failure"
+)
+
+var (
+	ErrRateLimited       = errors.New("too many requests")
+	ErrNegativeRateLimit = errors.New("rate limit can not be negative")
+)
+
+// unlimitedPaths are probes which should not be throttled.
+var unlimitedPaths = map[string]bool{"/healthz": true, "/readyz": true}
+
+type rateLimits struct {
+	read        ratelimit.Limit
+	write       ratelimit.Limit
+	authFailure ratelimit.Limit
+}
+
+func newRateLimits(cfg config.RateLimit) (rateLimits, error) {
+	if cfg.RateLimitRead < 0 || cfg.RateLimitWrite < 0 || cfg.RateLimitAuthFailure < 0 {
+		return rateLimits{}, ErrNegativeRateLimit
+	}
+
+	return rateLimits{
+		read:        ratelimit.Limit{Rate: cfg.RateLimitRead, Burst: cfg.RateLimitReadBurst},
+		write:       ratelimit.Limit{Rate: cfg.RateLimitWrite, Burst: cfg.RateLimitWriteBurst},
+		authFailure: ratelimit.Limit{Rate: cfg.RateLimitAuthFailure, Burst: cfg.RateLimitAuthFailureBurst},
+	}, nil
+}
+
+// rateLimitError is returned by authorization when budget of the user or auth failures of the client is exhausted.
+type rateLimitError struct {
+	result ratelimit.Result
+}
+
+func (e *rateLimitError) Error() string {
+	return ErrRateLimited.Error()
+}
+
+func (e *rateLimitError) Unwrap() error {
+	return ErrRateLimited
+}
+
+// SetRateLimitStore sets store of token buckets, buckets are kept in memory by default.
+func (s *Server) SetRateLimitStore(store ratelimit.Store) {
+	s.rateLimitStore = store
+}
+
+// rateLimit takes a token from buckets of client IP and API key, request is rejected if any of them is empty.
+func (s *Server) rateLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		budget, limit := s.requestBudget(r)
+		if unlimitedPaths[r.URL.Path] || !limit.Enabled() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		keys := []string{"ip:" + s.clientIP(r)}
+		if apiKey := r.Header.Get(apiKeyHeader); apiKey != "" {
+			keys = append(keys, "api_key:"+hashKey(apiKey))
+		}
+
+		var strictest *ratelimit.Result
+		for _, key := range keys {
+			result, ok := s.takeToken(r, budget, key, limit, 1)
+			if !ok {
+				continue
+			}
+			if !result.Allowed {
+				s.log(r).WithField("budget", budget).Info("rate limit exceeded")
+				setRateLimitHeaders(w, result)
+				http.Error(w, ErrRateLimited.Error(), http.StatusTooManyRequests)
+				return
+			}
+			if strictest == nil || result.Remaining < strictest.Remaining {
+				strictest = &result
+			}
+		}
+
+		if strictest != nil {
+			setRateLimitHeaders(w, *strictest)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// checkAuthFailures rejects credentials of client which has exhausted its auth failure budget, so password is not checked.
+func (s *Server) checkAuthFailures(r *http.Request) error {
+	limit := s.settings.Load().rateLimits.authFailure
+	if !limit.Enabled() {
+		return nil
+	}
+
+	if result, ok := s.takeToken(r, budgetAuthFailure, "ip:"+s.clientIP(r), limit, 0); ok && !result.Allowed {
+		return &rateLimitError{result: result}
+	}
+
+	return nil
+}
+
+func (s *Server) recordAuthFailure(r *http.Request) {
+	limit := s.settings.Load().rateLimits.authFailure
+	if limit.Enabled() {
+		s.takeToken(r, budgetAuthFailure, "ip:"+s.clientIP(r), limit, 1)
+	}
+}
+
+// limitUser takes a token from the bucket of authenticated user.
+func (s *Server) limitUser(r *http.Request, user *database.User) error {
+	budget, limit := s.requestBudget(r)
+	if !limit.Enabled() {
+		return nil
+	}
+
+	if result, ok := s.takeToken(r, budget, "user:"+user.ID, limit, 1); ok && !result.Allowed {
+		s.log(r).WithField("budget", budget).Info("rate limit exceeded")
+		return &rateLimitError{result: result}
+	}
+
+	return nil
+}
+
+// takeToken reports false if store failed, such requests are not limited.
+func (s *Server) takeToken(r *http.Request, budget, key string, limit ratelimit.Limit, n int) (ratelimit.Result, bool) {
+	result, err := s.rateLimitStore.Take(r.Context(), budget+":"+key, limit, n)
+	if err != nil {
+		s.log(r).WithError(err).Warn("failed to check rate limit")
+		return ratelimit.Result{}, false
+	}
+
+	if !result.Allowed && n > 0 {
+		s.metrics.ObserveRateLimited(budget)
+	}
+
+	return result, true
+}
+
+func (s *Server) requestBudget(r *http.Request) (string, ratelimit.Limit) {
+	limits := s.settings.Load().rateLimits
+
+	if safeMethod(r.Method) {
+		return budgetRead, limits.read
+	}
+
+	return budgetWrite, limits.write
+}
+
+func safeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// authErrorStatus returns status of failed authorization, rate limited requests also get their headers.
+func authErrorStatus(w http.ResponseWriter, err error) int {
+	var limited *rateLimitError
+	switch {
+	case errors.As(err, &limited):
+		setRateLimitHeaders(w, limited.result)
+		return http.StatusTooManyRequests
+	case errors.Is(err, ErrInvalidCSRFToken):
+		return http.StatusForbidden
+	default:
+		return http.StatusUnauthorized
+	}
+}
+
+func setRateLimitHeaders(w http.ResponseWriter, result ratelimit.Result) {
+	w.Header().Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+	w.Header().Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	w.Header().Set("RateLimit-Reset", ceilSeconds(result.Reset))
+	if !result.Allowed {
+		w.Header().Set("Retry-After", ceilSeconds(result.RetryAfter))
+	}
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
+
+// hashKey keeps API keys out of the store.
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,165 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/KseniiaSalmina/Profiles/internal/config"
+	"github.com/KseniiaSalmina/Profiles/internal/ratelimit"
+)
+
+// prepareRateLimitedServer freezes clock of buckets, so tokens are not refilled between requests of a test.
+func prepareRateLimitedServer(t1 *testing.T, limits config.RateLimit) *Server {
+	server := prepareServer()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	server.SetRateLimitStore(ratelimit.NewMemoryWithClock(func() time.Time { return now }))
+
+	cfg := serverCfg
+	cfg.RateLimit = limits
+	apply, err := server.Reconfigure(cfg)
+	require.NoError(t1, err)
+	apply()
+
+	return server
+}
+
+func TestServer_rateLimit(t1 *testing.T) {
+	type request struct {
+		method   string
+		url      string
+		ip       string
+		apiKey   string
+		username string
+		password string
+	}
+	serve := func(server *Server, req request) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(req.method, req.url, nil)
+		r.RemoteAddr = req.ip + ":1234"
+		if req.apiKey != "" {
+			r.Header.Set(apiKeyHeader, req.apiKey)
+		}
+		if req.username != "" {
+			r.SetBasicAuth(req.username, req.password)
+		}
+		w := httptest.NewRecorder()
+		server.httpServer.Handler.ServeHTTP(w, r)
+		return w
+	}
+
+	tests := []struct {
+		name     string
+		limits   config.RateLimit
+		requests []request
+		last     request
+		wantCode int
+	}{
+		{
+			name:     "client ip read budget",
+			limits:   config.RateLimit{RateLimitRead: 1, RateLimitReadBurst: 2},
+			requests: []request{{method: "GET", url: "/user", ip: "10.0.0.1"}, {method: "GET", url: "/user", ip: "10.0.0.1"}},
+			last:     request{method: "GET", url: "/user", ip: "10.0.0.1", username: "testUser3", password: "password"},
+			wantCode: http.StatusTooManyRequests,
+		},
+		{
+			name:     "other client ip",
+			limits:   config.RateLimit{RateLimitRead: 1, RateLimitReadBurst: 2},
+			requests: []request{{method: "GET", url: "/user", ip: "10.0.0.1"}, {method: "GET", url: "/user", ip: "10.0.0.1"}},
+			last:     request{method: "GET", url: "/user", ip: "10.0.0.2", username: "testUser3", password: "password"},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "write budget is separate",
+			limits:   config.RateLimit{RateLimitRead: 1, RateLimitReadBurst: 1, RateLimitWrite: 1, RateLimitWriteBurst: 1},
+			requests: []request{{method: "GET", url: "/user", ip: "10.0.0.1"}},
+			last:     request{method: "POST", url: "/user", ip: "10.0.0.1"},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "probes are not limited",
+			limits:   config.RateLimit{RateLimitRead: 1, RateLimitReadBurst: 1},
+			requests: []request{{method: "GET", url: "/user", ip: "10.0.0.1"}},
+			last:     request{method: "GET", url: "/healthz", ip: "10.0.0.1"},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "api key budget",
+			limits:   config.RateLimit{RateLimitRead: 1, RateLimitReadBurst: 2},
+			requests: []request{{method: "GET", url: "/user", ip: "10.0.0.1", apiKey: "key"}, {method: "GET", url: "/user", ip: "10.0.0.2", apiKey: "key"}},
+			last:     request{method: "GET", url: "/user", ip: "10.0.0.3", apiKey: "key"},
+			wantCode: http.StatusTooManyRequests,
+		},
+		{
+			name:   "authenticated user budget",
+			limits: config.RateLimit{RateLimitRead: 1, RateLimitReadBurst: 2},
+			requests: []request{
+				{method: "GET", url: "/user", ip: "10.0.0.1", username: "testUser3", password: "password"},
+				{method: "GET", url: "/user", ip: "10.0.0.2", username: "testUser3", password: "password"},
+			},
+			last:     request{method: "GET", url: "/user", ip: "10.0.0.3", username: "testUser3", password: "password"},
+			wantCode: http.StatusTooManyRequests,
+		},
+		{
+			name:   "auth failure budget",
+			limits: config.RateLimit{RateLimitAuthFailure: 1, RateLimitAuthFailureBurst: 2},
+			requests: []request{
+				{method: "GET", url: "/user", ip: "10.0.0.1", username: "testUser3", password: "wrong"},
+				{method: "GET", url: "/user", ip: "10.0.0.1", username: "unknown", password: "password"},
+			},
+			last:     request{method: "GET", url: "/user", ip: "10.0.0.1", username: "testUser3", password: "password"},
+			wantCode: http.StatusTooManyRequests,
+		},
+		{
+			name:   "successful auth does not spend failure budget",
+			limits: config.RateLimit{RateLimitAuthFailure: 1, RateLimitAuthFailureBurst: 1},
+			requests: []request{
+				{method: "GET", url: "/user", ip: "10.0.0.1", username: "testUser3", password: "password"},
+				{method: "GET", url: "/user", ip: "10.0.0.1", username: "testUser3", password: "password"},
+			},
+			last:     request{method: "GET", url: "/user", ip: "10.0.0.1", username: "testUser3", password: "password"},
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			server := prepareRateLimitedServer(t1, tt.limits)
+			for _, req := range tt.requests {
+				serve(server, req)
+			}
+
+			w := serve(server, tt.last)
+			assert.Equal(t1, tt.wantCode, w.Code)
+			if tt.wantCode == http.StatusTooManyRequests {
+				assert.Equal(t1, "1", w.Header().Get("Retry-After"))
+				assert.Equal(t1, "0", w.Header().Get("RateLimit-Remaining"))
+				assert.NotEmpty(t1, w.Header().Get("RateLimit-Limit"))
+				assert.NotEmpty(t1, w.Header().Get("RateLimit-Reset"))
+			}
+		})
+	}
+}
+
+func TestServer_rateLimitHeaders(t1 *testing.T) {
+	server := prepareRateLimitedServer(t1, config.RateLimit{RateLimitRead: 1, RateLimitReadBurst: 5})
+
+	req := httptest.NewRequest("GET", "/user", nil)
+	req.SetBasicAuth("testUser3", "password")
+	w := httptest.NewRecorder()
+	server.httpServer.Handler.ServeHTTP(w, req)
+
+	assert.Equal(t1, http.StatusOK, w.Code)
+	assert.Equal(t1, "5", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t1, "4", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t1, "1", w.Header().Get("RateLimit-Reset"))
+	assert.Empty(t1, w.Header().Get("Retry-After"))
+
+	cfg := serverCfg
+	cfg.RateLimitRead = -1
+	_, err := server.Reconfigure(cfg)
+	assert.ErrorIs(t1, err, ErrNegativeRateLimit)
+}
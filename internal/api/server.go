@@ -2,7 +2,9 @@ package api
 
 import (
 	"context"
+	"io"
 	"net/http"
+	"sync/atomic"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -12,59 +14,222 @@ import (
 	"github.com/KseniiaSalmina/Profiles/internal/api/models"
 	"github.com/KseniiaSalmina/Profiles/internal/config"
 	"github.com/KseniiaSalmina/Profiles/internal/database"
+	"github.com/KseniiaSalmina/Profiles/internal/idempotency"
+	"github.com/KseniiaSalmina/Profiles/internal/metrics"
+	"github.com/KseniiaSalmina/Profiles/internal/ratelimit"
+	"github.com/KseniiaSalmina/Profiles/internal/service"
+	"github.com/KseniiaSalmina/Profiles/internal/session"
 )
 
 type Service interface {
+	// ReturnSalt is the only method without context: salt is fixed when service is created and is read without I/O.
 	ReturnSalt() string
-	GetAuthData(username string) (*database.User, error)
-	GetAllUsers(limit, offset, pageNo int) *models.PageUsers
-	AddUser(user models.UserAdd) (string, error)
-	GetUserByID(id string) (*models.UserResponse, error)
-	ChangeUser(id string, user models.UserUpdate) error
-	DeleteUser(id string) error
+	GetAuthData(ctx context.Context, orgID, username string) (*database.User, error)
+	RecordLogin(ctx context.Context, user *database.User) error
+	GetAllUsers(ctx context.Context, orgID string, limit, offset, pageNo int, filter models.UserFilter) (*models.PageUsers, error)
+	AddUser(ctx context.Context, orgID string, user models.UserAdd) (string, error)
+	GetUserByID(ctx context.Context, orgID, id string) (*models.UserResponse, error)
+	ChangeUser(ctx context.Context, orgID, id string, user models.UserUpdate, callerSuperAdmin bool) error
+	ReplaceUser(ctx context.Context, orgID, id string, replace func(current models.UserReplace) (models.UserReplace, error)) error
+	DeleteUser(ctx context.Context, orgID, id string, callerSuperAdmin bool) error
+	ExportUsers(ctx context.Context, orgID string, filter models.UserFilter, fn func(user models.UserResponse) error) error
+	ImportUsers(ctx context.Context, orgID string, rows []models.ImportRow, opts models.ImportOptions) (*models.ImportJob, error)
+	StreamImport(ctx context.Context, orgID string, source service.ImportSource, opts models.ImportOptions) (*models.ImportJob, error)
+	StartImport(ctx context.Context, orgID string, rows []models.ImportRow, opts models.ImportOptions) (*models.ImportJob, error)
+	GetImport(ctx context.Context, orgID, id string) (*models.ImportJob, error)
+	Batch(ctx context.Context, orgID string, ops []models.BatchOperation, allOrNothing, callerSuperAdmin bool) (*models.BatchResponse, error)
+	GetAttributeSchema(ctx context.Context) (models.AttributeSchema, error)
+	SetAttributeSchema(ctx context.Context, schema models.AttributeSchema) error
+	SetAvatar(ctx context.Context, orgID, id string, r io.Reader, callerSuperAdmin bool) error
+	GetAvatar(ctx context.Context, orgID, id string, size int) (io.ReadSeekCloser, time.Time, error)
+	GetAllGroups(ctx context.Context, orgID string) ([]models.GroupResponse, error)
+	AddGroup(ctx context.Context, orgID string, group models.GroupAdd) (string, error)
+	GetGroupByID(ctx context.Context, orgID, id string) (*models.GroupResponse, error)
+	ChangeGroup(ctx context.Context, orgID, id string, group models.GroupUpdate) error
+	DeleteGroup(ctx context.Context, orgID, id string) error
+	AddMember(ctx context.Context, orgID, groupID, userID string) error
+	DeleteMember(ctx context.Context, orgID, groupID, userID string) error
+	GetUserGroups(ctx context.Context, orgID, userID string) ([]models.GroupResponse, error)
+	GetGroupMembers(ctx context.Context, orgID, groupID string) ([]models.UserResponse, error)
+	ResolveOrganization(ctx context.Context, name string) (string, error)
+	GetAllOrganizations(ctx context.Context) ([]models.OrganizationResponse, error)
+	AddOrganization(ctx context.Context, org models.OrganizationAdd) (string, error)
+	GetOrganizationByID(ctx context.Context, id string) (*models.OrganizationResponse, error)
+	ChangeOrganization(ctx context.Context, id string, org models.OrganizationUpdate) error
+	DeleteOrganization(ctx context.Context, id string) error
+	InactivityReport(ctx context.Context, orgID string) ([]models.InactivityAction, error)
 }
 
 type Server struct {
-	httpServer *http.Server
-	service    Service
-	logger     *logrus.Logger
+	httpServer       *http.Server
+	metricsServer    *http.Server
+	redirectServer   *http.Server // nil if TLS or redirect listener is disabled
+	service          Service
+	logger           *logrus.Logger
+	metrics          *metrics.Metrics
+	settings         atomic.Pointer[settings]
+	rateLimitStore   ratelimit.Store
+	idempotencyStore idempotency.Store
+	sessions         session.Store // nil if sessions are disabled
+	sessionConfig    config.Session
+	healthChecks     []healthCheck
+	draining         atomic.Bool
 }
 
-func NewServer(cfg config.Server, service Service, logger *logrus.Logger) *Server {
-	s := &Server{service: service, logger: logger}
+func NewServer(cfg config.Server, service Service, logger *logrus.Logger, metrics *metrics.Metrics) (*Server, error) {
+	settings, err := newSettings(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{service: service, logger: logger, metrics: metrics, rateLimitStore: ratelimit.NewMemory(), idempotencyStore: idempotency.NewMemory(), sessionConfig: cfg.Session}
+	s.settings.Store(settings)
 
-	router := bunrouter.New().Compat()
-	router.GET("/user", s.getAllUsers)
-	router.POST("/user", s.postUser)
-	router.GET("/user/:id", s.getUser)
-	router.PATCH("/user/:id", s.patchUser)
-	router.DELETE("/user/:id", s.deleteUser)
+	if cfg.SessionEnabled {
+		s.sessions = session.NewMemory()
+	}
+
+	router := bunrouter.New(bunrouter.Use(s.route, s.instrument)).Compat()
+	s.routes(router.NewGroup(v1.prefix, bunrouter.Use(s.versioned(v1))))
+	if cfg.LegacyRoutes {
+		s.routes(router.NewGroup("", bunrouter.Use(s.versioned(v1), s.deprecated(v1))))
+	}
+	router.GET("/healthz", s.getLiveness)
+	router.GET("/readyz", s.getReadiness)
 
 	swagHandler := httpSwagger.Handler(httpSwagger.URL("/swagger/doc.json"))
 	router.GET("/swagger/*path", swagHandler)
 
+	// metrics listener is expected to be reachable only by scrapers, main listener requires super admin
+	if cfg.MetricsListen == "" {
+		router.GET(metricsPath, s.getMetrics)
+	} else {
+		mux := http.NewServeMux()
+		mux.Handle(metricsPath, metrics.Handler())
+		s.metricsServer = &http.Server{
+			Addr:         cfg.MetricsListen,
+			Handler:      mux,
+			ReadTimeout:  cfg.ReadTimeout,
+			WriteTimeout: cfg.WriteTimeout,
+			IdleTimeout:  cfg.IdleTimeout,
+		}
+	}
+
 	s.httpServer = &http.Server{
 		Addr:         cfg.Listen,
-		Handler:      router,
+		Handler:      s.trace(s.logging(s.cors(s.rateLimit(s.timeout(s.tenant(router)))))),
 		ReadTimeout:  cfg.ReadTimeout,
 		WriteTimeout: cfg.WriteTimeout,
 		IdleTimeout:  cfg.IdleTimeout,
 	}
 
-	return s
+	if cfg.TLS.Enabled() {
+		if s.httpServer.TLSConfig, err = newTLSConfig(cfg.TLS, logger); err != nil {
+			return nil, err
+		}
+
+		if cfg.TLSRedirectListen != "" {
+			s.redirectServer = &http.Server{
+				Addr:         cfg.TLSRedirectListen,
+				Handler:      redirectToHTTPS(cfg.Listen),
+				ReadTimeout:  cfg.ReadTimeout,
+				WriteTimeout: cfg.WriteTimeout,
+				IdleTimeout:  cfg.IdleTimeout,
+			}
+		}
+	}
+
+	return s, nil
+}
+
+// routes registers API handlers on group of version, health probes, metrics and swagger are not versioned.
+func (s *Server) routes(g *bunrouter.CompatGroup) {
+	g.GET("/user", s.negotiatedList(s.getAllUsers))
+	g.POST("/user", s.negotiated(s.idempotent(s.postUser)))
+	g.GET("/user/:id", s.negotiated(s.getUser))
+	g.PATCH("/user/:id", s.idempotent(s.patchUser))
+	g.PUT("/user/:id", s.putUser)
+	g.DELETE("/user/:id", s.deleteUser)
+	g.POST("/user/import", s.negotiated(s.idempotent(s.postUserImport)))
+	g.GET("/user/import/:id", s.negotiated(s.getUserImport))
+	g.GET("/user/export", s.getUserExport)
+	g.POST("/user/batch", s.negotiated(s.idempotent(s.postUserBatch)))
+	g.PUT("/user/:id/avatar", s.putAvatar)
+	g.GET("/user/:id/avatar", s.getAvatar)
+	g.GET("/user/:id/groups", s.negotiatedList(s.getUserGroups))
+	g.GET("/group", s.negotiatedList(s.getAllGroups))
+	g.POST("/group", s.negotiated(s.idempotent(s.postGroup)))
+	g.GET("/group/:id", s.negotiated(s.getGroup))
+	g.PATCH("/group/:id", s.idempotent(s.patchGroup))
+	g.DELETE("/group/:id", s.deleteGroup)
+	g.GET("/group/:id/members", s.negotiatedList(s.getGroupMembers))
+	g.PUT("/group/:id/members/:userID", s.putGroupMember)
+	g.DELETE("/group/:id/members/:userID", s.deleteGroupMember)
+	g.GET("/organization", s.negotiatedList(s.getAllOrganizations))
+	g.POST("/organization", s.negotiated(s.idempotent(s.postOrganization)))
+	g.GET("/organization/:id", s.negotiated(s.getOrganization))
+	g.PATCH("/organization/:id", s.idempotent(s.patchOrganization))
+	g.DELETE("/organization/:id", s.deleteOrganization)
+	g.GET("/inactivity/report", s.negotiatedList(s.getInactivityReport))
+	g.GET("/health", s.negotiated(s.getHealth))
+	g.PUT("/admin/log-level", s.putLogLevel)
+	g.GET("/attributes/schema", s.negotiated(s.getAttributeSchema))
+	g.PUT("/attributes/schema", s.putAttributeSchema)
+
+	if s.sessions != nil {
+		g.POST("/session", s.negotiated(s.postSession))
+		g.GET("/session", s.negotiated(s.getSession))
+		g.DELETE("/session", s.deleteSession)
+	}
 }
 
 func (s *Server) Run() {
 	s.logger.Infof("server started at port %s", s.httpServer.Addr)
 
 	go func() {
-		err := s.httpServer.ListenAndServe()
+		var err error
+		if s.httpServer.TLSConfig != nil {
+			err = s.httpServer.ListenAndServeTLS("", "")
+		} else {
+			err = s.httpServer.ListenAndServe()
+		}
 		s.logger.Infof("server stopped: %s", err.Error())
 	}()
+
+	if s.redirectServer != nil {
+		s.logger.Infof("HTTPS redirect server started at port %s", s.redirectServer.Addr)
+
+		go func() {
+			err := s.redirectServer.ListenAndServe()
+			s.logger.Infof("HTTPS redirect server stopped: %s", err.Error())
+		}()
+	}
+
+	if s.metricsServer != nil {
+		s.logger.Infof("metrics server started at port %s", s.metricsServer.Addr)
+
+		go func() {
+			err := s.metricsServer.ListenAndServe()
+			s.logger.Infof("metrics server stopped: %s", err.Error())
+		}()
+	}
 }
 
 func (s *Server) Shutdown() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
+
+	if s.metricsServer != nil {
+		if err := s.metricsServer.Shutdown(ctx); err != nil {
+			s.logger.Infof("metrics server stopped: %s", err.Error())
+		}
+	}
+
+	if s.redirectServer != nil {
+		if err := s.redirectServer.Shutdown(ctx); err != nil {
+			s.logger.Infof("HTTPS redirect server stopped: %s", err.Error())
+		}
+	}
+
 	return s.httpServer.Shutdown(ctx)
 }
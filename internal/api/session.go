@@ -0,0 +1,188 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/KseniiaSalmina/Profiles/internal/api/models"
+	"github.com/KseniiaSalmina/Profiles/internal/database"
+	"github.com/KseniiaSalmina/Profiles/internal/metrics"
+	"github.com/KseniiaSalmina/Profiles/internal/session"
+)
+
+const (
+	sessionCookie = "profiles_session"
+	csrfHeader    = "X-CSRF-Token"
+)
+
+var (
+	ErrInvalidCSRFToken = errors.New("missing or invalid " + csrfHeader + " header")
+	ErrNoSession        = errors.New("request is not authorized by session cookie")
+	ErrSessionsDisabled = errors.New("sessions are disabled")
+)
+
+// SetSessionStore sets store of sessions, sessions are kept in memory by default.
+// Server built with sessions disabled has no session routes, so store can't be set.
+func (s *Server) SetSessionStore(store session.Store) error {
+	if s.sessions == nil {
+		return ErrSessionsDisabled
+	}
+
+	s.sessions = store
+	return nil
+}
+
+// sessionAuthorization checks session cookie, unsafe requests also need CSRF token of the session.
+func (s *Server) sessionAuthorization(r *http.Request, token string) (*database.User, error) {
+	if err := s.checkAuthFailures(r); err != nil {
+		return nil, err
+	}
+
+	sess, err := s.sessions.Get(r.Context(), token)
+	if err != nil {
+		s.metrics.ObserveAuth(metrics.AuthFailure, metrics.ReasonNoSession)
+		if errors.Is(err, session.ErrSessionDoesNotExist) {
+			s.recordAuthFailure(r)
+		}
+		return nil, err
+	}
+
+	if !safeMethod(r.Method) && !sess.ValidCSRF(r.Header.Get(csrfHeader)) {
+		s.metrics.ObserveAuth(metrics.AuthFailure, metrics.ReasonInvalidCSRF)
+		return nil, ErrInvalidCSRFToken
+	}
+
+	user, err := s.authData(r, sess.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	// username may belong to another user in organization of the request, and password change ends all sessions
+	if user.ID != sess.UserID || user.PasswordChangedAt.After(sess.CreatedAt) {
+		s.metrics.ObserveAuth(metrics.AuthFailure, metrics.ReasonNoSession)
+		return nil, session.ErrSessionDoesNotExist
+	}
+
+	return s.activeUser(r, user)
+}
+
+// @Summary Post session
+// @Tags session
+// @Description log in with username and password, session token is set in HttpOnly cookie, returned CSRF token is required in X-CSRF-Token header of POST, PUT, PATCH and DELETE requests
+// @Accept json,application/yaml,application/msgpack
+// @Produce json,application/yaml,application/msgpack
+// @Param credentials body models.SessionAdd true "username and password"
+// @Success 200 {object} models.SessionResponse
+// @Failure 401 {string} string
+// @Failure 429 {string} string
+// @Failure 500 {string} string
+// @Router /v1/session [post]
+func (s *Server) postSession(w http.ResponseWriter, r *http.Request) {
+	var credentials models.SessionAdd
+	if err := decodeBody(r, &credentials); err != nil {
+		s.log(r).WithError(err).Info("post session handler, failed unmarshall request body")
+		http.Error(w, err.Error(), decodeErrorStatus(err))
+		return
+	}
+	defer r.Body.Close()
+
+	user, err := s.passwordAuthorization(r, credentials.Username, credentials.Password)
+	if err != nil {
+		s.log(r).WithError(err).Info("post session handler, failed authorization")
+		http.Error(w, err.Error(), authErrorStatus(w, err))
+		return
+	}
+
+	sess, err := session.New(user.ID, organizationFromContext(r.Context()), user.Username, s.sessionConfig.SessionTTL, time.Now())
+	if err != nil {
+		s.log(r).WithError(err).Error("post session handler, failed to create session")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := s.sessions.Create(r.Context(), sess); err != nil {
+		s.log(r).WithError(err).Info("post session handler, failed to save session")
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    sess.Token,
+		Path:     "/",
+		Expires:  sess.ExpiresAt,
+		HttpOnly: true,
+		Secure:   s.sessionConfig.SessionCookieSecure,
+		SameSite: http.SameSiteStrictMode,
+	})
+
+	s.respond(w, r, http.StatusOK, models.SessionResponse{Username: sess.Username, CSRFToken: sess.CSRFToken, ExpiresAt: sess.ExpiresAt})
+}
+
+// @Summary Get session
+// @Tags session
+// @Description return current session and its CSRF token
+// @Produce json,application/yaml,application/msgpack
+// @Success 200 {object} models.SessionResponse
+// @Failure 401 {string} string
+// @Router /v1/session [get]
+func (s *Server) getSession(w http.ResponseWriter, r *http.Request) {
+	sess, err := s.currentSession(r)
+	if err != nil {
+		s.log(r).WithError(err).Info("get session handler, failed authorization")
+		http.Error(w, err.Error(), authErrorStatus(w, err))
+		return
+	}
+
+	s.respond(w, r, http.StatusOK, models.SessionResponse{Username: sess.Username, CSRFToken: sess.CSRFToken, ExpiresAt: sess.ExpiresAt})
+}
+
+// @Summary Delete session
+// @Tags session
+// @Description log out, X-CSRF-Token header is required
+// @Param X-CSRF-Token header string true "CSRF token of the session"
+// @Success 200
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Router /v1/session [delete]
+func (s *Server) deleteSession(w http.ResponseWriter, r *http.Request) {
+	if _, err := s.currentSession(r); err != nil {
+		s.log(r).WithError(err).Info("delete session handler, failed authorization")
+		http.Error(w, err.Error(), authErrorStatus(w, err))
+		return
+	}
+
+	cookie, _ := r.Cookie(sessionCookie)
+	if err := s.sessions.Delete(r.Context(), cookie.Value); err != nil {
+		s.log(r).WithError(err).Info("delete session handler, failed to delete session")
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   s.sessionConfig.SessionCookieSecure,
+		SameSite: http.SameSiteStrictMode,
+	})
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// currentSession authorizes request by session cookie only.
+func (s *Server) currentSession(r *http.Request) (session.Session, error) {
+	cookie, err := r.Cookie(sessionCookie)
+	if err != nil {
+		s.metrics.ObserveAuth(metrics.AuthFailure, metrics.ReasonNoCredentials)
+		return session.Session{}, ErrNoSession
+	}
+
+	if _, err := s.sessionAuthorization(r, cookie.Value); err != nil {
+		return session.Session{}, err
+	}
+
+	return s.sessions.Get(r.Context(), cookie.Value)
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/KseniiaSalmina/Profiles/internal/api/models"
+	"github.com/KseniiaSalmina/Profiles/internal/session"
+)
+
+func TestServer_session(t1 *testing.T) {
+	cfg := serverCfg
+	cfg.SessionEnabled = true
+	cfg.SessionTTL = time.Hour
+	cfg.SessionCookieSecure = true
+	server := prepareServerWithConfigs(cfg, serviceCfg)
+
+	serve := func(method, url, body string, cookie *http.Cookie, csrf string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		if csrf != "" {
+			req.Header.Set(csrfHeader, csrf)
+		}
+		w := httptest.NewRecorder()
+		server.httpServer.Handler.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t1, http.StatusUnauthorized, serve("POST", "/session", `{"username": "testUser3", "password": "wrong"}`, nil, "").Code)
+
+	w := serve("POST", "/session", `{"username": "username", "password": "password"}`, nil, "")
+	require.Equal(t1, http.StatusOK, w.Code)
+	var sess models.SessionResponse
+	require.NoError(t1, json.NewDecoder(w.Body).Decode(&sess))
+	assert.Equal(t1, "username", sess.Username)
+	assert.NotEmpty(t1, sess.CSRFToken)
+
+	cookies := w.Result().Cookies()
+	require.Len(t1, cookies, 1)
+	cookie := cookies[0]
+	assert.Equal(t1, sessionCookie, cookie.Name)
+	assert.True(t1, cookie.HttpOnly)
+	assert.True(t1, cookie.Secure)
+	assert.Equal(t1, http.SameSiteStrictMode, cookie.SameSite)
+
+	assert.Equal(t1, http.StatusOK, serve("GET", "/user", "", cookie, "").Code, "safe request does not need CSRF token")
+
+	w = serve("GET", "/session", "", cookie, "")
+	assert.Equal(t1, http.StatusOK, w.Code)
+	assert.Contains(t1, w.Body.String(), sess.CSRFToken)
+
+	user := `{"username": "sessionUser", "password": "password", "email": "session@email.com"}`
+	assert.Equal(t1, http.StatusForbidden, serve("POST", "/user", user, cookie, "").Code)
+	assert.Equal(t1, http.StatusForbidden, serve("POST", "/user", user, cookie, "wrong").Code)
+	assert.Equal(t1, http.StatusOK, serve("POST", "/user", user, cookie, sess.CSRFToken).Code)
+
+	assert.Equal(t1, http.StatusForbidden, serve("DELETE", "/session", "", cookie, "").Code)
+	w = serve("DELETE", "/session", "", cookie, sess.CSRFToken)
+	assert.Equal(t1, http.StatusOK, w.Code)
+	assert.Equal(t1, -1, w.Result().Cookies()[0].MaxAge)
+
+	assert.Equal(t1, http.StatusUnauthorized, serve("GET", "/user", "", cookie, "").Code, "session is deleted")
+	assert.Equal(t1, http.StatusUnauthorized, serve("GET", "/session", "", nil, "").Code)
+}
+
+func TestServer_sessionOtherOrganization(t1 *testing.T) {
+	cfg := serverCfg
+	cfg.SessionEnabled = true
+	cfg.SessionTTL = time.Hour
+	server := prepareServerWithConfigs(cfg, serviceCfg)
+
+	serve := func(method, url, body string, cookie *http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		req.SetBasicAuth("username", "password")
+		if cookie != nil {
+			req.Header.Del("Authorization")
+		}
+		w := httptest.NewRecorder()
+		server.httpServer.Handler.ServeHTTP(w, req)
+		return w
+	}
+
+	require.Equal(t1, http.StatusOK, serve("POST", "/organization", `{"name": "acme"}`, nil).Code)
+	require.Equal(t1, http.StatusOK, serve("POST", "/tenant/acme/user", `{"username": "testUser3", "password": "other", "email": "acme@email.com"}`, nil).Code)
+
+	w := httptest.NewRecorder()
+	server.httpServer.Handler.ServeHTTP(w, httptest.NewRequest("POST", "/session", strings.NewReader(`{"username": "testUser3", "password": "password"}`)))
+	require.Equal(t1, http.StatusOK, w.Code)
+	cookie := w.Result().Cookies()[0]
+
+	assert.Equal(t1, http.StatusOK, serve("GET", "/user", "", cookie).Code)
+	assert.Equal(t1, http.StatusUnauthorized, serve("GET", "/tenant/acme/user", "", cookie).Code, "session is bound to its user")
+}
+
+func TestServer_SetSessionStore(t1 *testing.T) {
+	assert.ErrorIs(t1, prepareServer().SetSessionStore(session.NewMemory()), ErrSessionsDisabled)
+
+	cfg := serverCfg
+	cfg.SessionEnabled = true
+	assert.NoError(t1, prepareServerWithConfigs(cfg, serviceCfg).SetSessionStore(session.NewMemory()))
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/KseniiaSalmina/Profiles/internal/config"
+)
+
+// settings are the part of config.Server which can be changed without restart.
+type settings struct {
+	readTimeout      time.Duration
+	writeTimeout     time.Duration
+	requestTimeout   time.Duration
+	trustedProxies   []*net.IPNet
+	rateLimits       rateLimits
+	cors             corsPolicy
+	batchMaxOps      int
+	idempotencyTTL   time.Duration
+	legacySunset     time.Time // zero if sunset of legacy routes is not announced
+	legacyDeprecated time.Time // zero omits Deprecation header of legacy routes
+}
+
+func newSettings(cfg config.Server) (*settings, error) {
+	if cfg.ReadTimeout < 0 || cfg.WriteTimeout < 0 || cfg.RequestTimeout < 0 || cfg.IdempotencyTTL < 0 {
+		return nil, ErrNegativeTimeout
+	}
+
+	if cfg.BatchMaxOperations <= 0 {
+		return nil, ErrNonPositiveBatchLimit
+	}
+
+	legacySunset, err := parseDate(cfg.LegacyRoutesSunset, ErrIncorrectSunset)
+	if err != nil {
+		return nil, err
+	}
+
+	legacyDeprecated, err := parseDate(cfg.LegacyRoutesDeprecated, ErrIncorrectDeprecation)
+	if err != nil {
+		return nil, err
+	}
+
+	trustedProxies, err := parseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+
+	rateLimits, err := newRateLimits(cfg.RateLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &settings{
+		readTimeout:      cfg.ReadTimeout,
+		writeTimeout:     cfg.WriteTimeout,
+		requestTimeout:   cfg.RequestTimeout,
+		trustedProxies:   trustedProxies,
+		rateLimits:       rateLimits,
+		cors:             newCORSPolicy(cfg.CORS),
+		batchMaxOps:      cfg.BatchMaxOperations,
+		idempotencyTTL:   cfg.IdempotencyTTL,
+		legacySunset:     legacySunset,
+		legacyDeprecated: legacyDeprecated,
+	}, nil
+}
+
+// parseDate parses date in YYYY-MM-DD format, empty value is zero time.
+func parseDate(value string, errIncorrect error) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	date, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %s", errIncorrect, value)
+	}
+
+	return date, nil
+}
+
+// Reconfigure validates settings and returns function applying them to running server, nothing is changed on error.
+// Listen addresses and idle timeout are used only on start.
+func (s *Server) Reconfigure(cfg config.Server) (func(), error) {
+	settings, err := newSettings(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return func() {
+		s.settings.Store(settings)
+	}, nil
+}
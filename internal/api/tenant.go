@@ -0,0 +1,47 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+const (
+	tenantHeader     = "X-Tenant"
+	tenantPathPrefix = "/tenant/"
+)
+
+type organizationKey struct{}
+
+// tenant resolves organization from "/tenant/{name}" path prefix or X-Tenant header, default organization is used when neither is set.
+func (s *Server) tenant(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.Header.Get(tenantHeader)
+
+		if rest, ok := strings.CutPrefix(r.URL.Path, tenantPathPrefix); ok {
+			var path string
+			name, path, _ = strings.Cut(rest, "/")
+
+			url := *r.URL
+			url.Path = "/" + path
+			url.RawPath = ""
+			r = r.Clone(r.Context())
+			r.URL = &url
+		}
+
+		orgID, err := s.service.ResolveOrganization(r.Context(), name)
+		if err != nil {
+			s.log(r).WithError(err).Info("tenant middleware, failed to resolve organization")
+			http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), organizationKey{}, orgID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func organizationFromContext(ctx context.Context) string {
+	orgID, _ := ctx.Value(organizationKey{}).(string)
+	return orgID
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KseniiaSalmina/Profiles/internal/api/models"
+)
+
+func TestServer_tenant(t1 *testing.T) {
+	server := prepareServer()
+
+	serve := func(method, url, body, username, password, tenant string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.SetBasicAuth(username, password)
+		if tenant != "" {
+			req.Header.Set(tenantHeader, tenant)
+		}
+		w := httptest.NewRecorder()
+		server.httpServer.Handler.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("POST", "/organization", `{"name":"acme"}`, "testUser3", "password", "")
+	assert.Equal(t1, http.StatusForbidden, w.Code)
+
+	w = serve("POST", "/organization", `{"name":"acme"}`, "username", "password", "")
+	assert.Equal(t1, http.StatusOK, w.Code)
+
+	w = serve("POST", "/tenant/acme/user", `{"email":"admin@acme.com","username":"testUser3","password":"acme","admin":true}`, "username", "password", "")
+	assert.Equal(t1, http.StatusOK, w.Code)
+
+	w = serve("POST", "/tenant/acme/user", `{"email":"root@acme.com","username":"root","password":"acme","super_admin":true}`, "username", "password", "")
+	assert.Equal(t1, http.StatusBadRequest, w.Code)
+
+	w = serve("GET", "/user", "", "testUser3", "acme", "acme")
+	assert.Equal(t1, http.StatusOK, w.Code)
+	var page models.PageUsers
+	assert.NoError(t1, json.NewDecoder(w.Body).Decode(&page))
+	assert.Len(t1, page.Users, 1)
+	assert.Equal(t1, "admin@acme.com", page.Users[0].Email)
+
+	w = serve("GET", "/user/db783cb2-8037-4b75-8c01-ab9065e568e3", "", "testUser3", "acme", "acme")
+	assert.Equal(t1, http.StatusBadRequest, w.Code)
+
+	w = serve("DELETE", "/tenant/acme/user/db783cb2-8037-4b75-8c01-ab9065e568e3", "", "testUser3", "acme", "")
+	assert.Equal(t1, http.StatusBadRequest, w.Code)
+
+	w = serve("GET", "/user", "", "testUser3", "password", "acme")
+	assert.Equal(t1, http.StatusUnauthorized, w.Code)
+
+	w = serve("GET", "/organization", "", "testUser3", "acme", "acme")
+	assert.Equal(t1, http.StatusForbidden, w.Code)
+
+	w = serve("GET", "/user", "", "username", "password", "unknown")
+	assert.Equal(t1, http.StatusNotFound, w.Code)
+}
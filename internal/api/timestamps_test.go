@@ -0,0 +1,86 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KseniiaSalmina/Profiles/internal/api/models"
+	"github.com/KseniiaSalmina/Profiles/internal/service"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func TestServer_timestamps(t1 *testing.T) {
+	server := prepareServer()
+	clock := &testClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	server.service.(*service.Service).SetClock(clock)
+
+	serve := func(method, url, body, username, password string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.SetBasicAuth(username, password)
+		w := httptest.NewRecorder()
+		server.httpServer.Handler.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("POST", "/user", `{"email":"new@email.com","username":"newUser","password":"new"}`, "username", "password")
+	assert.Equal(t1, http.StatusOK, w.Code)
+	var id string
+	assert.NoError(t1, json.NewDecoder(w.Body).Decode(&id))
+	created := clock.now
+
+	clock.now = created.Add(24 * time.Hour)
+	w = serve("PATCH", "/user/"+id, `{"password":"newer"}`, "username", "password")
+	assert.Equal(t1, http.StatusOK, w.Code)
+
+	clock.now = created.Add(48 * time.Hour)
+	w = serve("GET", "/user/"+id, "", "newUser", "newer")
+	assert.Equal(t1, http.StatusOK, w.Code)
+	var user models.UserResponse
+	assert.NoError(t1, json.NewDecoder(w.Body).Decode(&user))
+	assert.Equal(t1, created, user.CreatedAt)
+	assert.Equal(t1, created.Add(24*time.Hour), user.UpdatedAt)
+	assert.Equal(t1, created.Add(24*time.Hour), user.PasswordChangedAt)
+	assert.Equal(t1, created.Add(48*time.Hour), *user.LastLoginAt)
+
+	clock.now = created.Add(48*time.Hour + 30*time.Second)
+	w = serve("GET", "/user/"+id, "", "newUser", "newer")
+	assert.NoError(t1, json.NewDecoder(w.Body).Decode(&user))
+	assert.Equal(t1, created.Add(48*time.Hour), *user.LastLoginAt, "login time is not updated more often than once a minute")
+
+	clock.now = created.Add(48*time.Hour + 2*time.Minute)
+	w = serve("GET", "/user/"+id, "", "newUser", "newer")
+	assert.NoError(t1, json.NewDecoder(w.Body).Decode(&user))
+	assert.Equal(t1, clock.now, *user.LastLoginAt)
+
+	w = serve("GET", "/user?created_after=2024-01-01T00:00:00Z&created_before=2024-01-02T00:00:00Z", "", "username", "password")
+	assert.Equal(t1, http.StatusOK, w.Code)
+	var page models.PageUsers
+	assert.NoError(t1, json.NewDecoder(w.Body).Decode(&page))
+	assert.Len(t1, page.Users, 1)
+	assert.Equal(t1, id, page.Users[0].ID)
+
+	w = serve("GET", "/user?sort=-last_login_at&limit=2", "", "username", "password")
+	assert.Equal(t1, http.StatusOK, w.Code)
+	assert.NoError(t1, json.NewDecoder(w.Body).Decode(&page))
+	assert.Equal(t1, "username", page.Users[0].Username)
+	assert.Equal(t1, id, page.Users[1].ID)
+
+	w = serve("GET", "/user?sort=password", "", "username", "password")
+	assert.Equal(t1, http.StatusBadRequest, w.Code)
+
+	w = serve("GET", "/user?last_login_before=yesterday", "", "username", "password")
+	assert.Equal(t1, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,186 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/KseniiaSalmina/Profiles/internal/config"
+)
+
+var (
+	ErrNoClientCACertificates = errors.New("client CA file contains no certificates")
+	ErrNoVerifiedCertificate  = errors.New("client certificate is not verified")
+	ErrCertificateOrg         = errors.New("client certificate is not issued for organization of the user")
+)
+
+var tlsVersions = map[string]uint16{
+	config.TLSVersion12: tls.VersionTLS12,
+	config.TLSVersion13: tls.VersionTLS13,
+}
+
+// modernCipherSuites are used for TLS 1.2 by modern policy, TLS 1.3 suites are not configurable.
+var modernCipherSuites = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+}
+
+func newTLSConfig(cfg config.TLS, logger *logrus.Logger) (*tls.Config, error) {
+	certificates, err := newCertReloader(cfg.TLSCertFile, cfg.TLSKeyFile, cfg.TLSReloadCheck, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:     tlsVersions[cfg.TLSMinVersion],
+		GetCertificate: certificates.GetCertificate,
+	}
+
+	if cfg.TLSCipherPolicy == config.CipherPolicyModern {
+		tlsConfig.CipherSuites = modernCipherSuites
+	}
+
+	if cfg.TLSClientCAFile != "" {
+		pem, err := os.ReadFile(cfg.TLSClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client CA file: %w", err)
+		}
+
+		tlsConfig.ClientCAs = x509.NewCertPool()
+		if !tlsConfig.ClientCAs.AppendCertsFromPEM(pem) {
+			return nil, ErrNoClientCACertificates
+		}
+
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		if cfg.TLSClientAuth == config.ClientAuthRequire {
+			tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+	}
+
+	return tlsConfig, nil
+}
+
+// certReloader serves certificate from files and loads it again when files are modified,
+// files are checked on handshake at most once per interval.
+type certReloader struct {
+	certFile  string
+	keyFile   string
+	interval  time.Duration
+	logger    *logrus.Logger
+	mutex     sync.Mutex
+	cert      *tls.Certificate
+	modTime   time.Time
+	checkedAt time.Time
+}
+
+func newCertReloader(certFile, keyFile string, interval time.Duration, logger *logrus.Logger) (*certReloader, error) {
+	c := &certReloader{certFile: certFile, keyFile: keyFile, interval: interval, logger: logger}
+
+	modTime, err := c.lastModified()
+	if err != nil {
+		return nil, err
+	}
+	if err := c.load(modTime); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (c *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if time.Since(c.checkedAt) >= c.interval {
+		if err := c.reloadIfModified(); err != nil {
+			c.logger.WithError(err).Error("failed to reload TLS certificate, previous certificate is used")
+		}
+	}
+
+	return c.cert, nil
+}
+
+func (c *certReloader) reloadIfModified() error {
+	c.checkedAt = time.Now()
+
+	modTime, err := c.lastModified()
+	if err != nil {
+		return err
+	}
+	if !modTime.After(c.modTime) {
+		return nil
+	}
+
+	if err := c.load(modTime); err != nil {
+		return err
+	}
+	c.logger.WithField("cert_file", c.certFile).Info("TLS certificate reloaded")
+
+	return nil
+}
+
+func (c *certReloader) load(modTime time.Time) error {
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+
+	c.cert = &cert
+	c.modTime = modTime
+	c.checkedAt = time.Now()
+
+	return nil
+}
+
+func (c *certReloader) lastModified() (time.Time, error) {
+	var last time.Time
+	for _, file := range []string{c.certFile, c.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to check TLS certificate file: %w", err)
+		}
+		if info.ModTime().After(last) {
+			last = info.ModTime()
+		}
+	}
+
+	return last, nil
+}
+
+// clientCertificate returns leaf of the first verified chain, requests without verified client certificate fail.
+func clientCertificate(r *http.Request) (*x509.Certificate, error) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil, ErrNoVerifiedCertificate
+	}
+
+	return r.TLS.VerifiedChains[0][0], nil
+}
+
+// redirectToHTTPS sends plain HTTP requests to the same path on HTTPS listener.
+func redirectToHTTPS(listen string) http.HandlerFunc {
+	_, port, _ := net.SplitHostPort(listen)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.Host)
+		if err != nil {
+			host = r.Host
+		}
+		if port != "" && port != "443" {
+			host = net.JoinHostPort(host, port)
+		}
+
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+	}
+}
@@ -0,0 +1,255 @@
+package api
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/KseniiaSalmina/Profiles/internal/config"
+)
+
+type testCertificate struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// newTestCertificate creates certificate signed by parent, self-signed CA if parent is nil.
+func newTestCertificate(t1 *testing.T, commonName string, serial int64, parent *testCertificate, organizations ...string) *testCertificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t1, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: commonName, Organization: organizations},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t1, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t1, err)
+
+	return &testCertificate{cert: cert, key: key}
+}
+
+func (c *testCertificate) write(t1 *testing.T, dir string) (certFile, keyFile string) {
+	keyDER, err := x509.MarshalECPrivateKey(c.key)
+	require.NoError(t1, err)
+
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	require.NoError(t1, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw}), 0o600))
+	require.NoError(t1, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+
+	return certFile, keyFile
+}
+
+func (c *testCertificate) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.cert.Raw}, PrivateKey: c.key, Leaf: c.cert}
+}
+
+// startMutualTLS serves server over TLS, client certificates signed by ca are verified.
+func startMutualTLS(t1 *testing.T, server *Server, ca *testCertificate, clientAuth string) *httptest.Server {
+	dir := t1.TempDir()
+	certFile, keyFile := newTestCertificate(t1, "server", 2, ca).write(t1, dir)
+	caFile := filepath.Join(dir, "ca.pem")
+	require.NoError(t1, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw}), 0o600))
+
+	tlsConfig, err := newTLSConfig(config.TLS{
+		TLSCertFile:     certFile,
+		TLSKeyFile:      keyFile,
+		TLSMinVersion:   config.TLSVersion12,
+		TLSCipherPolicy: config.CipherPolicyModern,
+		TLSClientCAFile: caFile,
+		TLSClientAuth:   clientAuth,
+	}, server.logger)
+	require.NoError(t1, err)
+
+	// StartTLS would replace served certificate, so listener is wrapped directly
+	ts := httptest.NewUnstartedServer(server.httpServer.Handler)
+	ts.Listener = tls.NewListener(ts.Listener, tlsConfig)
+	ts.Start()
+	t1.Cleanup(ts.Close)
+
+	return ts
+}
+
+// mutualTLSClient trusts server certificates signed by ca and presents clientCert if it is set.
+func mutualTLSClient(ca, clientCert *testCertificate) *http.Client {
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	clientConfig := &tls.Config{RootCAs: roots}
+	if clientCert != nil {
+		clientConfig.Certificates = []tls.Certificate{clientCert.tlsCertificate()}
+	}
+
+	return &http.Client{Transport: &http.Transport{TLSClientConfig: clientConfig}}
+}
+
+func TestServer_mutualTLS(t1 *testing.T) {
+	ca := newTestCertificate(t1, "test CA", 1, nil)
+
+	tests := []struct {
+		name       string
+		clientAuth string
+		clientCert *testCertificate
+		username   string
+		wantCode   int
+		wantErr    bool
+	}{
+		{name: "certificate of existing user", clientAuth: config.ClientAuthOptional, clientCert: newTestCertificate(t1, "testUser3", 3, ca, "default"), wantCode: http.StatusOK},
+		{name: "certificate of unknown user", clientAuth: config.ClientAuthOptional, clientCert: newTestCertificate(t1, "unknown", 4, ca, "default"), wantCode: http.StatusUnauthorized},
+		{name: "certificate without organization", clientAuth: config.ClientAuthOptional, clientCert: newTestCertificate(t1, "testUser3", 6, ca), wantCode: http.StatusUnauthorized},
+		{name: "optional certificate is missing", clientAuth: config.ClientAuthOptional, wantCode: http.StatusUnauthorized},
+		{name: "basic auth without certificate", clientAuth: config.ClientAuthOptional, username: "testUser3", wantCode: http.StatusOK},
+		{name: "required certificate is missing", clientAuth: config.ClientAuthRequire, wantErr: true},
+		{name: "certificate of other CA", clientAuth: config.ClientAuthOptional, clientCert: newTestCertificate(t1, "testUser3", 5, nil, "default"), wantCode: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			ts := startMutualTLS(t1, prepareServer(), ca, tt.clientAuth)
+
+			req, err := http.NewRequest("GET", "https://"+ts.Listener.Addr().String()+"/user", nil)
+			require.NoError(t1, err)
+			if tt.username != "" {
+				req.SetBasicAuth(tt.username, "password")
+			}
+
+			resp, err := mutualTLSClient(ca, tt.clientCert).Do(req)
+			if tt.wantErr {
+				assert.Error(t1, err)
+				return
+			}
+			require.NoError(t1, err)
+			defer resp.Body.Close()
+			assert.Equal(t1, tt.wantCode, resp.StatusCode)
+		})
+	}
+}
+
+func TestServer_mutualTLSTenants(t1 *testing.T) {
+	server := prepareServer()
+	serve := func(method, url, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.SetBasicAuth("username", "password")
+		w := httptest.NewRecorder()
+		server.httpServer.Handler.ServeHTTP(w, req)
+		return w
+	}
+	require.Equal(t1, http.StatusOK, serve("POST", "/organization", `{"name":"acme"}`).Code)
+	require.Equal(t1, http.StatusOK, serve("POST", "/tenant/acme/user", `{"email":"test3@acme.com","username":"testUser3","password":"acme"}`).Code)
+
+	ca := newTestCertificate(t1, "test CA", 1, nil)
+	ts := startMutualTLS(t1, server, ca, config.ClientAuthOptional)
+	client := mutualTLSClient(ca, newTestCertificate(t1, "testUser3", 3, ca, "default"))
+
+	for _, tt := range []struct {
+		name     string
+		path     string
+		wantCode int
+	}{
+		{name: "organization of certificate", path: "/user", wantCode: http.StatusOK},
+		{name: "other organization by path", path: "/tenant/acme/user", wantCode: http.StatusUnauthorized},
+	} {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			resp, err := client.Get("https://" + ts.Listener.Addr().String() + tt.path)
+			require.NoError(t1, err)
+			defer resp.Body.Close()
+			assert.Equal(t1, tt.wantCode, resp.StatusCode)
+		})
+	}
+
+	t1.Run("other organization by header", func(t1 *testing.T) {
+		req, err := http.NewRequest("GET", "https://"+ts.Listener.Addr().String()+"/user", nil)
+		require.NoError(t1, err)
+		req.Header.Set(tenantHeader, "acme")
+		resp, err := client.Do(req)
+		require.NoError(t1, err)
+		defer resp.Body.Close()
+		assert.Equal(t1, http.StatusUnauthorized, resp.StatusCode)
+	})
+}
+
+func TestCertReloader(t1 *testing.T) {
+	dir := t1.TempDir()
+	ca := newTestCertificate(t1, "test CA", 1, nil)
+	certFile, keyFile := newTestCertificate(t1, "server", 2, ca).write(t1, dir)
+
+	reloader, err := newCertReloader(certFile, keyFile, 0, logrus.New())
+	require.NoError(t1, err)
+
+	cert, err := reloader.GetCertificate(nil)
+	require.NoError(t1, err)
+	assert.Equal(t1, int64(2), cert.Leaf.SerialNumber.Int64())
+
+	newTestCertificate(t1, "server", 3, ca).write(t1, dir)
+	later := time.Now().Add(time.Minute)
+	require.NoError(t1, os.Chtimes(certFile, later, later))
+
+	cert, err = reloader.GetCertificate(nil)
+	require.NoError(t1, err)
+	assert.Equal(t1, int64(3), cert.Leaf.SerialNumber.Int64())
+
+	require.NoError(t1, os.WriteFile(certFile, []byte("broken"), 0o600))
+	later = later.Add(time.Minute)
+	require.NoError(t1, os.Chtimes(certFile, later, later))
+
+	cert, err = reloader.GetCertificate(nil)
+	require.NoError(t1, err)
+	assert.Equal(t1, int64(3), cert.Leaf.SerialNumber.Int64(), "previous certificate should be kept")
+}
+
+func TestRedirectToHTTPS(t1 *testing.T) {
+	tests := []struct {
+		name   string
+		listen string
+		host   string
+		want   string
+	}{
+		{name: "custom port", listen: ":8443", host: "example.com:8080", want: "https://example.com:8443/user?pageNo=2"},
+		{name: "default port", listen: ":443", host: "example.com", want: "https://example.com/user?pageNo=2"},
+	}
+
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			req := httptest.NewRequest("POST", "/user?pageNo=2", nil)
+			req.Host = tt.host
+			w := httptest.NewRecorder()
+
+			redirectToHTTPS(tt.listen).ServeHTTP(w, req)
+
+			assert.Equal(t1, http.StatusPermanentRedirect, w.Code)
+			assert.Equal(t1, tt.want, w.Header().Get("Location"))
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestServer_trace(t1 *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t1.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+		_ = provider.Shutdown(context.Background())
+	})
+
+	server := prepareServer()
+	exporter.Reset()
+
+	req := httptest.NewRequest("GET", "/user/"+testUsers[2].ID, nil)
+	req.SetBasicAuth("testUser3", "password")
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+	server.httpServer.Handler.ServeHTTP(w, req)
+	assert.Equal(t1, http.StatusOK, w.Code)
+
+	spans := make(map[string]tracetest.SpanStub)
+	for _, span := range exporter.GetSpans() {
+		assert.Equal(t1, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext.TraceID().String())
+		spans[span.Name] = span
+	}
+
+	root, ok := spans["GET /user/:id"]
+	assert.True(t1, ok)
+	assert.Equal(t1, "00f067aa0ba902b7", root.Parent.SpanID().String())
+
+	for _, name := range []string{"Service.GetAuthData", "bcrypt.CompareHashAndPassword", "Service.GetUserByID"} {
+		assert.Equal(t1, root.SpanContext.SpanID(), spans[name].Parent.SpanID(), name)
+	}
+	assert.Equal(t1, spans["Service.GetUserByID"].SpanContext.SpanID(), spans["Database.GetUserByID"].Parent.SpanID())
+	assert.Equal(t1, spans["Database.GetUserByID"].SpanContext.SpanID(), spans["Database.rlock"].Parent.SpanID())
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/uptrace/bunrouter"
+)
+
+var (
+	ErrIncorrectSunset      = errors.New("incorrect sunset date of legacy routes, expected YYYY-MM-DD")
+	ErrIncorrectDeprecation = errors.New("incorrect deprecation date of legacy routes, expected YYYY-MM-DD")
+)
+
+// apiVersion is one version of API under its path prefix. Versions register the same handlers,
+// a new version changes only DTOs its clients see: view converts values handlers respond with,
+// e.g. models.UserResponse into DTO of the version.
+type apiVersion struct {
+	prefix string
+	view   func(value any) any // nil writes values of handlers as is
+}
+
+var v1 = apiVersion{prefix: "/v1"}
+
+type versionKey struct{}
+
+// versioned saves version of matched route, respond writes DTOs of this version.
+func (s *Server) versioned(version apiVersion) bunrouter.MiddlewareFunc {
+	return func(next bunrouter.HandlerFunc) bunrouter.HandlerFunc {
+		return func(w http.ResponseWriter, req bunrouter.Request) error {
+			return next(w, req.WithContext(context.WithValue(req.Context(), versionKey{}, version)))
+		}
+	}
+}
+
+// deprecated marks responses of legacy routes (RFC 9745 and RFC 8594) and links the same route of successor version.
+func (s *Server) deprecated(successor apiVersion) bunrouter.MiddlewareFunc {
+	return func(next bunrouter.HandlerFunc) bunrouter.HandlerFunc {
+		return func(w http.ResponseWriter, req bunrouter.Request) error {
+			settings := s.settings.Load()
+			if deprecated := settings.legacyDeprecated; !deprecated.IsZero() {
+				w.Header().Set("Deprecation", fmt.Sprintf("@%d", deprecated.Unix()))
+			}
+			if sunset := settings.legacySunset; !sunset.IsZero() {
+				w.Header().Set("Sunset", sunset.Format(http.TimeFormat))
+			}
+			w.Header().Add("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, successorPath(req.Request, successor)))
+
+			return next(w, req)
+		}
+	}
+}
+
+// successorPath inserts version prefix after tenant path prefix, which is already cut from URL by tenant middleware.
+func successorPath(r *http.Request, version apiVersion) string {
+	requested, _, _ := strings.Cut(r.RequestURI, "?")
+	tenantPrefix, ok := strings.CutSuffix(requested, r.URL.Path)
+	if !ok {
+		tenantPrefix = ""
+	}
+
+	return tenantPrefix + version.prefix + r.URL.Path
+}
+
+func versionFromContext(ctx context.Context) apiVersion {
+	version, _ := ctx.Value(versionKey{}).(apiVersion)
+	return version
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/KseniiaSalmina/Profiles/internal/api/models"
+)
+
+func TestServer_versions(t1 *testing.T) {
+	serve := func(server *Server, url string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", url, nil)
+		req.SetBasicAuth("username", "password")
+		w := httptest.NewRecorder()
+		server.httpServer.Handler.ServeHTTP(w, req)
+		return w
+	}
+
+	server := prepareServer()
+
+	t1.Run("versioned route", func(t1 *testing.T) {
+		w := serve(server, "/v1/user/"+testUsers[2].ID)
+		require.Equal(t1, http.StatusOK, w.Code)
+		assert.Empty(t1, w.Header().Get("Deprecation"))
+		assert.Empty(t1, w.Header().Get("Sunset"))
+		assert.Contains(t1, w.Body.String(), "testUser3")
+	})
+
+	t1.Run("legacy route", func(t1 *testing.T) {
+		w := serve(server, "/user/"+testUsers[2].ID+"?fields=username")
+		require.Equal(t1, http.StatusOK, w.Code)
+		assert.Equal(t1, "@1792368000", w.Header().Get("Deprecation"))
+		assert.Equal(t1, "Fri, 30 Apr 2027 00:00:00 GMT", w.Header().Get("Sunset"))
+		assert.Equal(t1, `</v1/user/`+testUsers[2].ID+`>; rel="successor-version"`, w.Header().Get("Link"))
+		assert.JSONEq(t1, `{"username": "testUser3"}`, w.Body.String())
+	})
+
+	t1.Run("legacy route with tenant prefix", func(t1 *testing.T) {
+		w := serve(server, "/tenant/default/group")
+		require.Equal(t1, http.StatusOK, w.Code)
+		assert.Equal(t1, `</tenant/default/v1/group>; rel="successor-version"`, w.Header().Get("Link"))
+	})
+
+	t1.Run("probes are not versioned", func(t1 *testing.T) {
+		assert.Equal(t1, http.StatusOK, serve(server, "/healthz").Code)
+		assert.Equal(t1, http.StatusNotFound, serve(server, "/v1/healthz").Code)
+	})
+
+	t1.Run("legacy routes without deprecation and sunset dates", func(t1 *testing.T) {
+		cfg := serverCfg
+		cfg.LegacyRoutesSunset = ""
+		cfg.LegacyRoutesDeprecated = ""
+		server := prepareServerWithConfigs(cfg, serviceCfg)
+
+		w := serve(server, "/user/"+testUsers[2].ID)
+		require.Equal(t1, http.StatusOK, w.Code)
+		assert.Empty(t1, w.Header().Get("Deprecation"))
+		assert.Empty(t1, w.Header().Get("Sunset"))
+		assert.NotEmpty(t1, w.Header().Get("Link"))
+	})
+
+	t1.Run("legacy routes disabled", func(t1 *testing.T) {
+		cfg := serverCfg
+		cfg.LegacyRoutes = false
+		server := prepareServerWithConfigs(cfg, serviceCfg)
+
+		assert.Equal(t1, http.StatusNotFound, serve(server, "/user/"+testUsers[2].ID).Code)
+		assert.Equal(t1, http.StatusOK, serve(server, "/v1/user/"+testUsers[2].ID).Code)
+	})
+}
+
+func TestServer_respondVersionView(t1 *testing.T) {
+	server := prepareServer()
+	v2 := apiVersion{prefix: "/v2", view: func(value any) any {
+		if user, ok := value.(*models.UserResponse); ok {
+			return map[string]string{"login": user.Username}
+		}
+		return value
+	}}
+
+	req := httptest.NewRequest("GET", "/v2/user/"+testUsers[2].ID, nil)
+	req = req.WithContext(context.WithValue(req.Context(), versionKey{}, v2))
+	w := httptest.NewRecorder()
+	server.respond(w, req, http.StatusOK, &models.UserResponse{Username: "testUser3"})
+
+	assert.Equal(t1, http.StatusOK, w.Code)
+	assert.JSONEq(t1, `{"login": "testUser3"}`, w.Body.String())
+}
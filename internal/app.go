@@ -1,32 +1,51 @@
 package app
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
+	"reflect"
 	"syscall"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
 	"github.com/KseniiaSalmina/Profiles/internal/api"
+	"github.com/KseniiaSalmina/Profiles/internal/blobstore"
 	"github.com/KseniiaSalmina/Profiles/internal/config"
 	"github.com/KseniiaSalmina/Profiles/internal/database"
 	"github.com/KseniiaSalmina/Profiles/internal/logger"
+	"github.com/KseniiaSalmina/Profiles/internal/metrics"
+	"github.com/KseniiaSalmina/Profiles/internal/notifier"
 	"github.com/KseniiaSalmina/Profiles/internal/service"
+	"github.com/KseniiaSalmina/Profiles/internal/tracing"
 )
 
 type Application struct {
-	cfg     config.Application
-	db      *database.Database
-	service *service.Service
-	logger  *logrus.Logger
-	server  *api.Server
-	closeCh chan os.Signal
+	cfg       config.Application
+	loader    *config.Loader // reads config again on reload
+	db        *database.Database
+	blobStore *blobstore.Local
+	service   *service.Service
+	logger    *logrus.Logger
+	metrics   *metrics.Metrics
+	tracing   *tracing.Provider
+	server    *api.Server
+	closeCh   chan os.Signal
+	reloadCh  chan os.Signal
+	ctx       context.Context // cancelled on stop, background jobs give up their work
+	cancel    context.CancelFunc
 }
 
-func NewApplication(cfg config.Application) (*Application, error) {
+func NewApplication(cfg config.Application, loader *config.Loader) (*Application, error) {
+	ctx, cancel := context.WithCancel(context.Background())
 	app := Application{
-		cfg: cfg,
+		cfg:    cfg,
+		loader: loader,
+		ctx:    ctx,
+		cancel: cancel,
 	}
 
 	if err := app.bootstrap(); err != nil {
@@ -34,32 +53,73 @@ func NewApplication(cfg config.Application) (*Application, error) {
 	}
 
 	app.readyToShutdown()
+	app.readyToReload()
 
 	return &app, nil
 }
 
 func (a *Application) bootstrap() error {
+	if err := a.initLogger(); err != nil {
+		return err
+	}
+
+	a.initMetrics()
+
+	if err := a.initTracing(); err != nil {
+		return err
+	}
+
 	a.initDatabase()
 
+	if err := a.initBlobStore(); err != nil {
+		return err
+	}
+
 	if err := a.initService(); err != nil {
 		return err
 	}
 
-	if err := a.initLogger(); err != nil {
+	if err := a.initServer(); err != nil {
 		return err
 	}
 
-	a.initServer()
+	return nil
+}
 
+func (a *Application) initMetrics() {
+	a.metrics = metrics.NewMetrics()
+}
+
+func (a *Application) initTracing() error {
+	provider, err := tracing.NewProvider(a.cfg.Tracing)
+	if err != nil {
+		return fmt.Errorf("failed to init tracing: %w", err)
+	}
+
+	a.tracing = provider
 	return nil
 }
 
 func (a *Application) initDatabase() {
 	a.db = database.NewDatabase()
+	a.metrics.RegisterUserCount(func() int {
+		count, _ := a.db.CountUsers(context.Background(), database.UserFilter{})
+		return count
+	})
+}
+
+func (a *Application) initBlobStore() error {
+	store, err := blobstore.NewLocal(a.cfg.BlobStorePath)
+	if err != nil {
+		return fmt.Errorf("failed to init blob store: %w", err)
+	}
+
+	a.blobStore = store
+	return nil
 }
 
 func (a *Application) initService() error {
-	service, err := service.NewService(a.cfg.Service, a.db)
+	service, err := service.NewService(a.ctx, a.cfg.Service, metrics.NewStorage(a.db, a.metrics), a.blobStore, notifier.NewLog(a.logger))
 	if err != nil {
 		return fmt.Errorf("failed to init service: %w", err)
 	}
@@ -79,26 +139,185 @@ func (a *Application) initLogger() error {
 	return nil
 }
 
-func (a *Application) initServer() {
-	a.server = api.NewServer(a.cfg.Server, a.service, a.logger)
+func (a *Application) initServer() error {
+	server, err := api.NewServer(a.cfg.Server, a.service, a.logger, a.metrics)
+	if err != nil {
+		return fmt.Errorf("failed to init server: %w", err)
+	}
+
+	a.server = server
+	a.server.AddHealthCheck("storage", a.db)
+	a.server.AddHealthCheck("blob_store", a.blobStore)
+	a.server.AddHealthCheck("bootstrap", a.service)
+
+	return nil
 }
 
 func (a *Application) Run() {
 	defer a.stop()
 
 	a.server.Run()
+	go a.runInactivityPolicy()
+
+	for {
+		select {
+		case <-a.reloadCh:
+			a.reload()
+		case <-a.closeCh:
+			return
+		}
+	}
+}
+
+// reload applies new logger, server settings and password policy without restart, in-memory data is kept.
+// Config is applied only if all its parts are valid.
+func (a *Application) reload() {
+	cfg, err := a.loader.Load()
+	if err != nil {
+		a.logger.WithError(err).Error("config reload rejected")
+		return
+	}
+
+	applyLogger, loggerErr := logger.Reconfigure(a.logger, cfg.Logger)
+	applyServer, serverErr := a.server.Reconfigure(cfg.Server)
+	if err := errors.Join(loggerErr, serverErr); err != nil {
+		a.logger.WithError(err).Error("config reload rejected")
+		return
+	}
 
-	<-a.closeCh
+	applyLogger()
+	applyServer()
+	a.service.SetPasswordPolicy(cfg.PasswordPolicy)
+
+	if restart := a.restartRequired(cfg); len(restart) != 0 {
+		a.logger.WithField("settings", restart).Warn("config reload, changed settings are applied only after restart")
+	}
+
+	a.cfg.Logger = cfg.Logger
+	a.cfg.ReadTimeout = cfg.ReadTimeout
+	a.cfg.WriteTimeout = cfg.WriteTimeout
+	a.cfg.RequestTimeout = cfg.RequestTimeout
+	a.cfg.TrustedProxies = cfg.TrustedProxies
+	a.cfg.DrainDelay = cfg.DrainDelay
+	a.cfg.IdempotencyTTL = cfg.IdempotencyTTL
+	a.cfg.BatchMaxOperations = cfg.BatchMaxOperations
+	a.cfg.LegacyRoutesSunset = cfg.LegacyRoutesSunset
+	a.cfg.LegacyRoutesDeprecated = cfg.LegacyRoutesDeprecated
+	a.cfg.RateLimit = cfg.RateLimit
+	a.cfg.CORS = cfg.CORS
+	a.cfg.PasswordPolicy = cfg.PasswordPolicy
+
+	a.logger.WithFields(logrus.Fields{
+		"log_level":       cfg.LogLevel,
+		"log_format":      cfg.LogFormat,
+		"read_timeout":    cfg.ReadTimeout.String(),
+		"write_timeout":   cfg.WriteTimeout.String(),
+		"request_timeout": cfg.RequestTimeout.String(),
+		"drain_delay":     cfg.DrainDelay.String(),
+	}).Info("config reloaded")
+}
+
+// restartRequired lists changed settings which running application can't apply.
+func (a *Application) restartRequired(cfg config.Application) []string {
+	changed := make([]string, 0)
+
+	if cfg.Mode != a.cfg.Mode {
+		changed = append(changed, "mode")
+	}
+	if cfg.Listen != a.cfg.Listen || cfg.MetricsListen != a.cfg.MetricsListen || cfg.IdleTimeout != a.cfg.IdleTimeout {
+		changed = append(changed, "server listen addresses and idle timeout")
+	}
+	if cfg.LegacyRoutes != a.cfg.LegacyRoutes {
+		changed = append(changed, "server legacy routes")
+	}
+	if cfg.TLS != a.cfg.TLS {
+		changed = append(changed, "server TLS")
+	}
+	if cfg.Session != a.cfg.Session {
+		changed = append(changed, "server sessions")
+	}
+	// Secrets re-read from files and keystore are not applied: salt is part of stored password hashes
+	// and admin credentials only create the first admin.
+	if cfg.Salt != a.cfg.Salt {
+		changed = append(changed, "service salt")
+	}
+	if cfg.AdminUsername != a.cfg.AdminUsername || cfg.AdminPassword != a.cfg.AdminPassword || cfg.AdminEmail != a.cfg.AdminEmail {
+		changed = append(changed, "first admin credentials")
+	}
+	service := cfg.Service
+	service.Salt, service.AdminUsername, service.AdminPassword, service.AdminEmail = a.cfg.Salt, a.cfg.AdminUsername, a.cfg.AdminPassword, a.cfg.AdminEmail
+	service.PasswordPolicy = a.cfg.PasswordPolicy
+	if !reflect.DeepEqual(service, a.cfg.Service) {
+		changed = append(changed, "service")
+	}
+	if cfg.BlobStore != a.cfg.BlobStore {
+		changed = append(changed, "blob store")
+	}
+	if cfg.Tracing != a.cfg.Tracing {
+		changed = append(changed, "tracing")
+	}
+
+	return changed
+}
+
+func (a *Application) runInactivityPolicy() {
+	policy := a.cfg.Inactivity
+	if policy.InactivityCheckInterval <= 0 || (policy.InactivityWarnAfterDays <= 0 && policy.InactivityDisableAfterDays <= 0) {
+		return
+	}
+
+	ticker := time.NewTicker(policy.InactivityCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		actions, err := a.service.ApplyInactivityPolicy(a.ctx)
+		if err != nil {
+			a.logger.WithError(err).Error("inactivity policy, failed to apply some actions")
+		}
+		for _, action := range actions {
+			a.logger.WithFields(logrus.Fields{
+				"user_id":       action.UserID,
+				"username":      action.Username,
+				"action":        action.Action,
+				"inactive_days": action.InactiveDays,
+			}).Info("inactivity policy applied")
+		}
+
+		select {
+		case <-ticker.C:
+		case <-a.ctx.Done():
+			return
+		}
+	}
 }
 
 func (a *Application) stop() {
+	a.server.Drain()
+	a.cancel()
+	a.service.WaitImports()
+
+	a.logger.Infof("readiness is failing, waiting %s for traffic to drain", a.cfg.DrainDelay)
+	time.Sleep(a.cfg.DrainDelay)
+
 	if err := a.server.Shutdown(); err != nil {
 		a.logger.Infof("server stopped: %s", err.Error())
 	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := a.tracing.Shutdown(ctx); err != nil {
+		a.logger.WithError(err).Error("failed to flush traces")
+	}
 }
 
 func (a *Application) readyToShutdown() {
 	ch := make(chan os.Signal, 1)
-	signal.Notify(ch, syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
+	signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
 	a.closeCh = ch
 }
+
+func (a *Application) readyToReload() {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGHUP)
+	a.reloadCh = ch
+}
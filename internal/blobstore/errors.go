@@ -0,0 +1,7 @@
+package blobstore
+
+import "errors"
+
+var ErrBlobDoesNotExist = errors.New("blob does not exist")
+var ErrIncorrectKey = errors.New("incorrect blob key")
+var ErrRootIsNotDirectory = errors.New("blob store root is not a directory")
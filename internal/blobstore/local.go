@@ -0,0 +1,164 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// tmpPrefix marks files of blobs being written.
+const tmpPrefix = ".tmp-"
+
+type Local struct {
+	root string
+}
+
+func NewLocal(root string) (*Local, error) {
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create blob store directory: %w", err)
+	}
+
+	return &Local{root: root}, nil
+}
+
+func (l *Local) Put(key string, r io.Reader) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("failed to put blob: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), tmpPrefix+"*")
+	if err != nil {
+		return fmt.Errorf("failed to put blob: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to put blob: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to put blob: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to put blob: %w", err)
+	}
+
+	return nil
+}
+
+func (l *Local) Get(key string) (io.ReadSeekCloser, time.Time, error) {
+	path, err := l.path(key)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, time.Time{}, ErrBlobDoesNotExist
+		}
+		return nil, time.Time{}, fmt.Errorf("failed to get blob: %w", err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, time.Time{}, fmt.Errorf("failed to get blob: %w", err)
+	}
+
+	return f, info.ModTime(), nil
+}
+
+// List returns keys of blobs under prefix, blobs being written are not listed.
+func (l *Local) List(prefix string) ([]string, error) {
+	path, err := l.path(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	err = filepath.WalkDir(path, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), tmpPrefix) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(l.root, name)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to list blobs: %w", err)
+	}
+
+	return keys, nil
+}
+
+// Delete removes blob, missing blob is not an error.
+func (l *Local) Delete(key string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete blob: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteAll removes every blob which key starts with prefix, missing prefix is not an error.
+func (l *Local) DeleteAll(prefix string) error {
+	path, err := l.path(prefix)
+	if err != nil {
+		return err
+	}
+
+	if err := os.RemoveAll(path); err != nil {
+		return fmt.Errorf("failed to delete blobs: %w", err)
+	}
+
+	return nil
+}
+
+func (l *Local) path(key string) (string, error) {
+	clean := filepath.Clean("/" + key)
+	if clean == "/" || strings.Contains(key, "..") {
+		return "", ErrIncorrectKey
+	}
+
+	return filepath.Join(l.root, clean), nil
+}
+
+// HealthCheck reports whether root directory of the store is still available.
+func (l *Local) HealthCheck(ctx context.Context) error {
+	info, err := os.Stat(l.root)
+	if err != nil {
+		return fmt.Errorf("failed to check blob store: %w", err)
+	}
+
+	if !info.IsDir() {
+		return fmt.Errorf("failed to check blob store: %w", ErrRootIsNotDirectory)
+	}
+
+	return nil
+}
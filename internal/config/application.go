@@ -1,7 +1,16 @@
 package config
 
+const (
+	ModeDevelopment = "development"
+	ModeProduction  = "production" // refuses to start with default salt and admin password
+)
+
 type Application struct {
-	Server
-	Service
-	Logger
+	Mode      string `env:"APP_MODE" envDefault:"development" yaml:"mode"`
+	Server    `yaml:"server"`
+	Service   `yaml:"service"`
+	Logger    `yaml:"logger"`
+	BlobStore `yaml:"blob_store"`
+	Tracing   `yaml:"tracing"`
+	Keystore  `yaml:"keystore"`
 }
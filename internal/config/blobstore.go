@@ -0,0 +1,5 @@
+package config
+
+type BlobStore struct {
+	BlobStorePath string `env:"BLOB_STORE_PATH" envDefault:"./data/blobs" yaml:"path"`
+}
@@ -0,0 +1,13 @@
+package config
+
+import "time"
+
+// CORS is disabled when no origins are allowed.
+type CORS struct {
+	CORSAllowedOrigins   []string      `env:"SERVER_CORS_ALLOWED_ORIGINS" envSeparator:"," yaml:"allowed_origins"` // "*" allows any origin
+	CORSAllowedMethods   []string      `env:"SERVER_CORS_ALLOWED_METHODS" envSeparator:"," envDefault:"GET,POST,PUT,PATCH,DELETE" yaml:"allowed_methods"`
+	CORSAllowedHeaders   []string      `env:"SERVER_CORS_ALLOWED_HEADERS" envSeparator:"," envDefault:"Authorization,Content-Type,X-Tenant,X-Request-ID,X-API-Key,X-CSRF-Token,Idempotency-Key" yaml:"allowed_headers"`
+	CORSExposedHeaders   []string      `env:"SERVER_CORS_EXPOSED_HEADERS" envSeparator:"," envDefault:"X-Request-ID,RateLimit-Limit,RateLimit-Remaining,RateLimit-Reset,Retry-After,Idempotent-Replayed,Deprecation,Sunset,Link" yaml:"exposed_headers"`
+	CORSAllowCredentials bool          `env:"SERVER_CORS_ALLOW_CREDENTIALS" envDefault:"false" yaml:"allow_credentials"`
+	CORSMaxAge           time.Duration `env:"SERVER_CORS_MAX_AGE" envDefault:"10m" yaml:"max_age"` // how long browser caches preflight response
+}
@@ -0,0 +1,27 @@
+package config
+
+import "errors"
+
+var (
+	ErrUnknownFileFormat       = errors.New("unknown config file format, expected .yaml, .yml or .toml")
+	ErrUnknownKey              = errors.New("unknown config file key")
+	ErrEmptyValue              = errors.New("value is required")
+	ErrNegativeValue           = errors.New("value can not be negative")
+	ErrNotPositiveValue        = errors.New("value should be positive")
+	ErrUnknownValue            = errors.New("unknown value")
+	ErrOutOfRange              = errors.New("value is out of range")
+	ErrIncorrectProxy          = errors.New("incorrect trusted proxy, expected IP address or CIDR network")
+	ErrIncorrectDate           = errors.New("incorrect date, expected YYYY-MM-DD")
+	ErrInsecureDefault         = errors.New("default value is not allowed in production mode")
+	ErrTLSPair                 = errors.New("certificate and key files should be set together")
+	ErrTLSDisabled             = errors.New("setting requires TLS certificate and key files")
+	ErrCORSWildcardCredentials = errors.New(`"*" origin can not be used with credentials`)
+)
+
+var (
+	ErrKeystoreNotConfigured  = errors.New("secret refers to keystore, but KEYSTORE_PATH is not set")
+	ErrEmptyPassphrase        = errors.New("keystore passphrase is required")
+	ErrKeystoreDecryption     = errors.New("failed to decrypt keystore, wrong passphrase or damaged file")
+	ErrUnknownKeystoreVersion = errors.New("unknown keystore version")
+	ErrSecretDoesNotExist     = errors.New("secret does not exist")
+)
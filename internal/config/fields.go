@@ -0,0 +1,102 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const redacted = "[redacted]"
+
+// field describes one configuration value: its environment variable, config file path and command line flag.
+type field struct {
+	env       string
+	path      []string
+	separator string
+	secret    bool // also read from NAME_FILE and NAME_KEYSTORE
+}
+
+func (f field) key() string {
+	return strings.Join(f.path, ".")
+}
+
+func (f field) flag() string {
+	return strings.ToLower(strings.ReplaceAll(f.env, "_", "-"))
+}
+
+// sources lists the field itself and, for secrets, its indirect variants which have the same form of names.
+func (f field) sources() []field {
+	if !f.secret {
+		return []field{f}
+	}
+
+	result := []field{f}
+	for _, suffix := range []string{fileSuffix, keystoreSuffix} {
+		if f.env == keystorePassphraseEnv && suffix == keystoreSuffix {
+			continue
+		}
+		path := append([]string(nil), f.path...)
+		path[len(path)-1] += strings.ToLower(suffix)
+		result = append(result, field{env: f.env + suffix, path: path, separator: f.separator})
+	}
+
+	return result
+}
+
+// fields lists all values of Application, nested structs are config file sections.
+func fields() []field {
+	return collectFields(reflect.TypeOf(Application{}), nil)
+}
+
+func collectFields(t reflect.Type, path []string) []field {
+	result := make([]field, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		fieldPath := append(append([]string(nil), path...), f.Tag.Get("yaml"))
+
+		name, ok := f.Tag.Lookup("env")
+		if !ok {
+			if f.Type.Kind() == reflect.Struct {
+				result = append(result, collectFields(f.Type, fieldPath)...)
+			}
+			continue
+		}
+
+		separator := f.Tag.Get("envSeparator")
+		if separator == "" {
+			separator = ","
+		}
+		result = append(result, field{env: name, path: fieldPath, separator: separator, secret: f.Tag.Get("secret") == "true"})
+	}
+
+	return result
+}
+
+// formatValue converts config file value to the environment variable form.
+func formatValue(value any, separator string) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, formatValue(item, separator))
+		}
+		return strings.Join(items, separator)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// redact replaces non-empty values of fields tagged as secret.
+func redact(v reflect.Value) {
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		switch {
+		case f.Kind() == reflect.Struct:
+			redact(f)
+		case v.Type().Field(i).Tag.Get("secret") == "true" && f.Kind() == reflect.String && f.String() != "":
+			f.SetString(redacted)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	keystorePathEnv       = "KEYSTORE_PATH"
+	keystorePassphraseEnv = "KEYSTORE_PASSPHRASE"
+
+	keystoreVersion = 1
+	keyLength       = 32 // AES-256
+	saltLength      = 16
+)
+
+type Keystore struct {
+	KeystorePath       string `env:"KEYSTORE_PATH" yaml:"path"`
+	KeystorePassphrase string `env:"KEYSTORE_PASSPHRASE" yaml:"passphrase" secret:"true"`
+}
+
+// Put reads secret from r and saves it to keystore under the name.
+func (k Keystore) Put(name string, r io.Reader) error {
+	if k.KeystorePath == "" {
+		return fmt.Errorf("%s: %w", keystorePathEnv, ErrEmptyValue)
+	}
+
+	secret, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("failed to read secret: %w", err)
+	}
+
+	ks, err := OpenKeystore(k.KeystorePath, k.KeystorePassphrase)
+	if err != nil {
+		return err
+	}
+	ks.SetSecret(name, strings.TrimSpace(string(secret)))
+
+	return ks.Save()
+}
+
+// keystoreFile is stored on disk, secrets are encrypted with AES-GCM by a key derived from passphrase with scrypt.
+type keystoreFile struct {
+	Version int    `json:"version"`
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+// LocalKeystore is a file with named secrets encrypted by passphrase.
+type LocalKeystore struct {
+	path       string
+	passphrase string
+	secrets    map[string]string
+}
+
+// OpenKeystore decrypts keystore file, missing file is an empty keystore which is created on Save.
+func OpenKeystore(path, passphrase string) (*LocalKeystore, error) {
+	if passphrase == "" {
+		return nil, ErrEmptyPassphrase
+	}
+
+	ks := &LocalKeystore{path: path, passphrase: passphrase, secrets: make(map[string]string)}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return ks, nil
+		}
+		return nil, fmt.Errorf("failed to read keystore: %w", err)
+	}
+
+	var file keystoreFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse keystore: %w", err)
+	}
+	if file.Version != keystoreVersion {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownKeystoreVersion, file.Version)
+	}
+
+	aead, err := newAEAD(passphrase, file.Salt)
+	if err != nil {
+		return nil, err
+	}
+
+	plain, err := aead.Open(nil, file.Nonce, file.Data, nil)
+	if err != nil {
+		return nil, ErrKeystoreDecryption
+	}
+
+	if err := json.Unmarshal(plain, &ks.secrets); err != nil {
+		return nil, fmt.Errorf("failed to parse keystore secrets: %w", err)
+	}
+
+	return ks, nil
+}
+
+func (k *LocalKeystore) Secret(name string) (string, error) {
+	secret, ok := k.secrets[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrSecretDoesNotExist, name)
+	}
+
+	return secret, nil
+}
+
+func (k *LocalKeystore) SetSecret(name, secret string) {
+	k.secrets[name] = secret
+}
+
+// Save encrypts secrets with new salt and nonce and replaces keystore file.
+func (k *LocalKeystore) Save() error {
+	plain, err := json.Marshal(k.secrets)
+	if err != nil {
+		return fmt.Errorf("failed to encode keystore secrets: %w", err)
+	}
+
+	file := keystoreFile{Version: keystoreVersion, Salt: make([]byte, saltLength)}
+	if _, err := rand.Read(file.Salt); err != nil {
+		return fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	aead, err := newAEAD(k.passphrase, file.Salt)
+	if err != nil {
+		return err
+	}
+
+	file.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(file.Nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	file.Data = aead.Seal(nil, file.Nonce, plain, nil)
+
+	data, err := json.Marshal(file)
+	if err != nil {
+		return fmt.Errorf("failed to encode keystore: %w", err)
+	}
+
+	tmp := k.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write keystore: %w", err)
+	}
+	if err := os.Rename(tmp, k.path); err != nil {
+		return fmt.Errorf("failed to replace keystore: %w", err)
+	}
+
+	return nil
+}
+
+func newAEAD(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, keyLength)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive keystore key: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create keystore cipher: %w", err)
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,234 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/caarlos0/env/v6"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	envFile       = ".env"
+	configFileEnv = "CONFIG_FILE"
+)
+
+// legacyNames maps previous names of environment variables to current ones, current name wins if both are set.
+var legacyNames = map[string]string{
+	"DB_USERNAME":  "ADMIN_USERNAME",
+	"DB_PASS":      "ADMIN_PASSWORD",
+	"DB_PASS_FILE": "ADMIN_PASSWORD_FILE",
+	"DB_Email":     "ADMIN_EMAIL",
+}
+
+// Loader builds configuration from sources in order of increasing priority:
+// defaults, config file, .env file, process environment and command line flags.
+type Loader struct {
+	configFile  string
+	flags       map[string]string
+	PrintConfig bool
+	KeystorePut string // name of the secret to read from stdin and put to keystore
+}
+
+// NewLoader parses command line arguments, every environment variable has a flag with lowercase name, e.g. --server-listen.
+func NewLoader(args []string) (*Loader, error) {
+	l := &Loader{flags: make(map[string]string)}
+
+	set := flag.NewFlagSet("profiles", flag.ContinueOnError)
+	set.StringVar(&l.configFile, "config", "", "path to .yaml, .yml or .toml config file, "+configFileEnv+" environment variable is used if not set")
+	set.BoolVar(&l.PrintConfig, "print-config", false, "print effective config with redacted secrets and exit")
+	set.StringVar(&l.KeystorePut, "keystore-put", "", "read secret with given name from stdin, put it to "+keystorePathEnv+" keystore and exit")
+
+	envByFlag := make(map[string]string)
+	for _, f := range fields() {
+		for _, source := range f.sources() {
+			set.String(source.flag(), "", "overrides "+source.env)
+			envByFlag[source.flag()] = source.env
+		}
+	}
+
+	if err := set.Parse(args); err != nil {
+		return nil, fmt.Errorf("failed to parse flags: %w", err)
+	}
+
+	set.Visit(func(f *flag.Flag) {
+		if name, ok := envByFlag[f.Name]; ok {
+			l.flags[name] = f.Value.String()
+		}
+	})
+
+	return l, nil
+}
+
+// Load reads all configuration sources, resolves secrets and validates the result.
+// Process environment is not changed, so the next call sees updated files and secrets.
+func (l *Loader) Load() (Application, error) {
+	environment, err := l.environment()
+	if err != nil {
+		return Application{}, err
+	}
+
+	if err := resolveSecrets(environment); err != nil {
+		return Application{}, fmt.Errorf("failed to resolve secrets: %w", err)
+	}
+
+	var cfg Application
+	if err := env.Parse(&cfg, env.Options{Environment: environment}); err != nil {
+		return Application{}, fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Application{}, fmt.Errorf("invalid config: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// LoadKeystore reads only keystore path and passphrase, other secrets may refer to keystore secrets which are not put yet.
+func (l *Loader) LoadKeystore() (Keystore, error) {
+	environment, err := l.environment()
+	if err != nil {
+		return Keystore{}, err
+	}
+
+	secrets := secretResolver{env: EnvSecrets(environment), file: FileSecrets{}}
+	passphrase, _, err := secrets.resolve(keystorePassphraseEnv)
+	if err != nil {
+		return Keystore{}, fmt.Errorf("failed to resolve secrets: %s: %w", keystorePassphraseEnv, err)
+	}
+
+	return Keystore{KeystorePath: environment[keystorePathEnv], KeystorePassphrase: passphrase}, nil
+}
+
+// environment merges configuration sources into variables, secrets are not resolved.
+func (l *Loader) environment() (map[string]string, error) {
+	environment := make(map[string]string)
+
+	configFile := l.configFile
+	if configFile == "" {
+		configFile = os.Getenv(configFileEnv)
+	}
+	if configFile != "" {
+		if err := readConfigFile(configFile, environment); err != nil {
+			return nil, err
+		}
+	}
+
+	dotenv, err := godotenv.Read(envFile)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read %s: %w", envFile, err)
+	}
+	merge(environment, renameLegacy(dotenv))
+
+	process := make(map[string]string)
+	for _, variable := range os.Environ() {
+		key, value, _ := strings.Cut(variable, "=")
+		process[key] = value
+	}
+	merge(environment, renameLegacy(process))
+
+	merge(environment, l.flags)
+
+	return environment, nil
+}
+
+// Print writes config in config file format, secrets are redacted.
+func (a Application) Print(w io.Writer) error {
+	redact(reflect.ValueOf(&a).Elem())
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(a); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	return encoder.Close()
+}
+
+// readConfigFile puts config file values to environment under their environment variable names.
+func readConfigFile(path string, environment map[string]string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	values := make(map[string]any)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &values)
+	case ".toml":
+		err = toml.Unmarshal(data, &values)
+	default:
+		return fmt.Errorf("%s: %w", path, ErrUnknownFileFormat)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	flat := make(map[string]any)
+	flatten(values, "", flat)
+
+	known := make(map[string]field)
+	for _, f := range fields() {
+		for _, source := range f.sources() {
+			known[source.key()] = source
+		}
+	}
+
+	var errs []error
+	for key, value := range flat {
+		f, ok := known[key]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: %w", key, ErrUnknownKey))
+			continue
+		}
+		environment[f.env] = formatValue(value, f.separator)
+	}
+
+	return errors.Join(errs...)
+}
+
+// flatten turns nested sections into dot separated keys, lists are kept as values.
+func flatten(values map[string]any, prefix string, result map[string]any) {
+	for key, value := range values {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		if section, ok := value.(map[string]any); ok {
+			flatten(section, key, result)
+			continue
+		}
+		result[key] = value
+	}
+}
+
+func renameLegacy(vars map[string]string) map[string]string {
+	for legacy, name := range legacyNames {
+		value, ok := vars[legacy]
+		if !ok {
+			continue
+		}
+		if _, set := vars[name]; !set {
+			vars[name] = value
+		}
+		delete(vars, legacy)
+	}
+
+	return vars
+}
+
+func merge(dst, src map[string]string) {
+	for key, value := range src {
+		dst[key] = value
+	}
+}
@@ -0,0 +1,202 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfigFile(t1 *testing.T, name, content string) string {
+	path := filepath.Join(t1.TempDir(), name)
+	require.NoError(t1, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoader_Load(t1 *testing.T) {
+	yamlFile := writeConfigFile(t1, "config.yaml", `
+server:
+  listen: ":9000"
+  read_timeout: 2s
+  trusted_proxies: [10.0.0.0/8, 127.0.0.1]
+service:
+  admin_username: FileAdmin
+  avatar:
+    sizes: [32, 64]
+logger:
+  level: info
+`)
+	tomlFile := writeConfigFile(t1, "config.toml", `
+[server]
+listen = ":9000"
+
+[tracing]
+sample_ratio = 0.5
+`)
+
+	type args struct {
+		file string
+		env  map[string]string
+		args []string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    func(t1 *testing.T, cfg Application)
+		wantErr error
+	}{
+		{name: "defaults", args: args{}, want: func(t1 *testing.T, cfg Application) {
+			assert.Equal(t1, ":8080", cfg.Listen)
+			assert.Equal(t1, ModeDevelopment, cfg.Mode)
+			assert.Equal(t1, []int{64, 128, 256}, cfg.AvatarSizes)
+		}},
+		{name: "yaml file overrides defaults", args: args{file: yamlFile}, want: func(t1 *testing.T, cfg Application) {
+			assert.Equal(t1, ":9000", cfg.Listen)
+			assert.Equal(t1, 2*time.Second, cfg.ReadTimeout)
+			assert.Equal(t1, 5*time.Second, cfg.WriteTimeout)
+			assert.Equal(t1, []string{"10.0.0.0/8", "127.0.0.1"}, cfg.TrustedProxies)
+			assert.Equal(t1, "FileAdmin", cfg.AdminUsername)
+			assert.Equal(t1, []int{32, 64}, cfg.AvatarSizes)
+			assert.Equal(t1, "info", cfg.LogLevel)
+		}},
+		{name: "toml file", args: args{file: tomlFile}, want: func(t1 *testing.T, cfg Application) {
+			assert.Equal(t1, ":9000", cfg.Listen)
+			assert.Equal(t1, 0.5, cfg.TracingSampleRatio)
+		}},
+		{name: "env overrides file", args: args{file: yamlFile, env: map[string]string{"SERVER_LISTEN": ":9001"}}, want: func(t1 *testing.T, cfg Application) {
+			assert.Equal(t1, ":9001", cfg.Listen)
+			assert.Equal(t1, 2*time.Second, cfg.ReadTimeout)
+		}},
+		{name: "flags override env", args: args{file: yamlFile, env: map[string]string{"SERVER_LISTEN": ":9001"}, args: []string{"--server-listen=:9002"}}, want: func(t1 *testing.T, cfg Application) {
+			assert.Equal(t1, ":9002", cfg.Listen)
+		}},
+		{name: "legacy admin variables", args: args{env: map[string]string{"DB_USERNAME": "Legacy", "DB_PASS": "secret"}}, want: func(t1 *testing.T, cfg Application) {
+			assert.Equal(t1, "Legacy", cfg.AdminUsername)
+			assert.Equal(t1, "secret", cfg.AdminPassword)
+		}},
+		{name: "current name wins over legacy", args: args{env: map[string]string{"DB_USERNAME": "Legacy", "ADMIN_USERNAME": "Current"}}, want: func(t1 *testing.T, cfg Application) {
+			assert.Equal(t1, "Current", cfg.AdminUsername)
+		}},
+		{name: "unknown file key", args: args{file: writeConfigFile(t1, "unknown.yaml", "server:\n  port: 80\n")}, wantErr: ErrUnknownKey},
+		{name: "unknown file format", args: args{file: writeConfigFile(t1, "config.json", "{}")}, wantErr: ErrUnknownFileFormat},
+		{name: "invalid value", args: args{env: map[string]string{"LOG_FORMAT": "xml"}}, wantErr: ErrUnknownValue},
+	}
+
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			t1.Setenv(configFileEnv, tt.args.file)
+			for key, value := range tt.args.env {
+				t1.Setenv(key, value)
+			}
+
+			loader, err := NewLoader(tt.args.args)
+			require.NoError(t1, err)
+
+			cfg, err := loader.Load()
+			if tt.wantErr != nil {
+				assert.True(t1, errors.Is(err, tt.wantErr), err)
+				return
+			}
+			require.NoError(t1, err)
+			tt.want(t1, cfg)
+		})
+	}
+}
+
+func TestApplication_Validate(t1 *testing.T) {
+	loader, err := NewLoader(nil)
+	require.NoError(t1, err)
+	valid, err := loader.Load()
+	require.NoError(t1, err)
+
+	tests := []struct {
+		name    string
+		change  func(cfg *Application)
+		wantErr []error
+	}{
+		{name: "valid", change: func(cfg *Application) {}},
+		{name: "all errors are reported", change: func(cfg *Application) {
+			cfg.Listen = ""
+			cfg.ReadTimeout = -time.Second
+			cfg.AvatarSizes = []int{0}
+			cfg.TracingSampleRatio = 2
+			cfg.TrustedProxies = []string{"proxy"}
+		}, wantErr: []error{ErrEmptyValue, ErrNegativeValue, ErrNotPositiveValue, ErrOutOfRange, ErrIncorrectProxy}},
+		{name: "production with defaults", change: func(cfg *Application) {
+			cfg.Mode = ModeProduction
+		}, wantErr: []error{ErrInsecureDefault}},
+		{name: "production", change: func(cfg *Application) {
+			cfg.Mode = ModeProduction
+			cfg.Salt = "pepper"
+			cfg.AdminPassword = "long random password"
+		}},
+		{name: "unknown mode", change: func(cfg *Application) {
+			cfg.Mode = "staging"
+		}, wantErr: []error{ErrUnknownValue}},
+		{name: "cors wildcard with credentials", change: func(cfg *Application) {
+			cfg.CORSAllowedOrigins = []string{"*"}
+			cfg.CORSAllowCredentials = true
+		}, wantErr: []error{ErrCORSWildcardCredentials}},
+		{name: "session without ttl", change: func(cfg *Application) {
+			cfg.SessionEnabled = true
+			cfg.SessionTTL = 0
+		}, wantErr: []error{ErrNotPositiveValue}},
+		{name: "batch longer than request timeout", change: func(cfg *Application) {
+			cfg.RequestTimeout = time.Second
+			cfg.BatchMaxOperations = 10
+		}, wantErr: []error{ErrOutOfRange}},
+		{name: "batch without request timeout", change: func(cfg *Application) {
+			cfg.RequestTimeout = 0
+			cfg.BatchMaxOperations = 1000
+		}},
+		{name: "password min length", change: func(cfg *Application) {
+			cfg.PasswordMinLength = 0
+		}, wantErr: []error{ErrOutOfRange}},
+		{name: "legacy routes sunset", change: func(cfg *Application) {
+			cfg.LegacyRoutesSunset = "30.04.2027"
+		}, wantErr: []error{ErrIncorrectDate}},
+		{name: "legacy routes deprecation", change: func(cfg *Application) {
+			cfg.LegacyRoutesDeprecated = "2026-19-10"
+		}, wantErr: []error{ErrIncorrectDate}},
+		{name: "legacy routes without sunset", change: func(cfg *Application) {
+			cfg.LegacyRoutesSunset = ""
+		}},
+	}
+
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			cfg := valid
+			cfg.AvatarSizes = append([]int(nil), valid.AvatarSizes...)
+			tt.change(&cfg)
+
+			err := cfg.Validate()
+			if len(tt.wantErr) == 0 {
+				assert.NoError(t1, err)
+				return
+			}
+			for _, want := range tt.wantErr {
+				assert.True(t1, errors.Is(err, want), err)
+			}
+		})
+	}
+}
+
+func TestApplication_Print(t1 *testing.T) {
+	loader, err := NewLoader([]string{"--service-salt=pepper", "--admin-password=secret"})
+	require.NoError(t1, err)
+	cfg, err := loader.Load()
+	require.NoError(t1, err)
+
+	var out bytes.Buffer
+	require.NoError(t1, cfg.Print(&out))
+
+	assert.NotContains(t1, out.String(), "pepper")
+	assert.NotContains(t1, out.String(), "secret")
+	assert.Contains(t1, out.String(), "salt: '"+redacted+"'")
+	assert.Equal(t1, "pepper", cfg.Salt, "printing should not change config")
+}
@@ -1,5 +1,6 @@
 package config
 
 type Logger struct {
-	LogLevel string `env:"LOG_LEVEL" envDefault:"debug"`
+	LogLevel  string `env:"LOG_LEVEL" envDefault:"debug" yaml:"level"`
+	LogFormat string `env:"LOG_FORMAT" envDefault:"text" yaml:"format"` // text or json
 }
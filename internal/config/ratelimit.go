@@ -0,0 +1,12 @@
+package config
+
+// RateLimit sets token buckets per client IP, API key and authenticated user: rate is requests per second, zero rate disables the budget.
+type RateLimit struct {
+	RateLimitRead       float64 `env:"SERVER_RATE_LIMIT_READ" envDefault:"20" yaml:"read"` // GET, HEAD and OPTIONS requests
+	RateLimitReadBurst  int     `env:"SERVER_RATE_LIMIT_READ_BURST" envDefault:"40" yaml:"read_burst"`
+	RateLimitWrite      float64 `env:"SERVER_RATE_LIMIT_WRITE" envDefault:"5" yaml:"write"`
+	RateLimitWriteBurst int     `env:"SERVER_RATE_LIMIT_WRITE_BURST" envDefault:"10" yaml:"write_burst"`
+	// RateLimitAuthFailure is counted per client IP, requests with credentials are rejected before password check when it is exhausted
+	RateLimitAuthFailure      float64 `env:"SERVER_RATE_LIMIT_AUTH_FAILURE" envDefault:"0.1" yaml:"auth_failure"`
+	RateLimitAuthFailureBurst int     `env:"SERVER_RATE_LIMIT_AUTH_FAILURE_BURST" envDefault:"5" yaml:"auth_failure_burst"`
+}
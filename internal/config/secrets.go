@@ -0,0 +1,112 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	fileSuffix     = "_FILE"     // variable holds path to file with the secret
+	keystoreSuffix = "_KEYSTORE" // variable holds name of the secret in local keystore
+)
+
+// SecretProvider returns secret by reference, meaning of the reference depends on provider.
+type SecretProvider interface {
+	Secret(ref string) (string, error)
+}
+
+// FileSecrets reads secrets from files, e.g. mounted docker or kubernetes secrets, reference is a file path.
+type FileSecrets struct{}
+
+func (FileSecrets) Secret(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file: %w", err)
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
+
+// EnvSecrets takes secrets from environment, reference is a variable name.
+type EnvSecrets map[string]string
+
+func (e EnvSecrets) Secret(name string) (string, error) {
+	secret, ok := e[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrSecretDoesNotExist, name)
+	}
+
+	return secret, nil
+}
+
+// resolveSecrets replaces every secret variable with value from its source:
+// NAME_FILE has priority over NAME_KEYSTORE, which has priority over NAME itself.
+func resolveSecrets(environment map[string]string) error {
+	secrets := secretResolver{env: EnvSecrets(environment), file: FileSecrets{}}
+
+	// keystore passphrase can't be kept in keystore, so it is resolved before keystore is opened
+	passphrase, found, err := secrets.resolve(keystorePassphraseEnv)
+	if err != nil {
+		return fmt.Errorf("%s: %w", keystorePassphraseEnv, err)
+	}
+	if found {
+		environment[keystorePassphraseEnv] = passphrase
+	}
+
+	if path := environment[keystorePathEnv]; path != "" {
+		keystore, err := OpenKeystore(path, passphrase)
+		if err != nil {
+			return fmt.Errorf("%s: %w", keystorePathEnv, err)
+		}
+		secrets.keystore = keystore
+	}
+
+	var errs []error
+	for _, f := range fields() {
+		if !f.secret || f.env == keystorePassphraseEnv {
+			continue
+		}
+
+		value, found, err := secrets.resolve(f.env)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", f.env, err))
+			continue
+		}
+		if found {
+			environment[f.env] = value
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+type secretResolver struct {
+	env      SecretProvider
+	file     SecretProvider
+	keystore SecretProvider // nil if KEYSTORE_PATH is not set
+}
+
+// resolve reports false if the secret is not set anywhere, so its default value is kept.
+func (r secretResolver) resolve(name string) (string, bool, error) {
+	if path, _ := r.env.Secret(name + fileSuffix); path != "" {
+		secret, err := r.file.Secret(path)
+		return secret, true, err
+	}
+
+	if ref, _ := r.env.Secret(name + keystoreSuffix); ref != "" {
+		if r.keystore == nil {
+			return "", true, ErrKeystoreNotConfigured
+		}
+		secret, err := r.keystore.Secret(ref)
+		return secret, true, err
+	}
+
+	secret, err := r.env.Secret(name)
+	if errors.Is(err, ErrSecretDoesNotExist) {
+		return "", false, nil
+	}
+
+	return secret, true, err
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoader_LoadSecrets(t1 *testing.T) {
+	keystorePath := filepath.Join(t1.TempDir(), "keystore.json")
+	require.NoError(t1, Keystore{KeystorePath: keystorePath, KeystorePassphrase: "passphrase"}.Put("salt", strings.NewReader("keystore salt\n")))
+
+	passwordFile := writeConfigFile(t1, "password", "  file password \n")
+	passphraseFile := writeConfigFile(t1, "passphrase", "passphrase\n")
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    func(t1 *testing.T, cfg Application)
+		wantErr error
+	}{
+		{name: "file", env: map[string]string{"ADMIN_PASSWORD_FILE": passwordFile}, want: func(t1 *testing.T, cfg Application) {
+			assert.Equal(t1, "file password", cfg.AdminPassword)
+		}},
+		{name: "file has priority over value", env: map[string]string{"ADMIN_PASSWORD": "env password", "ADMIN_PASSWORD_FILE": passwordFile}, want: func(t1 *testing.T, cfg Application) {
+			assert.Equal(t1, "file password", cfg.AdminPassword)
+		}},
+		{name: "legacy file variable", env: map[string]string{"DB_PASS_FILE": passwordFile}, want: func(t1 *testing.T, cfg Application) {
+			assert.Equal(t1, "file password", cfg.AdminPassword)
+		}},
+		{name: "keystore", env: map[string]string{"KEYSTORE_PATH": keystorePath, "KEYSTORE_PASSPHRASE_FILE": passphraseFile, "SERVICE_SALT_KEYSTORE": "salt"}, want: func(t1 *testing.T, cfg Application) {
+			assert.Equal(t1, "keystore salt", cfg.Salt)
+			assert.Equal(t1, "passphrase", cfg.KeystorePassphrase)
+		}},
+		{name: "default is kept", env: map[string]string{}, want: func(t1 *testing.T, cfg Application) {
+			assert.Equal(t1, defaultAdminPassword, cfg.AdminPassword)
+		}},
+		{name: "missing file", env: map[string]string{"ADMIN_PASSWORD_FILE": filepath.Join(t1.TempDir(), "missing")}, wantErr: os.ErrNotExist},
+		{name: "keystore is not configured", env: map[string]string{"SERVICE_SALT_KEYSTORE": "salt"}, wantErr: ErrKeystoreNotConfigured},
+		{name: "wrong passphrase", env: map[string]string{"KEYSTORE_PATH": keystorePath, "KEYSTORE_PASSPHRASE": "wrong", "SERVICE_SALT_KEYSTORE": "salt"}, wantErr: ErrKeystoreDecryption},
+		{name: "unknown keystore secret", env: map[string]string{"KEYSTORE_PATH": keystorePath, "KEYSTORE_PASSPHRASE": "passphrase", "SERVICE_SALT_KEYSTORE": "pepper"}, wantErr: ErrSecretDoesNotExist},
+	}
+
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			for key, value := range tt.env {
+				t1.Setenv(key, value)
+			}
+
+			loader, err := NewLoader(nil)
+			require.NoError(t1, err)
+
+			cfg, err := loader.Load()
+			if tt.wantErr != nil {
+				assert.True(t1, errors.Is(err, tt.wantErr), err)
+				return
+			}
+			require.NoError(t1, err)
+			tt.want(t1, cfg)
+		})
+	}
+}
+
+func TestLoader_LoadRereadsSecrets(t1 *testing.T) {
+	passwordFile := writeConfigFile(t1, "password", "first")
+	t1.Setenv("ADMIN_PASSWORD_FILE", passwordFile)
+
+	loader, err := NewLoader(nil)
+	require.NoError(t1, err)
+
+	cfg, err := loader.Load()
+	require.NoError(t1, err)
+	assert.Equal(t1, "first", cfg.AdminPassword)
+
+	require.NoError(t1, os.WriteFile(passwordFile, []byte("second"), 0o600))
+
+	cfg, err = loader.Load()
+	require.NoError(t1, err)
+	assert.Equal(t1, "second", cfg.AdminPassword)
+}
+
+func TestLoader_LoadKeystore(t1 *testing.T) {
+	keystorePath := filepath.Join(t1.TempDir(), "keystore.json")
+	t1.Setenv("KEYSTORE_PATH", keystorePath)
+	t1.Setenv("KEYSTORE_PASSPHRASE_FILE", writeConfigFile(t1, "passphrase", "passphrase\n"))
+	t1.Setenv("SERVICE_SALT_KEYSTORE", "salt")
+
+	loader, err := NewLoader([]string{"--keystore-put", "salt"})
+	require.NoError(t1, err)
+
+	_, err = loader.Load()
+	require.ErrorIs(t1, err, ErrSecretDoesNotExist, "config refers to secret which is not put yet")
+
+	keystore, err := loader.LoadKeystore()
+	require.NoError(t1, err)
+	assert.Equal(t1, Keystore{KeystorePath: keystorePath, KeystorePassphrase: "passphrase"}, keystore)
+	require.NoError(t1, keystore.Put(loader.KeystorePut, strings.NewReader("keystore salt\n")))
+
+	cfg, err := loader.Load()
+	require.NoError(t1, err)
+	assert.Equal(t1, "keystore salt", cfg.Salt)
+}
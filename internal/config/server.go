@@ -2,9 +2,34 @@ package config
 
 import "time"
 
+// PasswordHashCost is expected time to hash one password, limits of work done during request are derived from it.
+const PasswordHashCost = 100 * time.Millisecond
+
 type Server struct {
-	Listen       string        `env:"SERVER_LISTEN" envDefault:":8080"`
-	ReadTimeout  time.Duration `env:"SERVER_READ_TIMEOUT" envDefault:"5s"`
-	WriteTimeout time.Duration `env:"SERVER_WRITE_TIMEOUT" envDefault:"5s"`
-	IdleTimeout  time.Duration `env:"SERVER_IDLE_TIMEOUT" envDefault:"30s"`
+	Listen       string        `env:"SERVER_LISTEN" envDefault:":8080" yaml:"listen"`
+	ReadTimeout  time.Duration `env:"SERVER_READ_TIMEOUT" envDefault:"5s" yaml:"read_timeout"`
+	WriteTimeout time.Duration `env:"SERVER_WRITE_TIMEOUT" envDefault:"5s" yaml:"write_timeout"`
+	IdleTimeout  time.Duration `env:"SERVER_IDLE_TIMEOUT" envDefault:"30s" yaml:"idle_timeout"`
+	// RequestTimeout cancels context of request handling, zero value leaves only client disconnect
+	RequestTimeout time.Duration `env:"SERVER_REQUEST_TIMEOUT" envDefault:"5s" yaml:"request_timeout"`
+	// TrustedProxies are addresses or CIDR networks whose X-Forwarded-For header is used to find client address
+	TrustedProxies []string `env:"SERVER_TRUSTED_PROXIES" envSeparator:"," yaml:"trusted_proxies"`
+	// DrainDelay is time between readiness starts failing and server stops accepting requests
+	DrainDelay    time.Duration `env:"SERVER_DRAIN_DELAY" envDefault:"5s" yaml:"drain_delay"`
+	MetricsListen string        `env:"SERVER_METRICS_LISTEN" yaml:"metrics_listen"` // empty value serves /metrics on Listen to super admins
+	// IdempotencyTTL is how long responses to requests with Idempotency-Key header are replayed, zero value disables the header
+	IdempotencyTTL time.Duration `env:"SERVER_IDEMPOTENCY_TTL" envDefault:"24h" yaml:"idempotency_ttl"`
+	// BatchMaxOperations limits operations in one request to POST /user/batch, hashing of their passwords
+	// should take at most half of RequestTimeout
+	BatchMaxOperations int `env:"SERVER_BATCH_MAX_OPERATIONS" envDefault:"25" yaml:"batch_max_operations"`
+	// LegacyRoutes serves API also without version prefix, such responses carry Deprecation and Sunset headers
+	LegacyRoutes bool `env:"SERVER_LEGACY_ROUTES" envDefault:"true" yaml:"legacy_routes"`
+	// LegacyRoutesSunset is date in YYYY-MM-DD format when routes without version prefix are removed, empty value omits Sunset header
+	LegacyRoutesSunset string `env:"SERVER_LEGACY_ROUTES_SUNSET" envDefault:"2027-04-30" yaml:"legacy_routes_sunset"`
+	// LegacyRoutesDeprecated is date in YYYY-MM-DD format when routes without version prefix were deprecated, empty value omits Deprecation header
+	LegacyRoutesDeprecated string `env:"SERVER_LEGACY_ROUTES_DEPRECATED" envDefault:"2026-10-19" yaml:"legacy_routes_deprecated"`
+	TLS                    `yaml:"tls"`
+	RateLimit              `yaml:"rate_limit"`
+	CORS                   `yaml:"cors"`
+	Session                `yaml:"session"`
 }
@@ -1,8 +1,35 @@
 package config
 
+import "time"
+
 type Service struct {
-	Salt          string `env:"SERVICE_SALT" envDefault:"MyUniqueSalt"`
-	AdminUsername string `env:"DB_USERNAME" envDefault:"Admin"`
-	AdminPassword string `env:"DB_PASS" envDefault:"qwerty"`
-	AdminEmail    string `env:"DB_Email" envDefault:"qwerty@email.com"`
+	Salt           string `env:"SERVICE_SALT" envDefault:"MyUniqueSalt" yaml:"salt" secret:"true"`
+	AdminUsername  string `env:"ADMIN_USERNAME" envDefault:"Admin" yaml:"admin_username"`
+	AdminPassword  string `env:"ADMIN_PASSWORD" envDefault:"qwerty" yaml:"admin_password" secret:"true"`
+	AdminEmail     string `env:"ADMIN_EMAIL" envDefault:"qwerty@email.com" yaml:"admin_email"`
+	DefaultTenant  string `env:"SERVICE_DEFAULT_TENANT" envDefault:"default" yaml:"default_tenant"`
+	Avatar         `yaml:"avatar"`
+	Inactivity     `yaml:"inactivity"`
+	PasswordPolicy `yaml:"password_policy"`
+}
+
+// PasswordPolicy is checked when password is set, it is applied on reload without restart.
+type PasswordPolicy struct {
+	// PasswordMinLength is the least number of characters in password, bcrypt uses at most 72 bytes of it
+	PasswordMinLength int `env:"PASSWORD_MIN_LENGTH" envDefault:"1" yaml:"min_length"`
+}
+
+type Avatar struct {
+	AvatarMaxSize   int64 `env:"AVATAR_MAX_SIZE" envDefault:"5242880" yaml:"max_size"`
+	AvatarMaxWidth  int   `env:"AVATAR_MAX_WIDTH" envDefault:"4096" yaml:"max_width"`
+	AvatarMaxHeight int   `env:"AVATAR_MAX_HEIGHT" envDefault:"4096" yaml:"max_height"`
+	AvatarSizes     []int `env:"AVATAR_SIZES" envSeparator:"," envDefault:"64,128,256" yaml:"sizes"`
+}
+
+type Inactivity struct {
+	InactivityWarnAfterDays    int           `env:"INACTIVITY_WARN_AFTER_DAYS" envDefault:"0" yaml:"warn_after_days"`
+	InactivityDisableAfterDays int           `env:"INACTIVITY_DISABLE_AFTER_DAYS" envDefault:"0" yaml:"disable_after_days"`
+	InactivityExemptAdmins     bool          `env:"INACTIVITY_EXEMPT_ADMINS" envDefault:"true" yaml:"exempt_admins"`
+	InactivityAllowlist        []string      `env:"INACTIVITY_ALLOWLIST" envSeparator:"," yaml:"allowlist"`
+	InactivityCheckInterval    time.Duration `env:"INACTIVITY_CHECK_INTERVAL" envDefault:"1h" yaml:"check_interval"`
 }
@@ -0,0 +1,10 @@
+package config
+
+import "time"
+
+// Session enables cookie sessions for browsers, unsafe requests of a session require its CSRF token in X-CSRF-Token header.
+type Session struct {
+	SessionEnabled      bool          `env:"SERVER_SESSION_ENABLED" envDefault:"false" yaml:"enabled"`
+	SessionTTL          time.Duration `env:"SERVER_SESSION_TTL" envDefault:"12h" yaml:"ttl"`
+	SessionCookieSecure bool          `env:"SERVER_SESSION_COOKIE_SECURE" envDefault:"true" yaml:"cookie_secure"` // cookie is sent only over HTTPS
+}
@@ -0,0 +1,31 @@
+package config
+
+import "time"
+
+const (
+	TLSVersion12 = "1.2"
+	TLSVersion13 = "1.3"
+
+	CipherPolicyDefault = "default" // Go defaults
+	CipherPolicyModern  = "modern"  // only ECDHE key exchange with AEAD ciphers for TLS 1.2
+
+	ClientAuthOptional = "optional" // client certificate is verified if given
+	ClientAuthRequire  = "require"  // connections without verified client certificate are rejected
+)
+
+// TLS is enabled when both certificate and key files are set.
+type TLS struct {
+	TLSCertFile     string        `env:"SERVER_TLS_CERT_FILE" yaml:"cert_file"`
+	TLSKeyFile      string        `env:"SERVER_TLS_KEY_FILE" yaml:"key_file"`
+	TLSMinVersion   string        `env:"SERVER_TLS_MIN_VERSION" envDefault:"1.2" yaml:"min_version"`
+	TLSCipherPolicy string        `env:"SERVER_TLS_CIPHER_POLICY" envDefault:"default" yaml:"cipher_policy"`
+	TLSReloadCheck  time.Duration `env:"SERVER_TLS_RELOAD_CHECK" envDefault:"1m" yaml:"reload_check"` // how often certificate files are checked for changes
+	// TLSClientCAFile enables mutual TLS, common name of verified client certificate is used as username
+	TLSClientCAFile   string `env:"SERVER_TLS_CLIENT_CA_FILE" yaml:"client_ca_file"`
+	TLSClientAuth     string `env:"SERVER_TLS_CLIENT_AUTH" envDefault:"optional" yaml:"client_auth"`
+	TLSRedirectListen string `env:"SERVER_TLS_REDIRECT_LISTEN" yaml:"redirect_listen"` // plain HTTP listener redirecting to HTTPS
+}
+
+func (t TLS) Enabled() bool {
+	return t.TLSCertFile != "" && t.TLSKeyFile != ""
+}
@@ -0,0 +1,9 @@
+package config
+
+type Tracing struct {
+	TracingExporter     string  `env:"TRACING_EXPORTER" envDefault:"none" yaml:"exporter"` // none, stdout or otlp
+	TracingOTLPEndpoint string  `env:"TRACING_OTLP_ENDPOINT" envDefault:"localhost:4318" yaml:"otlp_endpoint"`
+	TracingOTLPInsecure bool    `env:"TRACING_OTLP_INSECURE" envDefault:"false" yaml:"otlp_insecure"`
+	TracingSampleRatio  float64 `env:"TRACING_SAMPLE_RATIO" envDefault:"1" yaml:"sample_ratio"`
+	TracingServiceName  string  `env:"TRACING_SERVICE_NAME" envDefault:"profiles" yaml:"service_name"`
+}
@@ -0,0 +1,210 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// insecure defaults are kept for local runs only, production mode rejects them
+const (
+	defaultSalt          = "MyUniqueSalt"
+	defaultAdminPassword = "qwerty"
+
+	maxPasswordLength = 72
+)
+
+var (
+	modes          = []string{ModeDevelopment, ModeProduction}
+	logFormats     = []string{"text", "json"}
+	traceExporters = []string{"none", "stdout", "otlp"}
+	tlsVersions    = []string{TLSVersion12, TLSVersion13}
+	cipherPolicies = []string{CipherPolicyDefault, CipherPolicyModern}
+	clientAuths    = []string{ClientAuthOptional, ClientAuthRequire}
+)
+
+// Validate checks all values and returns every found error at once.
+func (a Application) Validate() error {
+	var errs []error
+	check := func(name string, err error) {
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+
+	check("APP_MODE", oneOf(a.Mode, modes))
+
+	check("SERVER_LISTEN", required(a.Listen))
+	check("SERVER_READ_TIMEOUT", notNegative(a.ReadTimeout))
+	check("SERVER_WRITE_TIMEOUT", notNegative(a.WriteTimeout))
+	check("SERVER_IDLE_TIMEOUT", notNegative(a.IdleTimeout))
+	check("SERVER_REQUEST_TIMEOUT", notNegative(a.RequestTimeout))
+	check("SERVER_DRAIN_DELAY", notNegative(a.DrainDelay))
+	check("SERVER_IDEMPOTENCY_TTL", notNegative(a.IdempotencyTTL))
+	check("SERVER_BATCH_MAX_OPERATIONS", positive(a.BatchMaxOperations))
+	check("SERVER_BATCH_MAX_OPERATIONS", batchFitsTimeout(a.BatchMaxOperations, a.RequestTimeout))
+	check("SERVER_LEGACY_ROUTES_SUNSET", date(a.LegacyRoutesSunset))
+	check("SERVER_LEGACY_ROUTES_DEPRECATED", date(a.LegacyRoutesDeprecated))
+	for _, proxy := range a.TrustedProxies {
+		check("SERVER_TRUSTED_PROXIES", trustedProxy(proxy))
+	}
+
+	check("SERVER_RATE_LIMIT_READ", rateLimit(a.RateLimitRead, a.RateLimitReadBurst))
+	check("SERVER_RATE_LIMIT_WRITE", rateLimit(a.RateLimitWrite, a.RateLimitWriteBurst))
+	check("SERVER_RATE_LIMIT_AUTH_FAILURE", rateLimit(a.RateLimitAuthFailure, a.RateLimitAuthFailureBurst))
+
+	if a.CORSAllowCredentials && slices.Contains(a.CORSAllowedOrigins, "*") {
+		check("SERVER_CORS_ALLOWED_ORIGINS", ErrCORSWildcardCredentials)
+	}
+	check("SERVER_CORS_MAX_AGE", notNegative(a.CORSMaxAge))
+	if a.SessionEnabled {
+		check("SERVER_SESSION_TTL", positive(a.SessionTTL))
+	}
+
+	if (a.TLSCertFile == "") != (a.TLSKeyFile == "") {
+		check("SERVER_TLS_CERT_FILE and SERVER_TLS_KEY_FILE", ErrTLSPair)
+	}
+	check("SERVER_TLS_MIN_VERSION", oneOf(a.TLSMinVersion, tlsVersions))
+	check("SERVER_TLS_CIPHER_POLICY", oneOf(a.TLSCipherPolicy, cipherPolicies))
+	check("SERVER_TLS_CLIENT_AUTH", oneOf(a.TLSClientAuth, clientAuths))
+	check("SERVER_TLS_RELOAD_CHECK", notNegative(a.TLSReloadCheck))
+	if !a.TLS.Enabled() {
+		if a.TLSClientCAFile != "" {
+			check("SERVER_TLS_CLIENT_CA_FILE", ErrTLSDisabled)
+		}
+		if a.TLSRedirectListen != "" {
+			check("SERVER_TLS_REDIRECT_LISTEN", ErrTLSDisabled)
+		}
+	}
+
+	check("SERVICE_SALT", required(a.Salt))
+	check("ADMIN_USERNAME", required(a.AdminUsername))
+	check("ADMIN_PASSWORD", required(a.AdminPassword))
+	check("ADMIN_EMAIL", required(a.AdminEmail))
+	check("SERVICE_DEFAULT_TENANT", required(a.DefaultTenant))
+	if a.Mode == ModeProduction {
+		check("SERVICE_SALT", notDefault(a.Salt, defaultSalt))
+		check("ADMIN_PASSWORD", notDefault(a.AdminPassword, defaultAdminPassword))
+	}
+
+	check("AVATAR_MAX_SIZE", positive(a.AvatarMaxSize))
+	check("AVATAR_MAX_WIDTH", positive(a.AvatarMaxWidth))
+	check("AVATAR_MAX_HEIGHT", positive(a.AvatarMaxHeight))
+	for _, size := range a.AvatarSizes {
+		check("AVATAR_SIZES", positive(size))
+	}
+
+	check("PASSWORD_MIN_LENGTH", passwordLength(a.PasswordMinLength))
+
+	check("INACTIVITY_WARN_AFTER_DAYS", notNegative(a.InactivityWarnAfterDays))
+	check("INACTIVITY_DISABLE_AFTER_DAYS", notNegative(a.InactivityDisableAfterDays))
+	check("INACTIVITY_CHECK_INTERVAL", notNegative(a.InactivityCheckInterval))
+
+	check("BLOB_STORE_PATH", required(a.BlobStorePath))
+
+	if _, err := logrus.ParseLevel(a.LogLevel); err != nil {
+		check("LOG_LEVEL", fmt.Errorf("%w: %s", ErrUnknownValue, a.LogLevel))
+	}
+	check("LOG_FORMAT", oneOf(a.LogFormat, logFormats))
+
+	check("TRACING_EXPORTER", oneOf(a.TracingExporter, traceExporters))
+	if a.TracingSampleRatio < 0 || a.TracingSampleRatio > 1 {
+		check("TRACING_SAMPLE_RATIO", fmt.Errorf("%w: %v, expected from 0 to 1", ErrOutOfRange, a.TracingSampleRatio))
+	}
+	check("TRACING_SERVICE_NAME", required(a.TracingServiceName))
+
+	return errors.Join(errs...)
+}
+
+func required(value string) error {
+	if value == "" {
+		return ErrEmptyValue
+	}
+	return nil
+}
+
+// rateLimit checks rate with its burst, burst is not used when rate is zero.
+func rateLimit(rate float64, burst int) error {
+	if rate < 0 {
+		return ErrNegativeValue
+	}
+	if rate > 0 && burst <= 0 {
+		return fmt.Errorf("burst: %w", ErrNotPositiveValue)
+	}
+	return nil
+}
+
+func notNegative[T int | int64 | time.Duration](value T) error {
+	if value < 0 {
+		return ErrNegativeValue
+	}
+	return nil
+}
+
+func positive[T int | int64 | time.Duration](value T) error {
+	if value <= 0 {
+		return ErrNotPositiveValue
+	}
+	return nil
+}
+
+func oneOf(value string, allowed []string) error {
+	if !slices.Contains(allowed, value) {
+		return fmt.Errorf("%w: %q, expected one of %v", ErrUnknownValue, value, allowed)
+	}
+	return nil
+}
+
+func notDefault(value, defaultValue string) error {
+	if value == defaultValue {
+		return ErrInsecureDefault
+	}
+	return nil
+}
+
+// batchFitsTimeout checks that passwords of the largest batch are hashed in half of request timeout,
+// as import processed during request. Zero timeout does not limit batch.
+func batchFitsTimeout(operations int, requestTimeout time.Duration) error {
+	if requestTimeout <= 0 || operations <= 0 {
+		return nil
+	}
+	if limit := int(requestTimeout / 2 / PasswordHashCost); operations > limit {
+		return fmt.Errorf("%w: %d, SERVER_REQUEST_TIMEOUT %s allows at most %d", ErrOutOfRange, operations, requestTimeout, limit)
+	}
+	return nil
+}
+
+// passwordLength accepts lengths which bcrypt can check, longer passwords are truncated to 72 bytes.
+func passwordLength(length int) error {
+	if length <= 0 || length > maxPasswordLength {
+		return fmt.Errorf("%w: %d, expected from 1 to %d", ErrOutOfRange, length, maxPasswordLength)
+	}
+	return nil
+}
+
+// date accepts empty value, it means date is not set.
+func date(value string) error {
+	if value == "" {
+		return nil
+	}
+	if _, err := time.Parse(time.DateOnly, value); err != nil {
+		return fmt.Errorf("%w: %q", ErrIncorrectDate, value)
+	}
+	return nil
+}
+
+func trustedProxy(proxy string) error {
+	proxy = strings.TrimSpace(proxy)
+	if net.ParseIP(proxy) != nil {
+		return nil
+	}
+	if _, _, err := net.ParseCIDR(proxy); err != nil {
+		return fmt.Errorf("%w: %s", ErrIncorrectProxy, proxy)
+	}
+	return nil
+}
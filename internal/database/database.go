@@ -1,14 +1,23 @@
 package database
 
 import (
+	"context"
+	"slices"
 	"sync"
+	"time"
 )
 
 type Database struct {
-	mutex       sync.RWMutex
-	users       []*User
-	idIDX       map[string]*User
-	usernameIDX map[string]*User
+	mutex           sync.RWMutex
+	users           []*User
+	idIDX           map[string]*User
+	usernameIDX     map[string]*User // key is built by usernameKey, usernames are unique inside organization
+	attributeSchema AttributeSchema
+	groups          []*Group
+	groupIDX        map[string]*Group
+	members         map[string]map[string]struct{}
+	organizations   []*Organization
+	orgIDX          map[string]*Organization
 }
 
 func NewDatabase() *Database {
@@ -16,68 +25,178 @@ func NewDatabase() *Database {
 		users:       make([]*User, 0),
 		idIDX:       make(map[string]*User),
 		usernameIDX: make(map[string]*User),
+		groups:      make([]*Group, 0),
+		groupIDX:    make(map[string]*Group),
+		members:     make(map[string]map[string]struct{}),
+		orgIDX:      make(map[string]*Organization),
 	}
 }
 
-func (db *Database) AddUser(user User) error {
-	db.mutex.Lock()
+func (db *Database) AddUser(ctx context.Context, user User) error {
+	ctx, span := tracer.Start(ctx, "Database.AddUser")
+	defer span.End()
+
+	if err := db.lock(ctx); err != nil {
+		return err
+	}
 	defer db.mutex.Unlock()
 
+	return db.addUser(user)
+}
+
+func (db *Database) addUser(user User) error {
 	if _, ok := db.idIDX[user.ID]; ok {
 		return ErrUserAlreadyExist
 	}
 
-	if _, ok := db.usernameIDX[user.Username]; ok {
+	if _, ok := db.usernameIDX[usernameKey(user.OrgID, user.Username)]; ok {
 		return ErrNotUniqueUsername
 	}
 
-	db.idIDX[user.ID] = &user
-	db.usernameIDX[user.Username] = &user
-	db.users = append(db.users, &user)
+	db.insertUser(user)
 
 	return nil
 }
 
-func (db *Database) GetAllUsers(offset, limit int) []User {
-	db.mutex.RLock()
+// AddUsers adds all users or none of them if any is not unique.
+func (db *Database) AddUsers(ctx context.Context, users []User) error {
+	ctx, span := tracer.Start(ctx, "Database.AddUsers")
+	defer span.End()
+
+	if err := db.lock(ctx); err != nil {
+		return err
+	}
+	defer db.mutex.Unlock()
+
+	ids := make(map[string]struct{}, len(users))
+	usernames := make(map[string]struct{}, len(users))
+	for _, user := range users {
+		if _, ok := db.idIDX[user.ID]; ok {
+			return ErrUserAlreadyExist
+		}
+		if _, ok := ids[user.ID]; ok {
+			return ErrUserAlreadyExist
+		}
+
+		key := usernameKey(user.OrgID, user.Username)
+		if _, ok := db.usernameIDX[key]; ok {
+			return ErrNotUniqueUsername
+		}
+		if _, ok := usernames[key]; ok {
+			return ErrNotUniqueUsername
+		}
+
+		ids[user.ID] = struct{}{}
+		usernames[key] = struct{}{}
+	}
+
+	for _, user := range users {
+		db.insertUser(user)
+	}
+
+	return nil
+}
+
+func (db *Database) insertUser(user User) {
+	db.idIDX[user.ID] = &user
+	db.usernameIDX[usernameKey(user.OrgID, user.Username)] = &user
+	db.users = append(db.users, &user)
+}
+
+func (db *Database) GetAllUsers(ctx context.Context, offset, limit int, filter UserFilter) ([]User, error) {
+	ctx, span := tracer.Start(ctx, "Database.GetAllUsers")
+	defer span.End()
+
+	if err := db.rlock(ctx); err != nil {
+		return nil, err
+	}
 	defer db.mutex.RUnlock()
 
+	users := db.filterUsers(filter)
 	result := make([]User, 0, limit)
 
 	from := offset
 	to := offset + limit
 
-	if offset > len(db.users)-1 {
-		from = len(db.users) - limit
-		to = len(db.users)
+	if offset > len(users)-1 {
+		from = len(users) - limit
+		to = len(users)
 	}
 
 	if from < 0 {
 		from = 0
 	}
 
-	if to > len(db.users)-1 {
-		for _, user := range db.users[from:] {
+	if to > len(users)-1 {
+		for _, user := range users[from:] {
 			result = append(result, *user)
 		}
 	} else {
-		for _, user := range db.users[from:to] {
+		for _, user := range users[from:to] {
 			result = append(result, *user)
 		}
 	}
 
-	return result
+	return result, nil
 }
 
-func (db *Database) CountUsers() int {
-	db.mutex.RLock()
+func (db *Database) CountUsers(ctx context.Context, filter UserFilter) (int, error) {
+	ctx, span := tracer.Start(ctx, "Database.CountUsers")
+	defer span.End()
+
+	if err := db.rlock(ctx); err != nil {
+		return 0, err
+	}
 	defer db.mutex.RUnlock()
 
-	return len(db.users)
+	return len(db.filterUsers(filter)), nil
 }
 
-func (db *Database) GetUserByID(id string) (*User, error) {
-	db.mutex.RLock()
+// EachUser calls fn for every user matching filter until fn returns error. Read lock is held only while
+// snapshot of matching users is taken, fn is called without lock.
+func (db *Database) EachUser(ctx context.Context, filter UserFilter, fn func(user User) error) error {
+	ctx, span := tracer.Start(ctx, "Database.EachUser")
+	defer span.End()
+
+	if err := db.rlock(ctx); err != nil {
+		return err
+	}
+	users := slices.Clone(db.filterUsers(filter))
+	db.mutex.RUnlock()
+
+	for _, user := range users {
+		if err := fn(*user); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (db *Database) filterUsers(filter UserFilter) []*User {
+	if filter.isEmpty() && filter.SortBy == "" {
+		return db.users
+	}
+
+	users := make([]*User, 0)
+	for _, user := range db.users {
+		if filter.match(user) {
+			users = append(users, user)
+		}
+	}
+
+	filter.sort(users)
+
+	return users
+}
+
+func (db *Database) GetUserByID(ctx context.Context, id string) (*User, error) {
+	ctx, span := tracer.Start(ctx, "Database.GetUserByID")
+	defer span.End()
+
+	if err := db.rlock(ctx); err != nil {
+		return nil, err
+	}
 	defer db.mutex.RUnlock()
 
 	user, ok := db.idIDX[id]
@@ -88,11 +207,16 @@ func (db *Database) GetUserByID(id string) (*User, error) {
 	return user, nil
 }
 
-func (db *Database) GetUserByUsername(username string) (*User, error) {
-	db.mutex.RLock()
+func (db *Database) GetUserByUsername(ctx context.Context, orgID, username string) (*User, error) {
+	ctx, span := tracer.Start(ctx, "Database.GetUserByUsername")
+	defer span.End()
+
+	if err := db.rlock(ctx); err != nil {
+		return nil, err
+	}
 	defer db.mutex.RUnlock()
 
-	user, ok := db.usernameIDX[username]
+	user, ok := db.usernameIDX[usernameKey(orgID, username)]
 	if !ok {
 		return nil, ErrUserDoesNotExist
 	}
@@ -100,29 +224,88 @@ func (db *Database) GetUserByUsername(username string) (*User, error) {
 	return user, nil
 }
 
-func (db *Database) ChangeUser(user UserUpdate) error {
-	db.mutex.Lock()
+func (db *Database) ChangeUser(ctx context.Context, user UserUpdate) error {
+	ctx, span := tracer.Start(ctx, "Database.ChangeUser")
+	defer span.End()
+
+	if err := db.lock(ctx); err != nil {
+		return err
+	}
 	defer db.mutex.Unlock()
 
+	return db.changeUser(user)
+}
+
+func (db *Database) changeUser(user UserUpdate) error {
 	oldUser, ok := db.idIDX[user.ID]
 	if !ok {
 		return ErrUserDoesNotExist
 	}
 
 	if user.Username != nil && *user.Username != oldUser.Username {
-		if _, ok = db.usernameIDX[*user.Username]; ok {
+		if _, ok = db.usernameIDX[usernameKey(oldUser.OrgID, *user.Username)]; ok {
 			return ErrNotUniqueUsername
 		}
+	}
+
+	changed := *oldUser
+	db.updateUser(&changed, user)
+	db.replaceUser(oldUser, &changed)
+
+	return nil
+}
+
+func (db *Database) UpdateLastLogin(ctx context.Context, id string, loginAt time.Time) error {
+	ctx, span := tracer.Start(ctx, "Database.UpdateLastLogin")
+	defer span.End()
 
-		delete(db.usernameIDX, oldUser.Username)
-		db.usernameIDX[*user.Username] = oldUser
+	if err := db.lock(ctx); err != nil {
+		return err
 	}
+	defer db.mutex.Unlock()
 
-	db.updateUser(oldUser, user)
+	user, ok := db.idIDX[id]
+	if !ok {
+		return ErrUserDoesNotExist
+	}
+
+	changed := *user
+	changed.LastLoginAt = loginAt
+	db.replaceUser(user, &changed)
 
 	return nil
 }
 
+func (db *Database) UpdateInactivityWarning(ctx context.Context, id string, warnedAt time.Time) error {
+	ctx, span := tracer.Start(ctx, "Database.UpdateInactivityWarning")
+	defer span.End()
+
+	if err := db.lock(ctx); err != nil {
+		return err
+	}
+	defer db.mutex.Unlock()
+
+	user, ok := db.idIDX[id]
+	if !ok {
+		return ErrUserDoesNotExist
+	}
+
+	changed := *user
+	changed.InactivityWarnedAt = warnedAt
+	db.replaceUser(user, &changed)
+
+	return nil
+}
+
+// replaceUser stores changed copy of user. Stored users are never modified, so users returned
+// by storage and snapshots taken by EachUser stay consistent without lock.
+func (db *Database) replaceUser(old, user *User) {
+	db.idIDX[user.ID] = user
+	delete(db.usernameIDX, usernameKey(old.OrgID, old.Username))
+	db.usernameIDX[usernameKey(user.OrgID, user.Username)] = user
+	db.users[slices.Index(db.users, old)] = user
+}
+
 func (db *Database) updateUser(user *User, changes UserUpdate) {
 	if changes.Email != nil {
 		user.Email = *changes.Email
@@ -134,34 +317,89 @@ func (db *Database) updateUser(user *User, changes UserUpdate) {
 
 	if changes.PassHash != nil {
 		user.PassHash = *changes.PassHash
+		user.PasswordChangedAt = changes.UpdatedAt
 	}
 
 	if changes.Admin != nil {
 		user.Admin = *changes.Admin
 	}
+
+	if changes.SuperAdmin != nil {
+		user.SuperAdmin = *changes.SuperAdmin
+	}
+
+	if changes.Disabled != nil {
+		if user.Disabled && !*changes.Disabled {
+			user.ReactivatedAt = changes.UpdatedAt
+		}
+		user.Disabled = *changes.Disabled
+	}
+
+	if changes.Attributes != nil {
+		user.Attributes = changes.Attributes
+	}
+
+	user.UpdatedAt = changes.UpdatedAt
 }
 
-func (db *Database) DeleteUser(id string) error {
-	db.mutex.Lock()
+func (db *Database) DeleteUser(ctx context.Context, id string) error {
+	ctx, span := tracer.Start(ctx, "Database.DeleteUser")
+	defer span.End()
+
+	if err := db.lock(ctx); err != nil {
+		return err
+	}
 	defer db.mutex.Unlock()
 
+	return db.deleteUser(id)
+}
+
+func (db *Database) deleteUser(id string) error {
 	user, ok := db.idIDX[id]
 	if !ok {
 		return ErrUserDoesNotExist
 	}
 
 	delete(db.idIDX, user.ID)
-	delete(db.usernameIDX, user.Username)
-
-	for i, v := range db.users {
-		if v.ID == user.ID {
-			if i != len(db.users)-1 {
-				db.users = append(db.users[:i], db.users[i+1:]...)
-			}
-			db.users = db.users[:len(db.users)-1]
-			break
-		}
+	delete(db.usernameIDX, usernameKey(user.OrgID, user.Username))
+
+	for _, members := range db.members {
+		delete(members, user.ID)
 	}
 
+	db.users = slices.DeleteFunc(db.users, func(v *User) bool {
+		return v.ID == user.ID
+	})
+
+	return nil
+}
+
+func usernameKey(orgID, username string) string {
+	return orgID + "/" + username
+}
+
+func (db *Database) GetAttributeSchema(ctx context.Context) (AttributeSchema, error) {
+	ctx, span := tracer.Start(ctx, "Database.GetAttributeSchema")
+	defer span.End()
+
+	if err := db.rlock(ctx); err != nil {
+		return AttributeSchema{}, err
+	}
+	defer db.mutex.RUnlock()
+
+	return db.attributeSchema, nil
+}
+
+func (db *Database) SetAttributeSchema(ctx context.Context, schema AttributeSchema) error {
+	ctx, span := tracer.Start(ctx, "Database.SetAttributeSchema")
+	defer span.End()
+
+	if err := db.lock(ctx); err != nil {
+		return err
+	}
+	defer db.mutex.Unlock()
+
+	db.attributeSchema = schema
+
 	return nil
 }
@@ -1,8 +1,10 @@
 package database
 
 import (
+	"context"
 	"log"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -19,7 +21,11 @@ var testUsers = []User{
 		Email:    "test2@email.com",
 		Username: "testUser2",
 		PassHash: "super hash2",
-		Admin:    false},
+		Admin:    false,
+		Attributes: map[string]any{
+			"department": "sales",
+			"floor":      float64(2),
+		}},
 	{
 		ID:       "3",
 		Email:    "test3@email.com",
@@ -33,7 +39,7 @@ func prepareDB(isFull bool) *Database {
 
 	if isFull {
 		for _, user := range testUsers {
-			err := db.AddUser(user)
+			err := db.AddUser(context.Background(), user)
 			if err != nil {
 				log.Fatalf("failed to add user: %v, %s", user.ID, err.Error())
 			}
@@ -83,11 +89,11 @@ func TestDatabase_AddUser(t1 *testing.T) {
 
 	for _, tt := range tests {
 		t1.Run(tt.name, func(t1 *testing.T) {
-			err := db.AddUser(tt.args.user)
+			err := db.AddUser(context.Background(), tt.args.user)
 			if !tt.want.wantErr {
 				assert.NoError(t1, err)
 				assert.Equal(t1, &tt.args.user, db.idIDX[tt.args.user.ID])
-				assert.Equal(t1, &tt.args.user, db.usernameIDX[tt.args.user.Username])
+				assert.Equal(t1, &tt.args.user, db.usernameIDX[usernameKey(tt.args.user.OrgID, tt.args.user.Username)])
 				assert.Equal(t1, &tt.args.user, db.users[0]) //TODO: change if add new test cases
 			}
 			assert.Equal(t1, tt.want.error, err)
@@ -95,10 +101,75 @@ func TestDatabase_AddUser(t1 *testing.T) {
 	}
 }
 
+func TestDatabase_AddUsers(t1 *testing.T) {
+	tests := []struct {
+		name    string
+		users   []User
+		wantErr error
+	}{
+		{name: "standard case", users: []User{{ID: "4", Username: "testUser4"}, {ID: "5", Username: "testUser5"}}},
+		{name: "existing username", users: []User{{ID: "4", Username: "testUser4"}, {ID: "5", Username: "testUser"}}, wantErr: ErrNotUniqueUsername},
+		{name: "repeating username", users: []User{{ID: "4", Username: "testUser4"}, {ID: "5", Username: "testUser4"}}, wantErr: ErrNotUniqueUsername},
+		{name: "repeating ID", users: []User{{ID: "4", Username: "testUser4"}, {ID: "4", Username: "testUser5"}}, wantErr: ErrUserAlreadyExist},
+		{name: "same username in other organization", users: []User{{ID: "4", OrgID: "other", Username: "testUser"}}},
+	}
+
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			db := prepareDB(true)
+
+			err := db.AddUsers(context.Background(), tt.users)
+			assert.Equal(t1, tt.wantErr, err)
+
+			count, _ := db.CountUsers(context.Background(), UserFilter{})
+			if tt.wantErr != nil {
+				assert.Equal(t1, len(testUsers), count, "no user should be added")
+				return
+			}
+			assert.Equal(t1, len(testUsers)+len(tt.users), count)
+			for _, user := range tt.users {
+				assert.Equal(t1, user, *db.idIDX[user.ID])
+			}
+		})
+	}
+}
+
+func TestDatabase_EachUser(t1 *testing.T) {
+	db := prepareDB(true)
+
+	var usernames []string
+	err := db.EachUser(context.Background(), UserFilter{}, func(user User) error {
+		if user.ID == "1" {
+			username := "changedUser"
+			assert.NoError(t1, db.ChangeUser(context.Background(), UserUpdate{ID: "3", Username: &username}), "lock should not be held")
+			assert.NoError(t1, db.DeleteUser(context.Background(), "2"))
+		}
+		usernames = append(usernames, user.Username)
+		return nil
+	})
+	assert.NoError(t1, err)
+	assert.Equal(t1, []string{"testUser", "testUser2", "testUser3"}, usernames, "snapshot should not see later changes")
+
+	user, err := db.GetUserByUsername(context.Background(), "", "changedUser")
+	assert.NoError(t1, err)
+	assert.Equal(t1, "3", user.ID)
+	_, err = db.GetUserByUsername(context.Background(), "", "testUser3")
+	assert.ErrorIs(t1, err, ErrUserDoesNotExist)
+
+	usernames = nil
+	err = db.EachUser(context.Background(), UserFilter{SortBy: SortByUsername, SortDesc: true}, func(user User) error {
+		usernames = append(usernames, user.Username)
+		return nil
+	})
+	assert.NoError(t1, err)
+	assert.Equal(t1, []string{"testUser", "changedUser"}, usernames)
+}
+
 func TestDatabase_GetAllUsers(t1 *testing.T) {
 	type args struct {
 		offset int
 		limit  int
+		filter UserFilter
 	}
 	type res struct {
 		users []User
@@ -112,13 +183,16 @@ func TestDatabase_GetAllUsers(t1 *testing.T) {
 		{name: "only one user in result", args: args{offset: 1, limit: 1}, want: res{testUsers[1:2]}},
 		{name: "offset more than amount of users in db", args: args{offset: 5, limit: 2}, want: res{testUsers[1:]}},
 		{name: "offset+limit is more than len of slice of users in db", args: args{offset: 0, limit: 5}, want: res{testUsers}},
+		{name: "filter by attributes", args: args{offset: 0, limit: 5, filter: UserFilter{Attributes: map[string]string{"department": "sales", "floor": "2"}}}, want: res{testUsers[1:2]}},
+		{name: "no users match filter", args: args{offset: 0, limit: 5, filter: UserFilter{Attributes: map[string]string{"department": "hr"}}}, want: res{[]User{}}},
 	}
 
 	db := prepareDB(true)
 
 	for _, tt := range tests {
 		t1.Run(tt.name, func(t1 *testing.T) {
-			users := db.GetAllUsers(tt.args.offset, tt.args.limit)
+			users, err := db.GetAllUsers(context.Background(), tt.args.offset, tt.args.limit, tt.args.filter)
+			assert.NoError(t1, err)
 			assert.Equal(t1, tt.want.users, users)
 		})
 	}
@@ -146,7 +220,7 @@ func TestDatabase_GetUserByID(t1 *testing.T) {
 
 	for _, tt := range tests {
 		t1.Run(tt.name, func(t1 *testing.T) {
-			user, err := db.GetUserByID(tt.args.userID)
+			user, err := db.GetUserByID(context.Background(), tt.args.userID)
 			if tt.want.wantErr {
 				assert.Equal(t1, tt.want.err, err)
 			} else {
@@ -179,7 +253,7 @@ func TestDatabase_GetUserByUsername(t1 *testing.T) {
 
 	for _, tt := range tests {
 		t1.Run(tt.name, func(t1 *testing.T) {
-			user, err := db.GetUserByUsername(tt.args.username)
+			user, err := db.GetUserByUsername(context.Background(), "", tt.args.username)
 			if tt.want.wantErr {
 				assert.Equal(t1, tt.want.err, err)
 			} else {
@@ -237,7 +311,7 @@ func TestDatabase_ChangeUser(t1 *testing.T) {
 
 	for _, tt := range tests {
 		t1.Run(tt.name, func(t1 *testing.T) {
-			err := db.ChangeUser(tt.args.user)
+			err := db.ChangeUser(context.Background(), tt.args.user)
 			if tt.want.wantErr {
 				assert.Equal(t1, tt.want.err, err)
 			} else {
@@ -268,17 +342,40 @@ func TestDatabase_DeleteUser(t1 *testing.T) {
 
 	for _, tt := range tests {
 		t1.Run(tt.name, func(t1 *testing.T) {
-			err := db.DeleteUser(tt.args.userID)
+			err := db.DeleteUser(context.Background(), tt.args.userID)
 			if tt.want.wantErr {
 				assert.Equal(t1, tt.want.err, err)
 			} else {
 				assert.NoError(t1, err)
 				_, ok := db.idIDX[tt.args.userID]
 				assert.Equal(t1, false, ok)
-				_, ok = db.usernameIDX["testUser"] //TODO: change if add new test cases
+				_, ok = db.usernameIDX[usernameKey("", "testUser")] //TODO: change if add new test cases
 				assert.Equal(t1, false, ok)
 				assert.NotEqual(t1, testUsers[0], *db.users[0]) //TODO: change if add new test cases
 			}
 		})
 	}
 }
+
+func TestDatabase_lockCancellation(t1 *testing.T) {
+	db := prepareDB(true)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := db.GetUserByID(ctx, "1")
+	assert.ErrorIs(t1, err, context.Canceled)
+
+	db.mutex.Lock()
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = db.GetAllUsers(ctx, 0, 10, UserFilter{})
+	assert.ErrorIs(t1, err, context.DeadlineExceeded)
+	assert.ErrorIs(t1, db.DeleteUser(ctx, "1"), context.DeadlineExceeded)
+	db.mutex.Unlock()
+
+	// locks taken by abandoned waiters are released, so storage stays usable
+	user, err := db.GetUserByID(context.Background(), "1")
+	assert.NoError(t1, err)
+	assert.Equal(t1, "1", user.ID)
+	assert.NoError(t1, db.DeleteUser(context.Background(), "1"))
+}
@@ -5,3 +5,14 @@ import "errors"
 var ErrUserAlreadyExist = errors.New("user with this id is already exist")
 var ErrNotUniqueUsername = errors.New("user with this username is already exist")
 var ErrUserDoesNotExist = errors.New("user does not exist")
+var ErrGroupAlreadyExist = errors.New("group with this id is already exist")
+var ErrNotUniqueGroupName = errors.New("group with this name is already exist")
+var ErrGroupDoesNotExist = errors.New("group does not exist")
+var ErrParentGroupDoesNotExist = errors.New("parent group does not exist")
+var ErrGroupCycle = errors.New("group can not be nested into itself or its subgroup")
+var ErrGroupHasSubgroups = errors.New("group has subgroups")
+var ErrNotGroupMember = errors.New("user is not a member of the group")
+var ErrOrganizationAlreadyExist = errors.New("organization with this id is already exist")
+var ErrNotUniqueOrganizationName = errors.New("organization with this name is already exist")
+var ErrOrganizationDoesNotExist = errors.New("organization does not exist")
+var ErrOrganizationNotEmpty = errors.New("organization still has users")
@@ -0,0 +1,98 @@
+package database
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	SortByUsername          = "username"
+	SortByEmail             = "email"
+	SortByCreatedAt         = "created_at"
+	SortByUpdatedAt         = "updated_at"
+	SortByLastLoginAt       = "last_login_at"
+	SortByPasswordChangedAt = "password_changed_at"
+)
+
+func (f UserFilter) isEmpty() bool {
+	return f.OrgID == "" && len(f.Attributes) == 0 &&
+		f.CreatedAfter.IsZero() && f.CreatedBefore.IsZero() &&
+		f.UpdatedAfter.IsZero() && f.UpdatedBefore.IsZero() &&
+		f.LastLoginAfter.IsZero() && f.LastLoginBefore.IsZero()
+}
+
+func (f UserFilter) match(user *User) bool {
+	if f.OrgID != "" && f.OrgID != user.OrgID {
+		return false
+	}
+
+	if !inRange(user.CreatedAt, f.CreatedAfter, f.CreatedBefore) ||
+		!inRange(user.UpdatedAt, f.UpdatedAfter, f.UpdatedBefore) ||
+		!inRange(user.LastLoginAt, f.LastLoginAfter, f.LastLoginBefore) {
+		return false
+	}
+
+	for key, value := range f.Attributes {
+		attr, ok := user.Attributes[key]
+		if !ok || attributeToString(attr) != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (f UserFilter) sort(users []*User) {
+	var less func(a, b *User) bool
+	switch f.SortBy {
+	case SortByUsername:
+		less = func(a, b *User) bool { return a.Username < b.Username }
+	case SortByEmail:
+		less = func(a, b *User) bool { return a.Email < b.Email }
+	case SortByCreatedAt:
+		less = func(a, b *User) bool { return a.CreatedAt.Before(b.CreatedAt) }
+	case SortByUpdatedAt:
+		less = func(a, b *User) bool { return a.UpdatedAt.Before(b.UpdatedAt) }
+	case SortByLastLoginAt:
+		less = func(a, b *User) bool { return a.LastLoginAt.Before(b.LastLoginAt) }
+	case SortByPasswordChangedAt:
+		less = func(a, b *User) bool { return a.PasswordChangedAt.Before(b.PasswordChangedAt) }
+	default:
+		return
+	}
+
+	sort.SliceStable(users, func(i, j int) bool {
+		if f.SortDesc {
+			return less(users[j], users[i])
+		}
+		return less(users[i], users[j])
+	})
+}
+
+// inRange checks that t is not before after and is before before, zero bounds are ignored
+func inRange(t, after, before time.Time) bool {
+	if !after.IsZero() && t.Before(after) {
+		return false
+	}
+
+	if !before.IsZero() && !t.Before(before) {
+		return false
+	}
+
+	return true
+}
+
+func attributeToString(attr any) string {
+	switch v := attr.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,282 @@
+package database
+
+import "context"
+
+func (db *Database) AddGroup(ctx context.Context, group Group) error {
+	ctx, span := tracer.Start(ctx, "Database.AddGroup")
+	defer span.End()
+
+	if err := db.lock(ctx); err != nil {
+		return err
+	}
+	defer db.mutex.Unlock()
+
+	if _, ok := db.groupIDX[group.ID]; ok {
+		return ErrGroupAlreadyExist
+	}
+
+	if db.groupNameTaken(group.OrgID, group.Name) {
+		return ErrNotUniqueGroupName
+	}
+
+	if group.ParentID != "" {
+		if parent, ok := db.groupIDX[group.ParentID]; !ok || parent.OrgID != group.OrgID {
+			return ErrParentGroupDoesNotExist
+		}
+	}
+
+	db.groupIDX[group.ID] = &group
+	db.groups = append(db.groups, &group)
+
+	return nil
+}
+
+func (db *Database) GetAllGroups(ctx context.Context, orgID string) ([]Group, error) {
+	ctx, span := tracer.Start(ctx, "Database.GetAllGroups")
+	defer span.End()
+
+	if err := db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer db.mutex.RUnlock()
+
+	result := make([]Group, 0)
+	for _, group := range db.groups {
+		if group.OrgID == orgID {
+			result = append(result, *group)
+		}
+	}
+
+	return result, nil
+}
+
+func (db *Database) GetGroupByID(ctx context.Context, id string) (*Group, error) {
+	ctx, span := tracer.Start(ctx, "Database.GetGroupByID")
+	defer span.End()
+
+	if err := db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer db.mutex.RUnlock()
+
+	group, ok := db.groupIDX[id]
+	if !ok {
+		return nil, ErrGroupDoesNotExist
+	}
+
+	return group, nil
+}
+
+func (db *Database) ChangeGroup(ctx context.Context, group GroupUpdate) error {
+	ctx, span := tracer.Start(ctx, "Database.ChangeGroup")
+	defer span.End()
+
+	if err := db.lock(ctx); err != nil {
+		return err
+	}
+	defer db.mutex.Unlock()
+
+	oldGroup, ok := db.groupIDX[group.ID]
+	if !ok {
+		return ErrGroupDoesNotExist
+	}
+
+	if group.Name != nil && *group.Name != oldGroup.Name && db.groupNameTaken(oldGroup.OrgID, *group.Name) {
+		return ErrNotUniqueGroupName
+	}
+
+	if group.ParentID != nil && *group.ParentID != "" {
+		if parent, ok := db.groupIDX[*group.ParentID]; !ok || parent.OrgID != oldGroup.OrgID {
+			return ErrParentGroupDoesNotExist
+		}
+
+		for parentID := *group.ParentID; parentID != ""; parentID = db.groupIDX[parentID].ParentID {
+			if parentID == group.ID {
+				return ErrGroupCycle
+			}
+		}
+	}
+
+	if group.Name != nil {
+		oldGroup.Name = *group.Name
+	}
+
+	if group.Description != nil {
+		oldGroup.Description = *group.Description
+	}
+
+	if group.ParentID != nil {
+		oldGroup.ParentID = *group.ParentID
+	}
+
+	return nil
+}
+
+func (db *Database) DeleteGroup(ctx context.Context, id string) error {
+	ctx, span := tracer.Start(ctx, "Database.DeleteGroup")
+	defer span.End()
+
+	if err := db.lock(ctx); err != nil {
+		return err
+	}
+	defer db.mutex.Unlock()
+
+	if _, ok := db.groupIDX[id]; !ok {
+		return ErrGroupDoesNotExist
+	}
+
+	for _, group := range db.groups {
+		if group.ParentID == id {
+			return ErrGroupHasSubgroups
+		}
+	}
+
+	delete(db.groupIDX, id)
+	delete(db.members, id)
+
+	for i, v := range db.groups {
+		if v.ID == id {
+			db.groups = append(db.groups[:i], db.groups[i+1:]...)
+			break
+		}
+	}
+
+	return nil
+}
+
+func (db *Database) AddMember(ctx context.Context, groupID, userID string) error {
+	ctx, span := tracer.Start(ctx, "Database.AddMember")
+	defer span.End()
+
+	if err := db.lock(ctx); err != nil {
+		return err
+	}
+	defer db.mutex.Unlock()
+
+	group, ok := db.groupIDX[groupID]
+	if !ok {
+		return ErrGroupDoesNotExist
+	}
+
+	if user, ok := db.idIDX[userID]; !ok || user.OrgID != group.OrgID {
+		return ErrUserDoesNotExist
+	}
+
+	if _, ok := db.members[groupID]; !ok {
+		db.members[groupID] = make(map[string]struct{})
+	}
+	db.members[groupID][userID] = struct{}{}
+
+	return nil
+}
+
+func (db *Database) DeleteMember(ctx context.Context, groupID, userID string) error {
+	ctx, span := tracer.Start(ctx, "Database.DeleteMember")
+	defer span.End()
+
+	if err := db.lock(ctx); err != nil {
+		return err
+	}
+	defer db.mutex.Unlock()
+
+	if _, ok := db.groupIDX[groupID]; !ok {
+		return ErrGroupDoesNotExist
+	}
+
+	if _, ok := db.members[groupID][userID]; !ok {
+		return ErrNotGroupMember
+	}
+
+	delete(db.members[groupID], userID)
+
+	return nil
+}
+
+// GetUserGroups returns groups user is a member of, directly or through any of their subgroups.
+func (db *Database) GetUserGroups(ctx context.Context, userID string) ([]Group, error) {
+	ctx, span := tracer.Start(ctx, "Database.GetUserGroups")
+	defer span.End()
+
+	if err := db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer db.mutex.RUnlock()
+
+	if _, ok := db.idIDX[userID]; !ok {
+		return nil, ErrUserDoesNotExist
+	}
+
+	found := make(map[string]struct{})
+	for groupID, members := range db.members {
+		if _, ok := members[userID]; !ok {
+			continue
+		}
+
+		for id := groupID; id != ""; id = db.groupIDX[id].ParentID {
+			if _, ok := found[id]; ok {
+				break
+			}
+			found[id] = struct{}{}
+		}
+	}
+
+	result := make([]Group, 0, len(found))
+	for _, group := range db.groups {
+		if _, ok := found[group.ID]; ok {
+			result = append(result, *group)
+		}
+	}
+
+	return result, nil
+}
+
+// GetGroupMembers returns members of the group and of all its nested subgroups.
+func (db *Database) GetGroupMembers(ctx context.Context, groupID string) ([]User, error) {
+	ctx, span := tracer.Start(ctx, "Database.GetGroupMembers")
+	defer span.End()
+
+	if err := db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer db.mutex.RUnlock()
+
+	if _, ok := db.groupIDX[groupID]; !ok {
+		return nil, ErrGroupDoesNotExist
+	}
+
+	groups := map[string]struct{}{groupID: {}}
+	for queue := []string{groupID}; len(queue) != 0; queue = queue[1:] {
+		for _, group := range db.groups {
+			if _, ok := groups[group.ID]; !ok && group.ParentID == queue[0] {
+				groups[group.ID] = struct{}{}
+				queue = append(queue, group.ID)
+			}
+		}
+	}
+
+	members := make(map[string]struct{})
+	for id := range groups {
+		for userID := range db.members[id] {
+			members[userID] = struct{}{}
+		}
+	}
+
+	result := make([]User, 0, len(members))
+	for _, user := range db.users {
+		if _, ok := members[user.ID]; ok {
+			result = append(result, *user)
+		}
+	}
+
+	return result, nil
+}
+
+func (db *Database) groupNameTaken(orgID, name string) bool {
+	for _, group := range db.groups {
+		if group.OrgID == orgID && group.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
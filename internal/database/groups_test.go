@@ -0,0 +1,124 @@
+package database
+
+import (
+	"context"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testGroups = []Group{
+	{ID: "g1", Name: "company"},
+	{ID: "g2", Name: "dev", ParentID: "g1"},
+	{ID: "g3", Name: "backend", ParentID: "g2"},
+	{ID: "g4", Name: "sales", ParentID: "g1"},
+}
+
+func prepareGroups(db *Database) {
+	for _, group := range testGroups {
+		if err := db.AddGroup(context.Background(), group); err != nil {
+			log.Fatalf("failed to add group: %v, %s", group.ID, err.Error())
+		}
+	}
+
+	members := [][2]string{{"g3", "1"}, {"g2", "2"}, {"g4", "3"}}
+	for _, m := range members {
+		if err := db.AddMember(context.Background(), m[0], m[1]); err != nil {
+			log.Fatalf("failed to add member: %v, %s", m, err.Error())
+		}
+	}
+}
+
+func TestDatabase_AddGroup(t1 *testing.T) {
+	tests := []struct {
+		name  string
+		group Group
+		err   error
+	}{
+		{name: "standard case", group: Group{ID: "g5", Name: "qa", ParentID: "g2"}, err: nil},
+		{name: "repeating name", group: Group{ID: "g6", Name: "dev"}, err: ErrNotUniqueGroupName},
+		{name: "repeating id", group: Group{ID: "g1", Name: "other"}, err: ErrGroupAlreadyExist},
+		{name: "parent does not exist", group: Group{ID: "g7", Name: "orphan", ParentID: "g100"}, err: ErrParentGroupDoesNotExist},
+	}
+
+	db := prepareDB(true)
+	prepareGroups(db)
+
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			assert.Equal(t1, tt.err, db.AddGroup(context.Background(), tt.group))
+		})
+	}
+}
+
+func TestDatabase_ChangeGroup(t1 *testing.T) {
+	root, backend, name := "", "g3", "developers"
+
+	tests := []struct {
+		name   string
+		update GroupUpdate
+		err    error
+	}{
+		{name: "standard case", update: GroupUpdate{ID: "g2", Name: &name}, err: nil},
+		{name: "nest into own subgroup", update: GroupUpdate{ID: "g2", ParentID: &backend}, err: ErrGroupCycle},
+		{name: "make root", update: GroupUpdate{ID: "g4", ParentID: &root}, err: nil},
+		{name: "group does not exist", update: GroupUpdate{ID: "g100", Name: &name}, err: ErrGroupDoesNotExist},
+	}
+
+	db := prepareDB(true)
+	prepareGroups(db)
+
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			assert.Equal(t1, tt.err, db.ChangeGroup(context.Background(), tt.update))
+		})
+	}
+}
+
+func TestDatabase_GetUserGroups(t1 *testing.T) {
+	db := prepareDB(true)
+	prepareGroups(db)
+
+	groups, err := db.GetUserGroups(context.Background(), "1")
+	assert.NoError(t1, err)
+	assert.Equal(t1, testGroups[0:3], groups)
+
+	groups, err = db.GetUserGroups(context.Background(), "3")
+	assert.NoError(t1, err)
+	assert.Equal(t1, []Group{testGroups[0], testGroups[3]}, groups)
+
+	_, err = db.GetUserGroups(context.Background(), "100")
+	assert.Equal(t1, ErrUserDoesNotExist, err)
+}
+
+func TestDatabase_GetGroupMembers(t1 *testing.T) {
+	db := prepareDB(true)
+	prepareGroups(db)
+
+	members, err := db.GetGroupMembers(context.Background(), "g2")
+	assert.NoError(t1, err)
+	assert.Equal(t1, testUsers[0:2], members)
+
+	members, err = db.GetGroupMembers(context.Background(), "g1")
+	assert.NoError(t1, err)
+	assert.Equal(t1, testUsers, members)
+
+	assert.NoError(t1, db.DeleteUser(context.Background(), "2"))
+	members, err = db.GetGroupMembers(context.Background(), "g2")
+	assert.NoError(t1, err)
+	assert.Equal(t1, testUsers[0:1], members)
+}
+
+func TestDatabase_DeleteGroup(t1 *testing.T) {
+	db := prepareDB(true)
+	prepareGroups(db)
+
+	assert.Equal(t1, ErrGroupHasSubgroups, db.DeleteGroup(context.Background(), "g2"))
+	assert.NoError(t1, db.DeleteGroup(context.Background(), "g3"))
+	assert.Equal(t1, ErrGroupDoesNotExist, db.DeleteGroup(context.Background(), "g3"))
+
+	groups, err := db.GetUserGroups(context.Background(), "1")
+	assert.NoError(t1, err)
+	assert.Empty(t1, groups)
+}
@@ -0,0 +1,74 @@
+package database
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+)
+
+var tracer = otel.Tracer("github.com/KseniiaSalmina/Profiles/internal/database")
+
+// lock acquires write lock or gives up when context is done, waiting is recorded as a separate span to make contention visible in traces.
+func (db *Database) lock(ctx context.Context) error {
+	_, span := tracer.Start(ctx, "Database.lock")
+	defer span.End()
+
+	err := acquire(ctx, db.mutex.TryLock, db.mutex.Lock, db.mutex.Unlock)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return err
+}
+
+func (db *Database) rlock(ctx context.Context) error {
+	_, span := tracer.Start(ctx, "Database.rlock")
+	defer span.End()
+
+	err := acquire(ctx, db.mutex.TryRLock, db.mutex.RLock, db.mutex.RUnlock)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return err
+}
+
+// acquire waits for lock in a separate goroutine, so caller can stop waiting when context is done.
+// Lock taken after caller has given up is released right away.
+func acquire(ctx context.Context, tryLock func() bool, lock, unlock func()) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if tryLock() {
+		return nil
+	}
+
+	acquired := make(chan struct{})
+	go func() {
+		lock()
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		return nil
+	case <-ctx.Done():
+		go func() {
+			<-acquired
+			unlock()
+		}()
+		return ctx.Err()
+	}
+}
+
+// HealthCheck reports whether storage can be read, it fails when lock is not released in time.
+func (db *Database) HealthCheck(ctx context.Context) error {
+	if err := db.rlock(ctx); err != nil {
+		return err
+	}
+	db.mutex.RUnlock()
+
+	return nil
+}
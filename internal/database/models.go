@@ -1,17 +1,82 @@
 package database
 
+import "time"
+
 type User struct {
-	ID       string
-	Email    string
-	Username string
-	PassHash string
-	Admin    bool
+	ID         string
+	OrgID      string
+	Email      string
+	Username   string
+	PassHash   string
+	Admin      bool
+	SuperAdmin bool
+	Disabled   bool
+	Attributes map[string]any
+
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+	LastLoginAt        time.Time // zero if user has never logged in
+	PasswordChangedAt  time.Time
+	ReactivatedAt      time.Time // last time disabled user was enabled again
+	InactivityWarnedAt time.Time
 }
 
 type UserUpdate struct {
-	ID       string
-	Email    *string
-	Username *string
-	PassHash *string
-	Admin    *bool
+	ID         string
+	Email      *string
+	Username   *string
+	PassHash   *string
+	Admin      *bool
+	SuperAdmin *bool
+	Disabled   *bool
+	Attributes map[string]any
+	UpdatedAt  time.Time
+}
+
+type UserFilter struct {
+	OrgID      string // empty means users of all organizations
+	Attributes map[string]string
+
+	// zero time means no bound
+	CreatedAfter    time.Time
+	CreatedBefore   time.Time
+	UpdatedAfter    time.Time
+	UpdatedBefore   time.Time
+	LastLoginAfter  time.Time
+	LastLoginBefore time.Time
+
+	SortBy   string // one of SortBy constants, empty keeps insertion order
+	SortDesc bool
+}
+
+type AttributeSchema struct {
+	Properties map[string]AttributeProperty
+	Required   []string
+}
+
+type AttributeProperty struct {
+	Type      string
+	Enum      []any
+	Pattern   string
+	MaxLength int
+}
+
+type Group struct {
+	ID          string
+	OrgID       string
+	Name        string
+	Description string
+	ParentID    string
+}
+
+type GroupUpdate struct {
+	ID          string
+	Name        *string
+	Description *string
+	ParentID    *string
+}
+
+type Organization struct {
+	ID   string
+	Name string
 }
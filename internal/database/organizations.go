@@ -0,0 +1,152 @@
+package database
+
+import "context"
+
+func (db *Database) AddOrganization(ctx context.Context, org Organization) error {
+	ctx, span := tracer.Start(ctx, "Database.AddOrganization")
+	defer span.End()
+
+	if err := db.lock(ctx); err != nil {
+		return err
+	}
+	defer db.mutex.Unlock()
+
+	if _, ok := db.orgIDX[org.ID]; ok {
+		return ErrOrganizationAlreadyExist
+	}
+
+	if db.organizationByName(org.Name) != nil {
+		return ErrNotUniqueOrganizationName
+	}
+
+	db.orgIDX[org.ID] = &org
+	db.organizations = append(db.organizations, &org)
+
+	return nil
+}
+
+func (db *Database) GetAllOrganizations(ctx context.Context) ([]Organization, error) {
+	ctx, span := tracer.Start(ctx, "Database.GetAllOrganizations")
+	defer span.End()
+
+	if err := db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer db.mutex.RUnlock()
+
+	result := make([]Organization, 0, len(db.organizations))
+	for _, org := range db.organizations {
+		result = append(result, *org)
+	}
+
+	return result, nil
+}
+
+func (db *Database) GetOrganizationByID(ctx context.Context, id string) (*Organization, error) {
+	ctx, span := tracer.Start(ctx, "Database.GetOrganizationByID")
+	defer span.End()
+
+	if err := db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer db.mutex.RUnlock()
+
+	org, ok := db.orgIDX[id]
+	if !ok {
+		return nil, ErrOrganizationDoesNotExist
+	}
+
+	return org, nil
+}
+
+func (db *Database) GetOrganizationByName(ctx context.Context, name string) (*Organization, error) {
+	ctx, span := tracer.Start(ctx, "Database.GetOrganizationByName")
+	defer span.End()
+
+	if err := db.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer db.mutex.RUnlock()
+
+	org := db.organizationByName(name)
+	if org == nil {
+		return nil, ErrOrganizationDoesNotExist
+	}
+
+	return org, nil
+}
+
+func (db *Database) RenameOrganization(ctx context.Context, id, name string) error {
+	ctx, span := tracer.Start(ctx, "Database.RenameOrganization")
+	defer span.End()
+
+	if err := db.lock(ctx); err != nil {
+		return err
+	}
+	defer db.mutex.Unlock()
+
+	org, ok := db.orgIDX[id]
+	if !ok {
+		return ErrOrganizationDoesNotExist
+	}
+
+	if other := db.organizationByName(name); other != nil && other.ID != id {
+		return ErrNotUniqueOrganizationName
+	}
+
+	org.Name = name
+
+	return nil
+}
+
+// DeleteOrganization deletes organization without users, its groups are deleted with it.
+func (db *Database) DeleteOrganization(ctx context.Context, id string) error {
+	ctx, span := tracer.Start(ctx, "Database.DeleteOrganization")
+	defer span.End()
+
+	if err := db.lock(ctx); err != nil {
+		return err
+	}
+	defer db.mutex.Unlock()
+
+	if _, ok := db.orgIDX[id]; !ok {
+		return ErrOrganizationDoesNotExist
+	}
+
+	for _, user := range db.users {
+		if user.OrgID == id {
+			return ErrOrganizationNotEmpty
+		}
+	}
+
+	groups := make([]*Group, 0, len(db.groups))
+	for _, group := range db.groups {
+		if group.OrgID != id {
+			groups = append(groups, group)
+			continue
+		}
+		delete(db.groupIDX, group.ID)
+		delete(db.members, group.ID)
+	}
+	db.groups = groups
+
+	delete(db.orgIDX, id)
+	for i, v := range db.organizations {
+		if v.ID == id {
+			db.organizations = append(db.organizations[:i], db.organizations[i+1:]...)
+			break
+		}
+	}
+
+	return nil
+}
+
+func (db *Database) organizationByName(name string) *Organization {
+	for _, org := range db.organizations {
+		if org.Name == name {
+			return org
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDatabase_UsernameUniquePerOrganization(t1 *testing.T) {
+	db := prepareDB(true)
+
+	assert.NoError(t1, db.AddUser(context.Background(), User{ID: "10", OrgID: "org2", Username: "testUser"}))
+	assert.Equal(t1, ErrNotUniqueUsername, db.AddUser(context.Background(), User{ID: "11", OrgID: "org2", Username: "testUser"}))
+
+	user, err := db.GetUserByUsername(context.Background(), "org2", "testUser")
+	assert.NoError(t1, err)
+	assert.Equal(t1, "10", user.ID)
+
+	user, err = db.GetUserByUsername(context.Background(), "", "testUser")
+	assert.NoError(t1, err)
+	assert.Equal(t1, "1", user.ID)
+
+	users, err := db.GetAllUsers(context.Background(), 0, 10, UserFilter{OrgID: "org2"})
+	assert.NoError(t1, err)
+	assert.Len(t1, users, 1)
+
+	count, err := db.CountUsers(context.Background(), UserFilter{OrgID: "org2"})
+	assert.NoError(t1, err)
+	assert.Equal(t1, 1, count)
+}
+
+func TestDatabase_Organizations(t1 *testing.T) {
+	db := prepareDB(false)
+
+	assert.NoError(t1, db.AddOrganization(context.Background(), Organization{ID: "org1", Name: "first"}))
+	assert.NoError(t1, db.AddOrganization(context.Background(), Organization{ID: "org2", Name: "second"}))
+	assert.Equal(t1, ErrNotUniqueOrganizationName, db.AddOrganization(context.Background(), Organization{ID: "org3", Name: "first"}))
+	assert.Equal(t1, ErrNotUniqueOrganizationName, db.RenameOrganization(context.Background(), "org2", "first"))
+	assert.NoError(t1, db.RenameOrganization(context.Background(), "org2", "third"))
+
+	org, err := db.GetOrganizationByName(context.Background(), "third")
+	assert.NoError(t1, err)
+	assert.Equal(t1, "org2", org.ID)
+
+	assert.NoError(t1, db.AddUser(context.Background(), User{ID: "1", OrgID: "org1", Username: "user"}))
+	assert.NoError(t1, db.AddGroup(context.Background(), Group{ID: "g1", OrgID: "org2", Name: "group"}))
+	assert.Equal(t1, ErrUserDoesNotExist, db.AddMember(context.Background(), "g1", "1"))
+
+	assert.Equal(t1, ErrOrganizationNotEmpty, db.DeleteOrganization(context.Background(), "org1"))
+	assert.NoError(t1, db.DeleteOrganization(context.Background(), "org2"))
+
+	groups, err := db.GetAllGroups(context.Background(), "org2")
+	assert.NoError(t1, err)
+	assert.Empty(t1, groups)
+
+	orgs, err := db.GetAllOrganizations(context.Background())
+	assert.NoError(t1, err)
+	assert.Equal(t1, []Organization{{ID: "org1", Name: "first"}}, orgs)
+}
@@ -0,0 +1,128 @@
+package database
+
+import (
+	"context"
+	"slices"
+)
+
+// Tx changes users of storage inside Transaction, every change is checked the same way as outside of it.
+// Successful changes are recorded in undo log, so rollback touches only changed users.
+type Tx struct {
+	db   *Database
+	undo []func()
+}
+
+// Transaction holds write lock while fn runs, changes made through tx are discarded if fn returns error.
+func (db *Database) Transaction(ctx context.Context, fn func(tx *Tx) error) error {
+	ctx, span := tracer.Start(ctx, "Database.Transaction")
+	defer span.End()
+
+	if err := db.lock(ctx); err != nil {
+		return err
+	}
+	defer db.mutex.Unlock()
+
+	tx := &Tx{db: db}
+	if err := fn(tx); err != nil {
+		tx.rollback()
+		return err
+	}
+
+	return nil
+}
+
+func (tx *Tx) GetUserByID(id string) (*User, error) {
+	user, ok := tx.db.idIDX[id]
+	if !ok {
+		return nil, ErrUserDoesNotExist
+	}
+
+	return user, nil
+}
+
+func (tx *Tx) GetUserByUsername(orgID, username string) (*User, error) {
+	user, ok := tx.db.usernameIDX[usernameKey(orgID, username)]
+	if !ok {
+		return nil, ErrUserDoesNotExist
+	}
+
+	return user, nil
+}
+
+func (tx *Tx) AddUser(user User) error {
+	if err := tx.db.addUser(user); err != nil {
+		return err
+	}
+
+	added := tx.db.idIDX[user.ID]
+	tx.undo = append(tx.undo, func() {
+		tx.db.removeUser(added)
+	})
+
+	return nil
+}
+
+func (tx *Tx) ChangeUser(user UserUpdate) error {
+	old, ok := tx.db.idIDX[user.ID]
+	if !ok {
+		return ErrUserDoesNotExist
+	}
+
+	if err := tx.db.changeUser(user); err != nil {
+		return err
+	}
+
+	changed := tx.db.idIDX[user.ID]
+	tx.undo = append(tx.undo, func() {
+		tx.db.replaceUser(changed, old)
+	})
+
+	return nil
+}
+
+func (tx *Tx) DeleteUser(id string) error {
+	user, ok := tx.db.idIDX[id]
+	if !ok {
+		return ErrUserDoesNotExist
+	}
+
+	position := slices.Index(tx.db.users, user)
+	groups := make([]string, 0)
+	for groupID, members := range tx.db.members {
+		if _, ok := members[id]; ok {
+			groups = append(groups, groupID)
+		}
+	}
+
+	if err := tx.db.deleteUser(id); err != nil {
+		return err
+	}
+
+	tx.undo = append(tx.undo, func() {
+		tx.db.idIDX[user.ID] = user
+		tx.db.usernameIDX[usernameKey(user.OrgID, user.Username)] = user
+		tx.db.users = slices.Insert(tx.db.users, position, user)
+		for _, groupID := range groups {
+			tx.db.members[groupID][user.ID] = struct{}{}
+		}
+	})
+
+	return nil
+}
+
+// rollback undoes changes in reverse order, so every undo sees storage as it was right after its change.
+func (tx *Tx) rollback() {
+	for i := len(tx.undo) - 1; i >= 0; i-- {
+		tx.undo[i]()
+	}
+	tx.undo = nil
+}
+
+// removeUser drops user added by transaction, such user is not a member of any group yet.
+func (db *Database) removeUser(user *User) {
+	delete(db.idIDX, user.ID)
+	delete(db.usernameIDX, usernameKey(user.OrgID, user.Username))
+	db.users = slices.DeleteFunc(db.users, func(v *User) bool {
+		return v == user
+	})
+}
@@ -0,0 +1,108 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDatabase_Transaction(t1 *testing.T) {
+	newUser := User{ID: "4", Email: "test4@email.com", Username: "testUser4", PassHash: "super hash4"}
+	username := "changedUser"
+
+	t1.Run("commit", func(t1 *testing.T) {
+		db := prepareDB(true)
+		prepareGroups(db)
+
+		err := db.Transaction(context.Background(), func(tx *Tx) error {
+			if err := tx.AddUser(newUser); err != nil {
+				return err
+			}
+			if err := tx.ChangeUser(UserUpdate{ID: "1", Username: &username}); err != nil {
+				return err
+			}
+			return tx.DeleteUser("2")
+		})
+		assert.NoError(t1, err)
+
+		users, err := db.GetAllUsers(context.Background(), 0, 10, UserFilter{})
+		assert.NoError(t1, err)
+		assert.Equal(t1, []string{"changedUser", "testUser3", "testUser4"}, usernames(users))
+
+		members, err := db.GetGroupMembers(context.Background(), "g2")
+		assert.NoError(t1, err)
+		assert.Len(t1, members, 1)
+	})
+
+	t1.Run("rollback", func(t1 *testing.T) {
+		db := prepareDB(true)
+		prepareGroups(db)
+		errFailed := errors.New("failed")
+
+		err := db.Transaction(context.Background(), func(tx *Tx) error {
+			assert.NoError(t1, tx.AddUser(newUser))
+			assert.NoError(t1, tx.ChangeUser(UserUpdate{ID: "1", Username: &username}))
+			assert.NoError(t1, tx.DeleteUser("2"))
+
+			user, err := tx.GetUserByUsername("", "changedUser")
+			assert.NoError(t1, err)
+			assert.Equal(t1, "1", user.ID)
+			_, err = tx.GetUserByID("2")
+			assert.ErrorIs(t1, err, ErrUserDoesNotExist)
+
+			assert.ErrorIs(t1, tx.AddUser(newUser), ErrUserAlreadyExist)
+			return errFailed
+		})
+		assert.ErrorIs(t1, err, errFailed)
+
+		users, err := db.GetAllUsers(context.Background(), 0, 10, UserFilter{})
+		assert.NoError(t1, err)
+		assert.Equal(t1, testUsers, users)
+
+		user, err := db.GetUserByUsername(context.Background(), "", "testUser")
+		assert.NoError(t1, err)
+		assert.Equal(t1, testUsers[0], *user)
+		_, err = db.GetUserByID(context.Background(), "4")
+		assert.ErrorIs(t1, err, ErrUserDoesNotExist)
+
+		members, err := db.GetGroupMembers(context.Background(), "g2")
+		assert.NoError(t1, err)
+		assert.Equal(t1, testUsers[0:2], members)
+	})
+
+	t1.Run("rollback of several changes of one user", func(t1 *testing.T) {
+		db := prepareDB(true)
+		prepareGroups(db)
+		errFailed := errors.New("failed")
+
+		err := db.Transaction(context.Background(), func(tx *Tx) error {
+			assert.NoError(t1, tx.ChangeUser(UserUpdate{ID: "1", Username: &username}))
+			assert.NoError(t1, tx.DeleteUser("1"))
+			assert.NoError(t1, tx.AddUser(User{ID: "1", Email: "test@email.com", Username: "testUser"}))
+			assert.NoError(t1, tx.DeleteUser("3"))
+			return errFailed
+		})
+		assert.ErrorIs(t1, err, errFailed)
+
+		users, err := db.GetAllUsers(context.Background(), 0, 10, UserFilter{})
+		assert.NoError(t1, err)
+		assert.Equal(t1, testUsers, users)
+
+		_, err = db.GetUserByUsername(context.Background(), "", username)
+		assert.ErrorIs(t1, err, ErrUserDoesNotExist)
+
+		members, err := db.GetGroupMembers(context.Background(), "g2")
+		assert.NoError(t1, err)
+		assert.Equal(t1, testUsers[0:2], members)
+	})
+}
+
+func usernames(users []User) []string {
+	result := make([]string, 0, len(users))
+	for _, user := range users {
+		result = append(result, user.Username)
+	}
+	return result
+}
@@ -0,0 +1,30 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+var (
+	ErrInProgress = errors.New("request with this idempotency key is in progress")
+	ErrMismatch   = errors.New("idempotency key is already used for another request")
+)
+
+// Response is the first response to a request with idempotency key, it is replayed to retries of the request.
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// Store keeps responses by key until they expire.
+// Key is reserved by Start until response is saved by Finish or reservation is dropped by Cancel.
+type Store interface {
+	// Start reserves key and returns nil response, or returns saved response of the key.
+	// Fingerprint identifies request, key of another request fails with ErrMismatch, reserved key fails with ErrInProgress.
+	Start(ctx context.Context, key, fingerprint string, expiresAt time.Time) (*Response, error)
+	Finish(ctx context.Context, key string, response Response) error
+	Cancel(ctx context.Context, key string) error
+}
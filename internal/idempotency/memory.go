@@ -0,0 +1,99 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const cleanupInterval = time.Minute
+
+type entry struct {
+	fingerprint string
+	expiresAt   time.Time
+	response    *Response // nil while request is in progress
+}
+
+// Memory keeps responses of one instance, they are lost on restart.
+type Memory struct {
+	mutex       sync.Mutex
+	entries     map[string]*entry
+	lastCleanup time.Time
+	now         func() time.Time
+}
+
+func NewMemory() *Memory {
+	return &Memory{entries: make(map[string]*entry), now: time.Now}
+}
+
+func (m *Memory) Start(ctx context.Context, key, fingerprint string, expiresAt time.Time) (*Response, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.cleanup()
+
+	e, ok := m.entries[key]
+	if !ok || !m.now().Before(e.expiresAt) {
+		m.entries[key] = &entry{fingerprint: fingerprint, expiresAt: expiresAt}
+		return nil, nil
+	}
+
+	if e.fingerprint != fingerprint {
+		return nil, ErrMismatch
+	}
+
+	if e.response == nil {
+		return nil, ErrInProgress
+	}
+
+	return e.response, nil
+}
+
+func (m *Memory) Finish(ctx context.Context, key string, response Response) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if e, ok := m.entries[key]; ok {
+		e.response = &response
+	}
+
+	return nil
+}
+
+func (m *Memory) Cancel(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if e, ok := m.entries[key]; ok && e.response == nil {
+		delete(m.entries, key)
+	}
+
+	return nil
+}
+
+// cleanup removes expired responses at most once per interval.
+func (m *Memory) cleanup() {
+	now := m.now()
+	if now.Sub(m.lastCleanup) < cleanupInterval {
+		return
+	}
+	m.lastCleanup = now
+
+	for key, e := range m.entries {
+		if !now.Before(e.expiresAt) {
+			delete(m.entries, key)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemory(t1 *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewMemory()
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	response, err := store.Start(ctx, "key", "request", now.Add(time.Hour))
+	require.NoError(t1, err)
+	assert.Nil(t1, response, "key is reserved")
+
+	_, err = store.Start(ctx, "key", "request", now.Add(time.Hour))
+	assert.ErrorIs(t1, err, ErrInProgress)
+	_, err = store.Start(ctx, "key", "other request", now.Add(time.Hour))
+	assert.ErrorIs(t1, err, ErrMismatch)
+
+	saved := Response{Status: http.StatusOK, Header: http.Header{"Content-Type": {"application/json"}}, Body: []byte(`"id"`)}
+	require.NoError(t1, store.Finish(ctx, "key", saved))
+	response, err = store.Start(ctx, "key", "request", now.Add(time.Hour))
+	require.NoError(t1, err)
+	assert.Equal(t1, &saved, response)
+
+	require.NoError(t1, store.Cancel(ctx, "key"))
+	response, err = store.Start(ctx, "key", "request", now.Add(time.Hour))
+	require.NoError(t1, err)
+	assert.NotNil(t1, response, "saved response is not canceled")
+
+	_, err = store.Start(ctx, "canceled", "request", now.Add(time.Hour))
+	require.NoError(t1, err)
+	require.NoError(t1, store.Cancel(ctx, "canceled"))
+	response, err = store.Start(ctx, "canceled", "other request", now.Add(time.Hour))
+	require.NoError(t1, err)
+	assert.Nil(t1, response, "canceled key is reserved again")
+
+	now = now.Add(time.Hour)
+	response, err = store.Start(ctx, "key", "other request", now.Add(time.Hour))
+	require.NoError(t1, err)
+	assert.Nil(t1, response, "response has expired")
+	assert.Len(t1, store.entries, 1, "expired entries are cleaned up")
+}
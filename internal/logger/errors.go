@@ -0,0 +1,5 @@
+package logger
+
+import "errors"
+
+var ErrUnknownFormat = errors.New("unknown log format")
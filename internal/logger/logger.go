@@ -7,15 +7,42 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
 func NewLogger(cfg config.Logger) (*logrus.Logger, error) {
 	l := logrus.New()
 
+	apply, err := Reconfigure(l, cfg)
+	if err != nil {
+		return nil, err
+	}
+	apply()
+
+	return l, nil
+}
+
+// Reconfigure validates settings and returns function applying them to logger, nothing is changed on error.
+func Reconfigure(l *logrus.Logger, cfg config.Logger) (func(), error) {
 	lvl, err := logrus.ParseLevel(cfg.LogLevel)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parce log level: %w", err)
 	}
 
-	l.SetLevel(lvl)
+	var formatter logrus.Formatter
+	switch cfg.LogFormat {
+	case FormatText, "":
+		formatter = &logrus.TextFormatter{FullTimestamp: true}
+	case FormatJSON:
+		formatter = &logrus.JSONFormatter{}
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownFormat, cfg.LogFormat)
+	}
 
-	return l, nil
+	return func() {
+		l.SetLevel(lvl)
+		l.SetFormatter(formatter)
+	}, nil
 }
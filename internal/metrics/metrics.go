@@ -0,0 +1,134 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "profiles"
+
+// Auth results and failure reasons used as label values of authentication counter.
+const (
+	AuthSuccess = "success"
+	AuthFailure = "failure"
+
+	ReasonNone          = ""
+	ReasonNoCredentials = "no_credentials"
+	ReasonUnknownUser   = "unknown_user"
+	ReasonWrongPassword = "wrong_password"
+	ReasonWrongOrg      = "wrong_organization"
+	ReasonDisabled      = "disabled"
+	ReasonNoSession     = "no_session"
+	ReasonInvalidCSRF   = "invalid_csrf"
+	ReasonError         = "error"
+)
+
+type Metrics struct {
+	registry        *prometheus.Registry
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	authAttempts    *prometheus.CounterVec
+	bcryptDuration  prometheus.Histogram
+	storageDuration *prometheus.HistogramVec
+	rateLimited     *prometheus.CounterVec
+}
+
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "Number of served http requests by route, method and status code.",
+		}, []string{"route", "method", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of http requests by route, method and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method", "status"}),
+		authAttempts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "auth",
+			Name:      "attempts_total",
+			Help:      "Number of authentication attempts by result and failure reason.",
+		}, []string{"result", "reason"}),
+		bcryptDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "auth",
+			Name:      "bcrypt_duration_seconds",
+			Help:      "Duration of password hash verification.",
+			Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1},
+		}),
+		storageDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "storage",
+			Name:      "operation_duration_seconds",
+			Help:      "Latency of storage operations by operation name.",
+			Buckets:   []float64{.00001, .00005, .0001, .0005, .001, .005, .01, .05},
+		}, []string{"operation"}),
+		rateLimited: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "rate_limited_total",
+			Help:      "Number of requests rejected by rate limits by budget.",
+		}, []string{"budget"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.requestDuration,
+		m.authAttempts,
+		m.bcryptDuration,
+		m.storageDuration,
+		m.rateLimited,
+	)
+
+	return m
+}
+
+// RegisterUserCount exposes current amount of users, count is called on every scrape.
+func (m *Metrics) RegisterUserCount(count func() int) {
+	m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "users",
+		Help:      "Current number of users in all organizations.",
+	}, func() float64 {
+		return float64(count())
+	}))
+}
+
+func (m *Metrics) ObserveRequest(route, method string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	m.requests.WithLabelValues(route, method, code).Inc()
+	m.requestDuration.WithLabelValues(route, method, code).Observe(duration.Seconds())
+}
+
+func (m *Metrics) ObserveAuth(result, reason string) {
+	m.authAttempts.WithLabelValues(result, reason).Inc()
+}
+
+func (m *Metrics) ObserveBcrypt(duration time.Duration) {
+	m.bcryptDuration.Observe(duration.Seconds())
+}
+
+func (m *Metrics) ObserveStorage(operation string, duration time.Duration) {
+	m.storageDuration.WithLabelValues(operation).Observe(duration.Seconds())
+}
+
+func (m *Metrics) ObserveRateLimited(budget string) {
+	m.rateLimited.WithLabelValues(budget).Inc()
+}
+
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
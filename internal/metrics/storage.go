@@ -0,0 +1,169 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/KseniiaSalmina/Profiles/internal/database"
+	"github.com/KseniiaSalmina/Profiles/internal/service"
+)
+
+// Storage measures latency of every operation of wrapped storage.
+type Storage struct {
+	storage service.Storage
+	metrics *Metrics
+}
+
+func NewStorage(storage service.Storage, metrics *Metrics) *Storage {
+	return &Storage{storage: storage, metrics: metrics}
+}
+
+func (s *Storage) observe(operation string, start time.Time) {
+	s.metrics.ObserveStorage(operation, time.Since(start))
+}
+
+func (s *Storage) GetUserByUsername(ctx context.Context, orgID, username string) (*database.User, error) {
+	defer s.observe("GetUserByUsername", time.Now())
+	return s.storage.GetUserByUsername(ctx, orgID, username)
+}
+
+func (s *Storage) GetAllUsers(ctx context.Context, offset, limit int, filter database.UserFilter) ([]database.User, error) {
+	defer s.observe("GetAllUsers", time.Now())
+	return s.storage.GetAllUsers(ctx, offset, limit, filter)
+}
+
+func (s *Storage) CountUsers(ctx context.Context, filter database.UserFilter) (int, error) {
+	defer s.observe("CountUsers", time.Now())
+	return s.storage.CountUsers(ctx, filter)
+}
+
+func (s *Storage) EachUser(ctx context.Context, filter database.UserFilter, fn func(user database.User) error) error {
+	defer s.observe("EachUser", time.Now())
+	return s.storage.EachUser(ctx, filter, fn)
+}
+
+func (s *Storage) AddUser(ctx context.Context, user database.User) error {
+	defer s.observe("AddUser", time.Now())
+	return s.storage.AddUser(ctx, user)
+}
+
+func (s *Storage) AddUsers(ctx context.Context, users []database.User) error {
+	defer s.observe("AddUsers", time.Now())
+	return s.storage.AddUsers(ctx, users)
+}
+
+func (s *Storage) GetUserByID(ctx context.Context, id string) (*database.User, error) {
+	defer s.observe("GetUserByID", time.Now())
+	return s.storage.GetUserByID(ctx, id)
+}
+
+func (s *Storage) ChangeUser(ctx context.Context, user database.UserUpdate) error {
+	defer s.observe("ChangeUser", time.Now())
+	return s.storage.ChangeUser(ctx, user)
+}
+
+func (s *Storage) UpdateLastLogin(ctx context.Context, id string, loginAt time.Time) error {
+	defer s.observe("UpdateLastLogin", time.Now())
+	return s.storage.UpdateLastLogin(ctx, id, loginAt)
+}
+
+func (s *Storage) UpdateInactivityWarning(ctx context.Context, id string, warnedAt time.Time) error {
+	defer s.observe("UpdateInactivityWarning", time.Now())
+	return s.storage.UpdateInactivityWarning(ctx, id, warnedAt)
+}
+
+func (s *Storage) DeleteUser(ctx context.Context, id string) error {
+	defer s.observe("DeleteUser", time.Now())
+	return s.storage.DeleteUser(ctx, id)
+}
+
+// Transaction is measured as a whole, operations of tx are not observed separately.
+func (s *Storage) Transaction(ctx context.Context, fn func(tx *database.Tx) error) error {
+	defer s.observe("Transaction", time.Now())
+	return s.storage.Transaction(ctx, fn)
+}
+
+func (s *Storage) GetAttributeSchema(ctx context.Context) (database.AttributeSchema, error) {
+	defer s.observe("GetAttributeSchema", time.Now())
+	return s.storage.GetAttributeSchema(ctx)
+}
+
+func (s *Storage) SetAttributeSchema(ctx context.Context, schema database.AttributeSchema) error {
+	defer s.observe("SetAttributeSchema", time.Now())
+	return s.storage.SetAttributeSchema(ctx, schema)
+}
+
+func (s *Storage) GetAllGroups(ctx context.Context, orgID string) ([]database.Group, error) {
+	defer s.observe("GetAllGroups", time.Now())
+	return s.storage.GetAllGroups(ctx, orgID)
+}
+
+func (s *Storage) AddGroup(ctx context.Context, group database.Group) error {
+	defer s.observe("AddGroup", time.Now())
+	return s.storage.AddGroup(ctx, group)
+}
+
+func (s *Storage) GetGroupByID(ctx context.Context, id string) (*database.Group, error) {
+	defer s.observe("GetGroupByID", time.Now())
+	return s.storage.GetGroupByID(ctx, id)
+}
+
+func (s *Storage) ChangeGroup(ctx context.Context, group database.GroupUpdate) error {
+	defer s.observe("ChangeGroup", time.Now())
+	return s.storage.ChangeGroup(ctx, group)
+}
+
+func (s *Storage) DeleteGroup(ctx context.Context, id string) error {
+	defer s.observe("DeleteGroup", time.Now())
+	return s.storage.DeleteGroup(ctx, id)
+}
+
+func (s *Storage) AddMember(ctx context.Context, groupID, userID string) error {
+	defer s.observe("AddMember", time.Now())
+	return s.storage.AddMember(ctx, groupID, userID)
+}
+
+func (s *Storage) DeleteMember(ctx context.Context, groupID, userID string) error {
+	defer s.observe("DeleteMember", time.Now())
+	return s.storage.DeleteMember(ctx, groupID, userID)
+}
+
+func (s *Storage) GetUserGroups(ctx context.Context, userID string) ([]database.Group, error) {
+	defer s.observe("GetUserGroups", time.Now())
+	return s.storage.GetUserGroups(ctx, userID)
+}
+
+func (s *Storage) GetGroupMembers(ctx context.Context, groupID string) ([]database.User, error) {
+	defer s.observe("GetGroupMembers", time.Now())
+	return s.storage.GetGroupMembers(ctx, groupID)
+}
+
+func (s *Storage) AddOrganization(ctx context.Context, org database.Organization) error {
+	defer s.observe("AddOrganization", time.Now())
+	return s.storage.AddOrganization(ctx, org)
+}
+
+func (s *Storage) GetAllOrganizations(ctx context.Context) ([]database.Organization, error) {
+	defer s.observe("GetAllOrganizations", time.Now())
+	return s.storage.GetAllOrganizations(ctx)
+}
+
+func (s *Storage) GetOrganizationByID(ctx context.Context, id string) (*database.Organization, error) {
+	defer s.observe("GetOrganizationByID", time.Now())
+	return s.storage.GetOrganizationByID(ctx, id)
+}
+
+func (s *Storage) GetOrganizationByName(ctx context.Context, name string) (*database.Organization, error) {
+	defer s.observe("GetOrganizationByName", time.Now())
+	return s.storage.GetOrganizationByName(ctx, name)
+}
+
+func (s *Storage) RenameOrganization(ctx context.Context, id, name string) error {
+	defer s.observe("RenameOrganization", time.Now())
+	return s.storage.RenameOrganization(ctx, id, name)
+}
+
+func (s *Storage) DeleteOrganization(ctx context.Context, id string) error {
+	defer s.observe("DeleteOrganization", time.Now())
+	return s.storage.DeleteOrganization(ctx, id)
+}
@@ -0,0 +1,34 @@
+package notifier
+
+import (
+	"github.com/sirupsen/logrus"
+
+	"github.com/KseniiaSalmina/Profiles/internal/api/models"
+)
+
+// Log writes notices to the log, it is used when no delivery channel to users is configured.
+type Log struct {
+	logger *logrus.Logger
+}
+
+func NewLog(logger *logrus.Logger) *Log {
+	return &Log{logger: logger}
+}
+
+func (l *Log) NotifyInactivity(action models.InactivityAction) error {
+	fields := logrus.Fields{
+		"user_id":          action.UserID,
+		"username":         action.Username,
+		"email":            action.Email,
+		"action":           action.Action,
+		"last_activity_at": action.LastActivityAt,
+		"inactive_days":    action.InactiveDays,
+	}
+	if action.DisableAt != nil {
+		fields["disable_at"] = *action.DisableAt
+	}
+
+	l.logger.WithFields(fields).Info("inactivity notice")
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const cleanupInterval = time.Minute
+
+// Memory keeps buckets of one instance, buckets which have become full are removed periodically.
+type Memory struct {
+	mutex       sync.Mutex
+	buckets     map[string]*memoryBucket
+	lastCleanup time.Time
+	now         func() time.Time
+}
+
+type memoryBucket struct {
+	bucket
+	limit Limit
+}
+
+func NewMemory() *Memory {
+	return NewMemoryWithClock(time.Now)
+}
+
+// NewMemoryWithClock creates buckets refilled by time returned by now, e.g. frozen time in tests.
+func NewMemoryWithClock(now func() time.Time) *Memory {
+	return &Memory{buckets: make(map[string]*memoryBucket), now: now}
+}
+
+func (m *Memory) Take(ctx context.Context, key string, limit Limit, n int) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := m.now()
+	if now.Sub(m.lastCleanup) >= cleanupInterval {
+		m.cleanup(now)
+	}
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &memoryBucket{bucket: bucket{tokens: float64(limit.Burst), updated: now}}
+		m.buckets[key] = b
+	}
+	b.limit = limit
+	b.refill(limit, now)
+
+	return b.take(limit, n), nil
+}
+
+func (m *Memory) cleanup(now time.Time) {
+	m.lastCleanup = now
+	for key, b := range m.buckets {
+		b.refill(b.limit, now)
+		if b.tokens >= float64(b.limit.Burst) {
+			delete(m.buckets, key)
+		}
+	}
+}
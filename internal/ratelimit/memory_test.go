@@ -0,0 +1,68 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemory_Take(t1 *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewMemory()
+	store.now = func() time.Time { return now }
+	limit := Limit{Rate: 2, Burst: 3}
+	ctx := context.Background()
+
+	for i := 2; i >= 0; i-- {
+		res, err := store.Take(ctx, "ip", limit, 1)
+		require.NoError(t1, err)
+		assert.True(t1, res.Allowed)
+		assert.Equal(t1, 3, res.Limit)
+		assert.Equal(t1, i, res.Remaining)
+	}
+
+	res, err := store.Take(ctx, "ip", limit, 1)
+	require.NoError(t1, err)
+	assert.False(t1, res.Allowed)
+	assert.Equal(t1, 500*time.Millisecond, res.RetryAfter)
+	assert.Equal(t1, 1500*time.Millisecond, res.Reset)
+
+	res, err = store.Take(ctx, "other", limit, 1)
+	require.NoError(t1, err)
+	assert.True(t1, res.Allowed, "buckets of other keys are independent")
+
+	now = now.Add(500 * time.Millisecond)
+	res, err = store.Take(ctx, "ip", limit, 0)
+	require.NoError(t1, err)
+	assert.True(t1, res.Allowed)
+	assert.Equal(t1, 1, res.Remaining, "check does not take tokens")
+
+	res, err = store.Take(ctx, "ip", limit, 1)
+	require.NoError(t1, err)
+	assert.True(t1, res.Allowed)
+	assert.Equal(t1, 0, res.Remaining)
+
+	now = now.Add(time.Hour)
+	res, err = store.Take(ctx, "ip", limit, 1)
+	require.NoError(t1, err)
+	assert.Equal(t1, 2, res.Remaining, "bucket is not filled above burst")
+}
+
+func TestMemory_cleanup(t1 *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewMemory()
+	store.now = func() time.Time { return now }
+	limit := Limit{Rate: 1, Burst: 10}
+
+	_, err := store.Take(context.Background(), "idle", limit, 1)
+	require.NoError(t1, err)
+	now = now.Add(cleanupInterval)
+	_, err = store.Take(context.Background(), "active", limit, 1)
+	require.NoError(t1, err)
+
+	assert.NotContains(t1, store.buckets, "idle")
+	assert.Contains(t1, store.buckets, "active")
+}
@@ -0,0 +1,67 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Limit is a token bucket: it holds at most Burst tokens and gets Rate tokens per second, zero rate disables the limit.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+func (l Limit) Enabled() bool {
+	return l.Rate > 0 && l.Burst > 0
+}
+
+// Result describes bucket state after Take.
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration // time until a token is available, zero if allowed
+	Reset      time.Duration // time until bucket is full
+}
+
+// Store keeps token buckets by key.
+type Store interface {
+	// Take removes n tokens from bucket if it has enough of them, n = 0 only checks that bucket is not empty.
+	Take(ctx context.Context, key string, limit Limit, n int) (Result, error)
+}
+
+// bucket is state of one key, tokens are refilled lazily on access.
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+func (b *bucket) refill(limit Limit, now time.Time) {
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.updated).Seconds()*limit.Rate)
+	b.updated = now
+}
+
+func (b *bucket) take(limit Limit, n int) Result {
+	need := math.Max(float64(n), 1)
+	allowed := b.tokens >= need
+	if allowed {
+		b.tokens -= float64(n)
+	}
+
+	result := Result{
+		Allowed:   allowed,
+		Limit:     limit.Burst,
+		Remaining: int(b.tokens),
+		Reset:     seconds((float64(limit.Burst) - b.tokens) / limit.Rate),
+	}
+	if !allowed {
+		result.RetryAfter = seconds((need - b.tokens) / limit.Rate)
+	}
+
+	return result
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
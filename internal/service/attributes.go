@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/KseniiaSalmina/Profiles/internal/api/models"
+	"github.com/KseniiaSalmina/Profiles/internal/database"
+	"github.com/KseniiaSalmina/Profiles/internal/validation"
+)
+
+func (s *Service) GetAttributeSchema(ctx context.Context) (models.AttributeSchema, error) {
+	ctx, span := tracer.Start(ctx, "Service.GetAttributeSchema")
+	defer span.End()
+
+	dbSchema, err := s.storage.GetAttributeSchema(ctx)
+	if err != nil {
+		return models.AttributeSchema{}, fmt.Errorf("failed to get attribute schema: %w", err)
+	}
+
+	schema := models.AttributeSchema{
+		Type:       "object",
+		Properties: make(map[string]models.AttributeProperty, len(dbSchema.Properties)),
+		Required:   dbSchema.Required,
+	}
+
+	for name, property := range dbSchema.Properties {
+		schema.Properties[name] = models.AttributeProperty{
+			Type:      property.Type,
+			Enum:      property.Enum,
+			Pattern:   property.Pattern,
+			MaxLength: property.MaxLength,
+		}
+	}
+
+	return schema, nil
+}
+
+// SetAttributeSchema replaces attribute schema. Already stored attributes are not revalidated, new schema is applied on the next user change.
+func (s *Service) SetAttributeSchema(ctx context.Context, schema models.AttributeSchema) error {
+	ctx, span := tracer.Start(ctx, "Service.SetAttributeSchema")
+	defer span.End()
+
+	if err := validation.AttributeSchema(schema); err != nil {
+		return fmt.Errorf("failed to set attribute schema: %w", err)
+	}
+
+	dbSchema := database.AttributeSchema{
+		Properties: make(map[string]database.AttributeProperty, len(schema.Properties)),
+		Required:   schema.Required,
+	}
+
+	for name, property := range schema.Properties {
+		dbSchema.Properties[name] = database.AttributeProperty{
+			Type:      property.Type,
+			Enum:      property.Enum,
+			Pattern:   property.Pattern,
+			MaxLength: property.MaxLength,
+		}
+	}
+
+	if err := s.storage.SetAttributeSchema(ctx, dbSchema); err != nil {
+		return fmt.Errorf("failed to set attribute schema: %w", err)
+	}
+
+	return nil
+}
+
+// mergeAttributes returns new map with changes applied over current attributes, nil value removes attribute.
+func mergeAttributes(current, changes map[string]any) map[string]any {
+	merged := make(map[string]any, len(current)+len(changes))
+
+	for key, value := range current {
+		merged[key] = value
+	}
+
+	for key, value := range changes {
+		if value == nil {
+			delete(merged, key)
+			continue
+		}
+		merged[key] = value
+	}
+
+	return merged
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"slices"
+	"strconv"
+	"time"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/webp"
+
+	"github.com/KseniiaSalmina/Profiles/internal/validation"
+)
+
+const avatarOriginal = "original"
+
+// SetAvatar replaces avatar of user, only super admins can change avatars of other super admins.
+func (s *Service) SetAvatar(ctx context.Context, orgID, id string, r io.Reader, callerSuperAdmin bool) error {
+	ctx, span := tracer.Start(ctx, "Service.SetAvatar")
+	defer span.End()
+
+	user, err := s.getUser(ctx, orgID, id)
+	if err != nil {
+		return fmt.Errorf("failed to set avatar: %w", err)
+	}
+
+	if err := checkTarget(user, callerSuperAdmin); err != nil {
+		return fmt.Errorf("failed to set avatar: %w", err)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, s.avatar.AvatarMaxSize+1))
+	if err != nil {
+		return fmt.Errorf("failed to read avatar: %w", err)
+	}
+
+	if int64(len(data)) > s.avatar.AvatarMaxSize {
+		return fmt.Errorf("failed to set avatar: %w: max %d bytes", validation.ErrImageTooLarge, s.avatar.AvatarMaxSize)
+	}
+
+	contentType, err := validation.Avatar(data, s.avatar.AvatarMaxWidth, s.avatar.AvatarMaxHeight)
+	if err != nil {
+		return fmt.Errorf("failed to set avatar: %w", err)
+	}
+
+	img, err := decodeImage(data, contentType)
+	if err != nil {
+		return fmt.Errorf("failed to set avatar: %w", err)
+	}
+
+	// new images replace old ones under the same keys, so the previous avatar is served until the new one is saved
+	if err := s.putAvatar(id, avatarOriginal, img); err != nil {
+		return err
+	}
+	keep := []string{avatarKey(id, avatarOriginal)}
+
+	for _, size := range s.avatar.AvatarSizes {
+		name := strconv.Itoa(size)
+		if err := s.putAvatar(id, name, resize(img, size)); err != nil {
+			return err
+		}
+		keep = append(keep, avatarKey(id, name))
+	}
+
+	return s.deleteStaleAvatars(id, keep)
+}
+
+// deleteStaleAvatars removes images of sizes which are not configured anymore.
+func (s *Service) deleteStaleAvatars(id string, keep []string) error {
+	keys, err := s.blobStore.List(avatarPrefix(id))
+	if err != nil {
+		return fmt.Errorf("failed to delete old avatar: %w", err)
+	}
+
+	for _, key := range keys {
+		if slices.Contains(keep, key) {
+			continue
+		}
+		if err := s.blobStore.Delete(key); err != nil {
+			return fmt.Errorf("failed to delete old avatar: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// GetAvatar returns avatar in png format, zero size means original image.
+func (s *Service) GetAvatar(ctx context.Context, orgID, id string, size int) (io.ReadSeekCloser, time.Time, error) {
+	ctx, span := tracer.Start(ctx, "Service.GetAvatar")
+	defer span.End()
+
+	if _, err := s.getUser(ctx, orgID, id); err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to get avatar: %w", err)
+	}
+
+	name := avatarOriginal
+	if size != 0 {
+		if !slices.Contains(s.avatar.AvatarSizes, size) {
+			return nil, time.Time{}, fmt.Errorf("%w: available sizes %v", validation.ErrIncorrectAvatarSize, s.avatar.AvatarSizes)
+		}
+		name = strconv.Itoa(size)
+	}
+
+	blob, modTime, err := s.blobStore.Get(avatarKey(id, name))
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to get avatar: %w", err)
+	}
+
+	return blob, modTime, nil
+}
+
+func (s *Service) putAvatar(id, name string, img image.Image) error {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return fmt.Errorf("failed to encode avatar: %w", err)
+	}
+
+	if err := s.blobStore.Put(avatarKey(id, name), &buf); err != nil {
+		return fmt.Errorf("failed to save avatar: %w", err)
+	}
+
+	return nil
+}
+
+func avatarPrefix(id string) string {
+	return "avatars/" + id
+}
+
+func avatarKey(id, name string) string {
+	return avatarPrefix(id) + "/" + name + ".png"
+}
+
+func decodeImage(data []byte, contentType string) (image.Image, error) {
+	r := bytes.NewReader(data)
+
+	switch contentType {
+	case validation.ImagePNG:
+		return png.Decode(r)
+	case validation.ImageJPEG:
+		return jpeg.Decode(r)
+	case validation.ImageWebP:
+		return webp.Decode(r)
+	default:
+		return nil, validation.ErrUnsupportedImage
+	}
+}
+
+// resize fits image into size x size square keeping aspect ratio, images smaller than square are not enlarged.
+func resize(img image.Image, size int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	if width <= size && height <= size {
+		return img
+	}
+
+	if width >= height {
+		height = max(1, height*size/width)
+		width = size
+	} else {
+		width = max(1, width*size/height)
+		height = size
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Over, nil)
+
+	return dst
+}
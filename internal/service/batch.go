@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/KseniiaSalmina/Profiles/internal/api/models"
+	"github.com/KseniiaSalmina/Profiles/internal/database"
+	"github.com/KseniiaSalmina/Profiles/internal/validation"
+)
+
+// errBatchFailed rolls back transaction of all-or-nothing batch.
+var errBatchFailed = errors.New("batch operation failed")
+
+// Batch applies operations in order in one storage transaction. All-or-nothing batch is rolled back
+// on the first failed operation, otherwise failed operations are only reported.
+// Passwords are hashed before transaction, so storage is not locked for bcrypt.
+// Operations on super admins fail unless caller is super admin.
+func (s *Service) Batch(ctx context.Context, orgID string, ops []models.BatchOperation, allOrNothing, callerSuperAdmin bool) (*models.BatchResponse, error) {
+	ctx, span := tracer.Start(ctx, "Service.Batch")
+	defer span.End()
+
+	schema, err := s.GetAttributeSchema(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply batch: %w", err)
+	}
+
+	passHashes := make([]*string, len(ops))
+	passErrs := make([]error, len(ops)) // passwords rejected by policy fail only their operations
+	for i, op := range ops {
+		if op.Op == models.BatchDelete || op.User.Password == nil {
+			continue
+		}
+		if err := s.checkPassword(*op.User.Password); err != nil {
+			passErrs[i] = err
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("failed to apply batch: %w", err)
+		}
+
+		hashPass, err := s.hashPassword(ctx, *op.User.Password)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply batch: %w", err)
+		}
+		passHashes[i] = &hashPass
+	}
+
+	response := &models.BatchResponse{Results: make([]models.BatchResult, len(ops))}
+	deleted := make([]string, 0)
+
+	err = s.storage.Transaction(ctx, func(tx *database.Tx) error {
+		for i, op := range ops {
+			result := &response.Results[i]
+			result.Op = op.Op
+
+			id, err := "", passErrs[i]
+			if err == nil {
+				id, err = s.batchOperation(tx, orgID, op, schema, passHashes[i], callerSuperAdmin)
+			}
+			if err != nil {
+				result.Status = models.BatchFailed
+				result.Error = err.Error()
+				if allOrNothing {
+					return errBatchFailed
+				}
+				continue
+			}
+
+			result.ID = id
+			result.Status = models.BatchOK
+			if op.Op == models.BatchDelete {
+				deleted = append(deleted, id)
+			}
+		}
+
+		return nil
+	})
+
+	switch {
+	case errors.Is(err, errBatchFailed):
+		for i := range response.Results {
+			result := &response.Results[i]
+			switch result.Status {
+			case models.BatchOK:
+				result.Status = models.BatchRolledBack
+			case "":
+				result.Op = ops[i].Op
+				result.Status = models.BatchSkipped
+			}
+		}
+		return response, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to apply batch: %w", err)
+	}
+
+	response.Committed = true
+
+	// avatars can't be restored on rollback, so they are removed only after commit, failure leaves unused files only
+	for _, id := range deleted {
+		_ = s.blobStore.DeleteAll(avatarPrefix(id))
+	}
+
+	return response, nil
+}
+
+// batchOperation returns id of created, patched or deleted user.
+func (s *Service) batchOperation(tx *database.Tx, orgID string, op models.BatchOperation, schema models.AttributeSchema, passHash *string, callerSuperAdmin bool) (string, error) {
+	switch op.Op {
+	case models.BatchCreate:
+		if op.User.Disabled != nil {
+			return "", ErrDisabledNewUser
+		}
+
+		user := batchUserAdd(op.User)
+		if err := validation.UserAdd(user); err != nil {
+			return "", err
+		}
+		if err := s.checkNewUser(orgID, user, schema); err != nil {
+			return "", err
+		}
+
+		dbUser := s.userRecord(orgID, user, *passHash)
+		if err := tx.AddUser(dbUser); err != nil {
+			return "", err
+		}
+		return dbUser.ID, nil
+
+	case models.BatchPatch:
+		if err := validation.UserUpdate(op.User); err != nil {
+			return "", err
+		}
+
+		current, err := txUser(tx, orgID, op.ID)
+		if err != nil {
+			return "", err
+		}
+		if err := checkTarget(current, callerSuperAdmin); err != nil {
+			return "", err
+		}
+
+		changes, err := s.userChanges(current, op.User, schema, passHash)
+		if err != nil {
+			return "", err
+		}
+		return op.ID, tx.ChangeUser(changes)
+
+	case models.BatchDelete:
+		current, err := txUser(tx, orgID, op.ID)
+		if err != nil {
+			return "", err
+		}
+		if err := checkTarget(current, callerSuperAdmin); err != nil {
+			return "", err
+		}
+		return op.ID, tx.DeleteUser(op.ID)
+
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnknownBatchOperation, op.Op)
+	}
+}
+
+// batchUserAdd takes profile of created user from batch operation, missing fields are left empty for validation.
+func batchUserAdd(user models.UserUpdate) models.UserAdd {
+	add := models.UserAdd{Attributes: user.Attributes}
+	if user.Email != nil {
+		add.Email = *user.Email
+	}
+	if user.Username != nil {
+		add.Username = *user.Username
+	}
+	if user.Password != nil {
+		add.Password = *user.Password
+	}
+	if user.Admin != nil {
+		add.Admin = *user.Admin
+	}
+	if user.SuperAdmin != nil {
+		add.SuperAdmin = *user.SuperAdmin
+	}
+
+	return add
+}
+
+// txUser is getUser inside transaction.
+func txUser(tx *database.Tx, orgID, id string) (*database.User, error) {
+	user, err := tx.GetUserByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.OrgID != orgID {
+		return nil, database.ErrUserDoesNotExist
+	}
+
+	return user, nil
+}
@@ -0,0 +1,18 @@
+package service
+
+import "time"
+
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now().UTC()
+}
+
+// SetClock replaces clock used for profile timestamps, for tests.
+func (s *Service) SetClock(clock Clock) {
+	s.clock = clock
+}
@@ -0,0 +1,11 @@
+package service
+
+import "errors"
+
+var ErrImportDoesNotExist = errors.New("import does not exist")
+var ErrImportAborted = errors.New("import aborted, some rows are invalid and nothing was created")
+var ErrTooManyImports = errors.New("too many imports are running, try again later")
+var ErrImportsStopped = errors.New("service is stopping, imports are not accepted")
+var ErrUnknownBatchOperation = errors.New("batch operation should be create, patch or delete")
+var ErrDisabledNewUser = errors.New("new user can't be disabled")
+var ErrConcurrentChange = errors.New("user was changed by another request, try again")
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/KseniiaSalmina/Profiles/internal/api/models"
+	"github.com/KseniiaSalmina/Profiles/internal/database"
+)
+
+func (s *Service) GetAllGroups(ctx context.Context, orgID string) ([]models.GroupResponse, error) {
+	ctx, span := tracer.Start(ctx, "Service.GetAllGroups")
+	defer span.End()
+
+	groups, err := s.storage.GetAllGroups(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get groups: %w", err)
+	}
+
+	return groupsResponse(groups), nil
+}
+
+func (s *Service) AddGroup(ctx context.Context, orgID string, group models.GroupAdd) (string, error) {
+	ctx, span := tracer.Start(ctx, "Service.AddGroup")
+	defer span.End()
+
+	id := uuid.NewString()
+
+	dbGroup := database.Group{
+		ID:          id,
+		OrgID:       orgID,
+		Name:        group.Name,
+		Description: group.Description,
+		ParentID:    group.ParentID,
+	}
+
+	if err := s.storage.AddGroup(ctx, dbGroup); err != nil {
+		return "", fmt.Errorf("failed to create new group: %w", err)
+	}
+
+	return id, nil
+}
+
+func (s *Service) GetGroupByID(ctx context.Context, orgID, id string) (*models.GroupResponse, error) {
+	ctx, span := tracer.Start(ctx, "Service.GetGroupByID")
+	defer span.End()
+
+	dbGroup, err := s.getGroup(ctx, orgID, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group by id: %w", err)
+	}
+
+	group := groupResponse(*dbGroup)
+
+	return &group, nil
+}
+
+func (s *Service) ChangeGroup(ctx context.Context, orgID, id string, group models.GroupUpdate) error {
+	ctx, span := tracer.Start(ctx, "Service.ChangeGroup")
+	defer span.End()
+
+	if _, err := s.getGroup(ctx, orgID, id); err != nil {
+		return fmt.Errorf("failed to change group: %w", err)
+	}
+
+	dbGroup := database.GroupUpdate{
+		ID:          id,
+		Name:        group.Name,
+		Description: group.Description,
+		ParentID:    group.ParentID,
+	}
+
+	if err := s.storage.ChangeGroup(ctx, dbGroup); err != nil {
+		return fmt.Errorf("failed to change group: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Service) DeleteGroup(ctx context.Context, orgID, id string) error {
+	ctx, span := tracer.Start(ctx, "Service.DeleteGroup")
+	defer span.End()
+
+	if _, err := s.getGroup(ctx, orgID, id); err != nil {
+		return fmt.Errorf("failed to delete group: %w", err)
+	}
+
+	if err := s.storage.DeleteGroup(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete group: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Service) AddMember(ctx context.Context, orgID, groupID, userID string) error {
+	ctx, span := tracer.Start(ctx, "Service.AddMember")
+	defer span.End()
+
+	if _, err := s.getGroup(ctx, orgID, groupID); err != nil {
+		return fmt.Errorf("failed to add member: %w", err)
+	}
+
+	if err := s.storage.AddMember(ctx, groupID, userID); err != nil {
+		return fmt.Errorf("failed to add member: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Service) DeleteMember(ctx context.Context, orgID, groupID, userID string) error {
+	ctx, span := tracer.Start(ctx, "Service.DeleteMember")
+	defer span.End()
+
+	if _, err := s.getGroup(ctx, orgID, groupID); err != nil {
+		return fmt.Errorf("failed to delete member: %w", err)
+	}
+
+	if err := s.storage.DeleteMember(ctx, groupID, userID); err != nil {
+		return fmt.Errorf("failed to delete member: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Service) GetUserGroups(ctx context.Context, orgID, userID string) ([]models.GroupResponse, error) {
+	ctx, span := tracer.Start(ctx, "Service.GetUserGroups")
+	defer span.End()
+
+	if _, err := s.getUser(ctx, orgID, userID); err != nil {
+		return nil, fmt.Errorf("failed to get user's groups: %w", err)
+	}
+
+	dbGroups, err := s.storage.GetUserGroups(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user's groups: %w", err)
+	}
+
+	return groupsResponse(dbGroups), nil
+}
+
+func (s *Service) GetGroupMembers(ctx context.Context, orgID, groupID string) ([]models.UserResponse, error) {
+	ctx, span := tracer.Start(ctx, "Service.GetGroupMembers")
+	defer span.End()
+
+	if _, err := s.getGroup(ctx, orgID, groupID); err != nil {
+		return nil, fmt.Errorf("failed to get group members: %w", err)
+	}
+
+	dbUsers, err := s.storage.GetGroupMembers(ctx, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group members: %w", err)
+	}
+
+	users := make([]models.UserResponse, 0, len(dbUsers))
+	for _, user := range dbUsers {
+		users = append(users, userResponse(user))
+	}
+
+	return users, nil
+}
+
+func groupsResponse(dbGroups []database.Group) []models.GroupResponse {
+	groups := make([]models.GroupResponse, 0, len(dbGroups))
+	for _, group := range dbGroups {
+		groups = append(groups, groupResponse(group))
+	}
+
+	return groups
+}
+
+func groupResponse(group database.Group) models.GroupResponse {
+	return models.GroupResponse{
+		ID:          group.ID,
+		Name:        group.Name,
+		Description: group.Description,
+		ParentID:    group.ParentID,
+	}
+}
+
+func (s *Service) getGroup(ctx context.Context, orgID, id string) (*database.Group, error) {
+	group, err := s.storage.GetGroupByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if group.OrgID != orgID {
+		return nil, database.ErrGroupDoesNotExist
+	}
+
+	return group, nil
+}
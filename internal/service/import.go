@@ -0,0 +1,386 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/KseniiaSalmina/Profiles/internal/api/models"
+	"github.com/KseniiaSalmina/Profiles/internal/database"
+)
+
+const (
+	// importRetention is how long finished import is available for status requests.
+	importRetention = time.Hour
+	// maxRunningImports limits background imports, each of them keeps one CPU busy with bcrypt.
+	maxRunningImports = 4
+)
+
+// ImportSource returns rows of streamed import one by one, io.EOF means there are no more rows.
+type ImportSource func() (models.ImportRow, error)
+
+type importJob struct {
+	mutex  sync.Mutex
+	orgID  string
+	report models.ImportJob
+}
+
+func (j *importJob) update(change func(report *models.ImportJob)) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	change(&j.report)
+}
+
+func (j *importJob) snapshot() models.ImportJob {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	report := j.report
+	report.Rows = slices.Clone(j.report.Rows)
+
+	return report
+}
+
+func (j *importJob) fail(i int, err error) {
+	j.update(func(report *models.ImportJob) {
+		report.Rows[i].Error = err.Error()
+		report.Failed++
+		report.Processed++
+	})
+}
+
+// add appends row read by streamed import and returns its index.
+func (j *importJob) add(username string) int {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	j.report.Rows = append(j.report.Rows, models.ImportRowResult{Row: len(j.report.Rows) + 1, Username: username})
+	j.report.Total++
+
+	return len(j.report.Rows) - 1
+}
+
+func (j *importJob) finish(now time.Time, err error) {
+	j.update(func(report *models.ImportJob) {
+		report.Status = models.ImportDone
+		report.FinishedAt = &now
+		if err != nil {
+			report.Error = err.Error()
+		}
+	})
+}
+
+func (j *importJob) succeed(i int, id string) {
+	j.update(func(report *models.ImportJob) {
+		report.Rows[i].ID = id
+		report.Succeeded++
+		report.Processed++
+	})
+}
+
+// ImportUsers creates users of rows in organization and returns report when all rows are processed.
+func (s *Service) ImportUsers(ctx context.Context, orgID string, rows []models.ImportRow, opts models.ImportOptions) (*models.ImportJob, error) {
+	ctx, span := tracer.Start(ctx, "Service.ImportUsers")
+	defer span.End()
+
+	job := s.newImportJob(orgID, rows, opts)
+	if err := s.runImport(ctx, job, rows); err != nil {
+		return nil, fmt.Errorf("failed to import users: %w", err)
+	}
+
+	report := job.snapshot()
+	return &report, nil
+}
+
+// StreamImport creates users of best effort import as source returns rows, so neither rows nor their passwords
+// are kept until the whole import is read. Error of source or ctx stops import, it is reported in the job
+// and users created before stay.
+func (s *Service) StreamImport(ctx context.Context, orgID string, source ImportSource, opts models.ImportOptions) (*models.ImportJob, error) {
+	ctx, span := tracer.Start(ctx, "Service.StreamImport")
+	defer span.End()
+
+	schema, err := s.GetAttributeSchema(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to import users: %w", err)
+	}
+
+	job := s.newImportJob(orgID, nil, opts)
+	err = s.streamImport(ctx, job, source, schema, opts.DryRun)
+	job.finish(s.clock.Now(), err)
+
+	report := job.snapshot()
+	return &report, nil
+}
+
+// StartImport runs import in background, its progress is returned by GetImport. At most maxRunningImports
+// run at once. Import is cancelled when context of service is done, ctx only carries trace of the request.
+func (s *Service) StartImport(ctx context.Context, orgID string, rows []models.ImportRow, opts models.ImportOptions) (*models.ImportJob, error) {
+	s.importsMutex.Lock()
+	if s.importsDone || s.ctx.Err() != nil {
+		s.importsMutex.Unlock()
+		return nil, ErrImportsStopped
+	}
+	select {
+	case s.importSlots <- struct{}{}:
+	default:
+		s.importsMutex.Unlock()
+		return nil, ErrTooManyImports
+	}
+	s.importsWG.Add(1)
+	s.importsMutex.Unlock()
+
+	job := s.newImportJob(orgID, rows, opts)
+
+	go func() {
+		defer s.importsWG.Done()
+		defer func() { <-s.importSlots }()
+
+		ctx, span := tracer.Start(trace.ContextWithSpan(s.ctx, trace.SpanFromContext(ctx)), "Service.ImportUsers")
+		defer span.End()
+
+		_ = s.runImport(ctx, job, rows)
+	}()
+
+	report := job.snapshot()
+	return &report, nil
+}
+
+// WaitImports refuses new background imports and waits for running ones, they stop early when context
+// of service is done.
+func (s *Service) WaitImports() {
+	s.importsMutex.Lock()
+	s.importsDone = true
+	s.importsMutex.Unlock()
+
+	s.importsWG.Wait()
+}
+
+func (s *Service) GetImport(ctx context.Context, orgID, id string) (*models.ImportJob, error) {
+	_, span := tracer.Start(ctx, "Service.GetImport")
+	defer span.End()
+
+	s.importsMutex.Lock()
+	job, ok := s.imports[id]
+	s.importsMutex.Unlock()
+
+	if !ok || job.orgID != orgID {
+		return nil, ErrImportDoesNotExist
+	}
+
+	report := job.snapshot()
+	return &report, nil
+}
+
+// newImportJob registers import, imports finished more than importRetention ago are forgotten.
+func (s *Service) newImportJob(orgID string, rows []models.ImportRow, opts models.ImportOptions) *importJob {
+	now := s.clock.Now()
+
+	job := &importJob{
+		orgID: orgID,
+		report: models.ImportJob{
+			ID:        uuid.NewString(),
+			Status:    models.ImportRunning,
+			Mode:      opts.Mode,
+			DryRun:    opts.DryRun,
+			Total:     len(rows),
+			StartedAt: now,
+			Rows:      make([]models.ImportRowResult, 0, len(rows)),
+		},
+	}
+	for i, row := range rows {
+		job.report.Rows = append(job.report.Rows, models.ImportRowResult{Row: i + 1, Username: row.User.Username})
+	}
+
+	s.importsMutex.Lock()
+	defer s.importsMutex.Unlock()
+
+	for id, other := range s.imports {
+		if report := other.snapshot(); report.FinishedAt != nil && now.Sub(*report.FinishedAt) > importRetention {
+			delete(s.imports, id)
+		}
+	}
+	s.imports[job.report.ID] = job
+
+	return job
+}
+
+// runImport validates all rows first, so all-or-nothing import is aborted before any password is hashed.
+func (s *Service) runImport(ctx context.Context, job *importJob, rows []models.ImportRow) (err error) {
+	defer func() {
+		job.finish(s.clock.Now(), err)
+	}()
+
+	schema, err := s.GetAttributeSchema(ctx)
+	if err != nil {
+		return err
+	}
+
+	valid, err := s.checkImportRows(ctx, job, rows, schema)
+	if err != nil {
+		return err
+	}
+
+	report := job.snapshot()
+	switch {
+	case report.Mode == models.ImportAllOrNothing && report.Failed != 0:
+		job.update(func(report *models.ImportJob) {
+			report.Error = ErrImportAborted.Error()
+			report.Processed = report.Total
+		})
+		return nil
+	case report.DryRun:
+		for _, i := range valid {
+			job.succeed(i, "")
+		}
+		return nil
+	case report.Mode == models.ImportAllOrNothing:
+		return s.importAll(ctx, job, rows, valid, schema)
+	default:
+		return s.importEach(ctx, job, rows, valid, schema)
+	}
+}
+
+// checkImportRows records errors of invalid rows and returns indexes of valid ones.
+func (s *Service) checkImportRows(ctx context.Context, job *importJob, rows []models.ImportRow, schema models.AttributeSchema) ([]int, error) {
+	valid := make([]int, 0, len(rows))
+	usernames := make(map[string]struct{}, len(rows))
+
+	for i, row := range rows {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		ok, err := s.checkImportRow(ctx, job, i, row, schema, usernames)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			valid = append(valid, i)
+		}
+	}
+
+	return valid, nil
+}
+
+// checkImportRow records error of invalid row, only failure of storage is returned.
+// Usernames of valid rows are added to usernames, so duplicates inside import are found.
+func (s *Service) checkImportRow(ctx context.Context, job *importJob, i int, row models.ImportRow, schema models.AttributeSchema, usernames map[string]struct{}) (bool, error) {
+	if row.Err != nil {
+		job.fail(i, row.Err)
+		return false, nil
+	}
+
+	if err := s.checkNewUser(job.orgID, row.User, schema); err != nil {
+		job.fail(i, err)
+		return false, nil
+	}
+
+	if _, ok := usernames[row.User.Username]; ok {
+		job.fail(i, database.ErrNotUniqueUsername)
+		return false, nil
+	}
+	usernames[row.User.Username] = struct{}{}
+
+	_, err := s.storage.GetUserByUsername(ctx, job.orgID, row.User.Username)
+	switch {
+	case err == nil:
+		job.fail(i, database.ErrNotUniqueUsername)
+		return false, nil
+	case !errors.Is(err, database.ErrUserDoesNotExist):
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (s *Service) importAll(ctx context.Context, job *importJob, rows []models.ImportRow, valid []int, schema models.AttributeSchema) error {
+	users := make([]database.User, 0, len(valid))
+	for _, i := range valid {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		user, err := s.newUser(ctx, job.orgID, rows[i].User, schema)
+		if err != nil {
+			return err
+		}
+		// plaintext password is not kept until the whole import is processed
+		rows[i].User.Password = ""
+		users = append(users, user)
+	}
+
+	if err := s.storage.AddUsers(ctx, users); err != nil {
+		return err
+	}
+
+	for n, i := range valid {
+		job.succeed(i, users[n].ID)
+	}
+
+	return nil
+}
+
+func (s *Service) importEach(ctx context.Context, job *importJob, rows []models.ImportRow, valid []int, schema models.AttributeSchema) error {
+	for _, i := range valid {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		s.importRow(ctx, job, i, &rows[i], schema)
+	}
+
+	return nil
+}
+
+// importRow creates user of valid row, failure is recorded in job and does not stop import.
+func (s *Service) importRow(ctx context.Context, job *importJob, i int, row *models.ImportRow, schema models.AttributeSchema) {
+	user, err := s.newUser(ctx, job.orgID, row.User, schema)
+	row.User.Password = ""
+	if err == nil {
+		err = s.storage.AddUser(ctx, user)
+	}
+	if err != nil {
+		job.fail(i, err)
+		return
+	}
+
+	job.succeed(i, user.ID)
+}
+
+// streamImport checks and creates each row as soon as it is read, dry run only checks rows.
+func (s *Service) streamImport(ctx context.Context, job *importJob, source ImportSource, schema models.AttributeSchema, dryRun bool) error {
+	usernames := make(map[string]struct{})
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		row, err := source()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		i := job.add(row.User.Username)
+		ok, err := s.checkImportRow(ctx, job, i, row, schema, usernames)
+		switch {
+		case err != nil:
+			return err
+		case !ok:
+		case dryRun:
+			job.succeed(i, "")
+		default:
+			s.importRow(ctx, job, i, &row, schema)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/KseniiaSalmina/Profiles/internal/api/models"
+	"github.com/KseniiaSalmina/Profiles/internal/database"
+)
+
+const day = 24 * time.Hour
+
+type Notifier interface {
+	NotifyInactivity(action models.InactivityAction) error
+}
+
+// InactivityReport returns actions inactivity policy would take in organization on the next run, nothing is changed.
+func (s *Service) InactivityReport(ctx context.Context, orgID string) ([]models.InactivityAction, error) {
+	ctx, span := tracer.Start(ctx, "Service.InactivityReport")
+	defer span.End()
+
+	return s.evaluateInactivity(ctx, database.UserFilter{OrgID: orgID})
+}
+
+// ApplyInactivityPolicy warns and disables inactive users of all organizations, returns taken actions.
+// Failed actions are skipped and reported in error, other actions are still applied.
+func (s *Service) ApplyInactivityPolicy(ctx context.Context) ([]models.InactivityAction, error) {
+	ctx, span := tracer.Start(ctx, "Service.ApplyInactivityPolicy")
+	defer span.End()
+
+	actions, err := s.evaluateInactivity(ctx, database.UserFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make([]models.InactivityAction, 0, len(actions))
+	var errs []error
+
+	for _, action := range actions {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+
+		if err := s.applyInactivityAction(ctx, action); err != nil {
+			errs = append(errs, fmt.Errorf("failed to %s user %s: %w", action.Action, action.UserID, err))
+			continue
+		}
+		applied = append(applied, action)
+	}
+
+	return applied, errors.Join(errs...)
+}
+
+func (s *Service) applyInactivityAction(ctx context.Context, action models.InactivityAction) error {
+	now := s.clock.Now()
+
+	switch action.Action {
+	case models.InactivityWarn:
+		if err := s.storage.UpdateInactivityWarning(ctx, action.UserID, now); err != nil {
+			return err
+		}
+	case models.InactivityDisable:
+		disabled := true
+		if err := s.storage.ChangeUser(ctx, database.UserUpdate{ID: action.UserID, Disabled: &disabled, UpdatedAt: now}); err != nil {
+			return err
+		}
+	}
+
+	return s.notifier.NotifyInactivity(action)
+}
+
+func (s *Service) evaluateInactivity(ctx context.Context, filter database.UserFilter) ([]models.InactivityAction, error) {
+	actions := make([]models.InactivityAction, 0)
+
+	policy := s.inactivity
+	if policy.InactivityWarnAfterDays <= 0 && policy.InactivityDisableAfterDays <= 0 {
+		return actions, nil
+	}
+
+	now := s.clock.Now()
+
+	count, err := s.storage.CountUsers(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	users, err := s.storage.GetAllUsers(ctx, 0, count, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+
+	for _, user := range users {
+		if user.Disabled || s.inactivityExempt(user) {
+			continue
+		}
+
+		lastActivity := lastActivity(user)
+		inactiveDays := int(now.Sub(lastActivity) / day)
+
+		action := models.InactivityAction{
+			UserID:         user.ID,
+			Username:       user.Username,
+			Email:          user.Email,
+			LastActivityAt: lastActivity,
+			InactiveDays:   inactiveDays,
+		}
+
+		switch {
+		case policy.InactivityDisableAfterDays > 0 && inactiveDays >= policy.InactivityDisableAfterDays:
+			action.Action = models.InactivityDisable
+		case policy.InactivityWarnAfterDays > 0 && inactiveDays >= policy.InactivityWarnAfterDays && user.InactivityWarnedAt.Before(lastActivity):
+			action.Action = models.InactivityWarn
+			if policy.InactivityDisableAfterDays > 0 {
+				disableAt := lastActivity.Add(time.Duration(policy.InactivityDisableAfterDays) * day)
+				action.DisableAt = &disableAt
+			}
+		default:
+			continue
+		}
+
+		actions = append(actions, action)
+	}
+
+	return actions, nil
+}
+
+func (s *Service) inactivityExempt(user database.User) bool {
+	if user.Admin && s.inactivity.InactivityExemptAdmins {
+		return true
+	}
+
+	return slices.Contains(s.inactivity.InactivityAllowlist, user.Username) || slices.Contains(s.inactivity.InactivityAllowlist, user.ID)
+}
+
+// lastActivity is the latest of creation, login and reactivation
+func lastActivity(user database.User) time.Time {
+	last := user.CreatedAt
+	for _, t := range []time.Time{user.LastLoginAt, user.ReactivatedAt} {
+		if t.After(last) {
+			last = t
+		}
+	}
+
+	return last
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/KseniiaSalmina/Profiles/internal/api/models"
+	"github.com/KseniiaSalmina/Profiles/internal/database"
+	"github.com/KseniiaSalmina/Profiles/internal/validation"
+)
+
+// ResolveOrganization returns id of organization by its name, empty name means default organization.
+func (s *Service) ResolveOrganization(ctx context.Context, name string) (string, error) {
+	ctx, span := tracer.Start(ctx, "Service.ResolveOrganization")
+	defer span.End()
+
+	if name == "" {
+		return s.defaultOrgID, nil
+	}
+
+	org, err := s.storage.GetOrganizationByName(ctx, name)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve organization: %w", err)
+	}
+
+	return org.ID, nil
+}
+
+func (s *Service) GetAllOrganizations(ctx context.Context) ([]models.OrganizationResponse, error) {
+	ctx, span := tracer.Start(ctx, "Service.GetAllOrganizations")
+	defer span.End()
+
+	dbOrgs, err := s.storage.GetAllOrganizations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organizations: %w", err)
+	}
+
+	orgs := make([]models.OrganizationResponse, 0, len(dbOrgs))
+	for _, org := range dbOrgs {
+		orgs = append(orgs, models.OrganizationResponse{ID: org.ID, Name: org.Name})
+	}
+
+	return orgs, nil
+}
+
+func (s *Service) AddOrganization(ctx context.Context, org models.OrganizationAdd) (string, error) {
+	ctx, span := tracer.Start(ctx, "Service.AddOrganization")
+	defer span.End()
+
+	if err := validation.OrganizationAdd(org); err != nil {
+		return "", fmt.Errorf("failed to create organization: %w", err)
+	}
+
+	id := uuid.NewString()
+
+	if err := s.storage.AddOrganization(ctx, database.Organization{ID: id, Name: org.Name}); err != nil {
+		return "", fmt.Errorf("failed to create organization: %w", err)
+	}
+
+	return id, nil
+}
+
+func (s *Service) GetOrganizationByID(ctx context.Context, id string) (*models.OrganizationResponse, error) {
+	ctx, span := tracer.Start(ctx, "Service.GetOrganizationByID")
+	defer span.End()
+
+	org, err := s.storage.GetOrganizationByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization by id: %w", err)
+	}
+
+	return &models.OrganizationResponse{ID: org.ID, Name: org.Name}, nil
+}
+
+func (s *Service) ChangeOrganization(ctx context.Context, id string, org models.OrganizationUpdate) error {
+	ctx, span := tracer.Start(ctx, "Service.ChangeOrganization")
+	defer span.End()
+
+	if err := s.storage.RenameOrganization(ctx, id, org.Name); err != nil {
+		return fmt.Errorf("failed to change organization: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Service) DeleteOrganization(ctx context.Context, id string) error {
+	ctx, span := tracer.Start(ctx, "Service.DeleteOrganization")
+	defer span.End()
+
+	if id == s.defaultOrgID {
+		return fmt.Errorf("failed to delete organization: %w", validation.ErrDefaultOrganization)
+	}
+
+	if err := s.storage.DeleteOrganization(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete organization: %w", err)
+	}
+
+	return nil
+}
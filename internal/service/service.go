@@ -1,9 +1,18 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
 	"golang.org/x/crypto/bcrypt"
 
 	"github.com/KseniiaSalmina/Profiles/internal/api/models"
@@ -12,51 +21,142 @@ import (
 	"github.com/KseniiaSalmina/Profiles/internal/validation"
 )
 
+var tracer = otel.Tracer("github.com/KseniiaSalmina/Profiles/internal/service")
+
+const (
+	// replaceAttempts limits how many times ReplaceUser builds replacement of user changed by concurrent requests.
+	replaceAttempts = 3
+	// lastLoginPrecision is how often time of the last login is updated for user who keeps sending requests.
+	lastLoginPrecision = time.Minute
+)
+
 type Storage interface {
-	GetUserByUsername(username string) (*database.User, error)
-	GetAllUsers(offset, limit int) []database.User
-	CountUsers() int
-	AddUser(user database.User) error
-	GetUserByID(id string) (*database.User, error)
-	ChangeUser(user database.UserUpdate) error
-	DeleteUser(id string) error
+	GetUserByUsername(ctx context.Context, orgID, username string) (*database.User, error)
+	GetAllUsers(ctx context.Context, offset, limit int, filter database.UserFilter) ([]database.User, error)
+	CountUsers(ctx context.Context, filter database.UserFilter) (int, error)
+	EachUser(ctx context.Context, filter database.UserFilter, fn func(user database.User) error) error
+	AddUser(ctx context.Context, user database.User) error
+	AddUsers(ctx context.Context, users []database.User) error
+	GetUserByID(ctx context.Context, id string) (*database.User, error)
+	ChangeUser(ctx context.Context, user database.UserUpdate) error
+	UpdateLastLogin(ctx context.Context, id string, loginAt time.Time) error
+	UpdateInactivityWarning(ctx context.Context, id string, warnedAt time.Time) error
+	DeleteUser(ctx context.Context, id string) error
+	Transaction(ctx context.Context, fn func(tx *database.Tx) error) error
+	GetAttributeSchema(ctx context.Context) (database.AttributeSchema, error)
+	SetAttributeSchema(ctx context.Context, schema database.AttributeSchema) error
+	GetAllGroups(ctx context.Context, orgID string) ([]database.Group, error)
+	AddGroup(ctx context.Context, group database.Group) error
+	GetGroupByID(ctx context.Context, id string) (*database.Group, error)
+	ChangeGroup(ctx context.Context, group database.GroupUpdate) error
+	DeleteGroup(ctx context.Context, id string) error
+	AddMember(ctx context.Context, groupID, userID string) error
+	DeleteMember(ctx context.Context, groupID, userID string) error
+	GetUserGroups(ctx context.Context, userID string) ([]database.Group, error)
+	GetGroupMembers(ctx context.Context, groupID string) ([]database.User, error)
+	AddOrganization(ctx context.Context, org database.Organization) error
+	GetAllOrganizations(ctx context.Context) ([]database.Organization, error)
+	GetOrganizationByID(ctx context.Context, id string) (*database.Organization, error)
+	GetOrganizationByName(ctx context.Context, name string) (*database.Organization, error)
+	RenameOrganization(ctx context.Context, id, name string) error
+	DeleteOrganization(ctx context.Context, id string) error
+}
+
+type BlobStore interface {
+	Put(key string, r io.Reader) error
+	Get(key string) (io.ReadSeekCloser, time.Time, error)
+	List(prefix string) ([]string, error)
+	Delete(key string) error
+	DeleteAll(prefix string) error
 }
 
 type Service struct {
-	storage Storage
-	salt    string
+	storage      Storage
+	blobStore    BlobStore
+	notifier     Notifier
+	salt         string
+	avatar       config.Avatar
+	inactivity   config.Inactivity
+	defaultOrgID string
+	clock        Clock
+	passwords    atomic.Pointer[config.PasswordPolicy]
+	ctx          context.Context // done on shutdown, background imports are cancelled with it
+	importsMutex sync.Mutex
+	imports      map[string]*importJob
+	importSlots  chan struct{} // taken by running background imports
+	importsWG    sync.WaitGroup
+	importsDone  bool // background imports are not accepted after WaitImports
 }
 
-func NewService(cfg config.Service, storage Storage) (*Service, error) {
+// NewService bootstraps default organization and the first admin, ctx bounds lifetime of background imports.
+func NewService(ctx context.Context, cfg config.Service, storage Storage, blobStore BlobStore, notifier Notifier) (*Service, error) {
 	service := Service{
-		storage: storage,
-		salt:    cfg.Salt,
+		storage:     storage,
+		blobStore:   blobStore,
+		notifier:    notifier,
+		salt:        cfg.Salt,
+		avatar:      cfg.Avatar,
+		inactivity:  cfg.Inactivity,
+		clock:       systemClock{},
+		ctx:         ctx,
+		imports:     make(map[string]*importJob),
+		importSlots: make(chan struct{}, maxRunningImports),
 	}
+	service.SetPasswordPolicy(cfg.PasswordPolicy)
+
+	defaultOrgID, err := service.AddOrganization(ctx, models.OrganizationAdd{Name: cfg.DefaultTenant})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add default organization to db: %w", err)
+	}
+	service.defaultOrgID = defaultOrgID
 
 	firstUser := models.UserAdd{
-		Email:    cfg.AdminEmail,
-		Username: cfg.AdminUsername,
-		Password: cfg.AdminPassword,
-		Admin:    true,
+		Email:      cfg.AdminEmail,
+		Username:   cfg.AdminUsername,
+		Password:   cfg.AdminPassword,
+		Admin:      true,
+		SuperAdmin: true,
 	}
 
 	if err := validation.UserAdd(firstUser); err != nil {
 		return nil, fmt.Errorf("failed to add firs admin to db: %w", err)
 	}
 
-	if _, err := service.AddUser(firstUser); err != nil {
+	if _, err := service.AddUser(ctx, defaultOrgID, firstUser); err != nil {
 		return nil, fmt.Errorf("failed to add firs admin to db: %w", err)
 	}
 
 	return &service, nil
 }
 
+// HealthCheck reports whether default organization is still available,
+// NewService fails if default organization or the first admin is not created.
+func (s *Service) HealthCheck(ctx context.Context) error {
+	if _, err := s.storage.GetOrganizationByID(ctx, s.defaultOrgID); err != nil {
+		return fmt.Errorf("failed to get default organization: %w", err)
+	}
+
+	return nil
+}
+
+// ReturnSalt returns salt appended to passwords before hashing, it is not changed while service runs.
 func (s *Service) ReturnSalt() string {
 	return s.salt
 }
 
-func (s *Service) GetAuthData(username string) (*database.User, error) {
-	user, err := s.storage.GetUserByUsername(username)
+// GetAuthData looks for user in organization, super admins of default organization are found in any organization.
+func (s *Service) GetAuthData(ctx context.Context, orgID, username string) (*database.User, error) {
+	ctx, span := tracer.Start(ctx, "Service.GetAuthData")
+	defer span.End()
+
+	user, err := s.storage.GetUserByUsername(ctx, orgID, username)
+	if errors.Is(err, database.ErrUserDoesNotExist) && orgID != s.defaultOrgID {
+		superAdmin, superErr := s.storage.GetUserByUsername(ctx, s.defaultOrgID, username)
+		if superErr == nil && superAdmin.SuperAdmin {
+			return superAdmin, nil
+		}
+	}
+
 	if err != nil {
 		return nil, fmt.Errorf("failed to get auth data: %w", err)
 	}
@@ -64,21 +164,44 @@ func (s *Service) GetAuthData(username string) (*database.User, error) {
 	return user, nil
 }
 
-func (s *Service) GetAllUsers(limit, offset, pageNo int) *models.PageUsers {
-	dbUsers := s.storage.GetAllUsers(offset, limit)
+// RecordLogin saves time of successful authentication, saved time is older than lastLoginPrecision at most,
+// so requests of the same user do not lock storage every time.
+func (s *Service) RecordLogin(ctx context.Context, user *database.User) error {
+	now := s.clock.Now()
+	if now.Sub(user.LastLoginAt) < lastLoginPrecision {
+		return nil
+	}
+
+	ctx, span := tracer.Start(ctx, "Service.RecordLogin")
+	defer span.End()
+
+	if err := s.storage.UpdateLastLogin(ctx, user.ID, now); err != nil {
+		return fmt.Errorf("failed to record login: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Service) GetAllUsers(ctx context.Context, orgID string, limit, offset, pageNo int, filter models.UserFilter) (*models.PageUsers, error) {
+	ctx, span := tracer.Start(ctx, "Service.GetAllUsers")
+	defer span.End()
+
+	dbFilter := userFilter(orgID, filter)
+	dbUsers, err := s.storage.GetAllUsers(ctx, offset, limit, dbFilter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
 
 	users := make([]models.UserResponse, 0, len(dbUsers))
 	for _, user := range dbUsers {
-		u := models.UserResponse{
-			ID:       user.ID,
-			Email:    user.Email,
-			Username: user.Username,
-			Admin:    user.Admin,
-		}
-		users = append(users, u)
+		users = append(users, userResponse(user))
+	}
+
+	usersAmount, err := s.storage.CountUsers(ctx, dbFilter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count users: %w", err)
 	}
 
-	usersAmount := s.storage.CountUsers()
 	pagesAmount := usersAmount / limit
 	if usersAmount%limit != 0 {
 		pagesAmount++
@@ -93,76 +216,399 @@ func (s *Service) GetAllUsers(limit, offset, pageNo int) *models.PageUsers {
 		PageNo:      pageNo,
 		Limit:       limit,
 		PagesAmount: pagesAmount,
+	}, nil
+}
+
+// ExportUsers calls fn for every user of organization matching filter until fn returns error.
+func (s *Service) ExportUsers(ctx context.Context, orgID string, filter models.UserFilter, fn func(user models.UserResponse) error) error {
+	ctx, span := tracer.Start(ctx, "Service.ExportUsers")
+	defer span.End()
+
+	err := s.storage.EachUser(ctx, userFilter(orgID, filter), func(user database.User) error {
+		return fn(userResponse(user))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to export users: %w", err)
 	}
+
+	return nil
 }
 
-func (s *Service) AddUser(user models.UserAdd) (string, error) {
-	hashPass, err := bcrypt.GenerateFromPassword([]byte(user.Password+s.salt), bcrypt.DefaultCost)
+func (s *Service) AddUser(ctx context.Context, orgID string, user models.UserAdd) (string, error) {
+	ctx, span := tracer.Start(ctx, "Service.AddUser")
+	defer span.End()
+
+	schema, err := s.GetAttributeSchema(ctx)
 	if err != nil {
 		return "", fmt.Errorf("failed to create user: %w", err)
 	}
 
-	id := uuid.NewString()
-
-	dbUser := database.User{
-		ID:       id,
-		Email:    user.Email,
-		Username: user.Username,
-		PassHash: string(hashPass),
-		Admin:    user.Admin,
+	dbUser, err := s.newUser(ctx, orgID, user, schema)
+	if err != nil {
+		return "", fmt.Errorf("failed to create user: %w", err)
 	}
 
-	if err := s.storage.AddUser(dbUser); err != nil {
+	if err := s.storage.AddUser(ctx, dbUser); err != nil {
 		return "", fmt.Errorf("failed to create new user: %w", err)
 	}
 
-	return id, nil
+	return dbUser.ID, nil
 }
 
-func (s *Service) GetUserByID(id string) (*models.UserResponse, error) {
-	dbUser, err := s.storage.GetUserByID(id)
+// newUser checks user against organization and attribute schema and hashes its password.
+func (s *Service) newUser(ctx context.Context, orgID string, user models.UserAdd, schema models.AttributeSchema) (database.User, error) {
+	if err := s.checkNewUser(orgID, user, schema); err != nil {
+		return database.User{}, err
+	}
+
+	hashPass, err := s.hashPassword(ctx, user.Password)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user by id: %w", err)
+		return database.User{}, err
 	}
 
-	user := models.UserResponse{
-		ID:       dbUser.ID,
-		Email:    dbUser.Email,
-		Username: dbUser.Username,
-		Admin:    dbUser.Admin,
+	return s.userRecord(orgID, user, hashPass), nil
+}
+
+func (s *Service) userRecord(orgID string, user models.UserAdd, hashPass string) database.User {
+	now := s.clock.Now()
+
+	return database.User{
+		ID:         uuid.NewString(),
+		OrgID:      orgID,
+		Email:      user.Email,
+		Username:   user.Username,
+		PassHash:   hashPass,
+		Admin:      user.Admin || user.SuperAdmin,
+		SuperAdmin: user.SuperAdmin,
+		Attributes: user.Attributes,
+
+		CreatedAt:         now,
+		UpdatedAt:         now,
+		PasswordChangedAt: now,
 	}
+}
+
+func (s *Service) checkNewUser(orgID string, user models.UserAdd, schema models.AttributeSchema) error {
+	if user.SuperAdmin && orgID != s.defaultOrgID {
+		return validation.ErrSuperAdminOutsideDefault
+	}
+
+	if err := s.checkPassword(user.Password); err != nil {
+		return err
+	}
+
+	return validation.Attributes(user.Attributes, schema)
+}
+
+func (s *Service) GetUserByID(ctx context.Context, orgID, id string) (*models.UserResponse, error) {
+	ctx, span := tracer.Start(ctx, "Service.GetUserByID")
+	defer span.End()
+
+	dbUser, err := s.getUser(ctx, orgID, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user by id: %w", err)
+	}
+
+	user := userResponse(*dbUser)
 
 	return &user, nil
 }
 
-func (s *Service) ChangeUser(id string, user models.UserUpdate) error {
-	dbUser := database.UserUpdate{
-		ID:       id,
-		Email:    user.Email,
-		Username: user.Username,
-		Admin:    user.Admin,
+// ChangeUser changes fields of user, only super admins can change other super admins.
+func (s *Service) ChangeUser(ctx context.Context, orgID, id string, user models.UserUpdate, callerSuperAdmin bool) error {
+	ctx, span := tracer.Start(ctx, "Service.ChangeUser")
+	defer span.End()
+
+	current, err := s.getUser(ctx, orgID, id)
+	if err != nil {
+		return fmt.Errorf("failed to change user: %w", err)
 	}
 
+	if err := checkTarget(current, callerSuperAdmin); err != nil {
+		return fmt.Errorf("failed to change user: %w", err)
+	}
+
+	var schema models.AttributeSchema
+	if user.Attributes != nil {
+		if schema, err = s.GetAttributeSchema(ctx); err != nil {
+			return fmt.Errorf("failed to change user: %w", err)
+		}
+	}
+
+	var passHash *string
 	if user.Password != nil {
-		hashPass, err := bcrypt.GenerateFromPassword([]byte(*user.Password+s.salt), bcrypt.DefaultCost)
+		hashPass, err := s.hashPassword(ctx, *user.Password)
 		if err != nil {
 			return fmt.Errorf("failed to change user: %w", err)
 		}
-		newHash := string(hashPass)
-		dbUser.PassHash = &newHash
+		passHash = &hashPass
 	}
 
-	if err := s.storage.ChangeUser(dbUser); err != nil {
+	// attributes are merged into the latest profile under transaction lock, so concurrent changes are not lost
+	err = s.storage.Transaction(ctx, func(tx *database.Tx) error {
+		latest, err := txUser(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if err := checkTarget(latest, callerSuperAdmin); err != nil {
+			return err
+		}
+
+		dbUser, err := s.userChanges(latest, user, schema, passHash)
+		if err != nil {
+			return err
+		}
+		return tx.ChangeUser(dbUser)
+	})
+	if err != nil {
 		return fmt.Errorf("failed to change user: %w", err)
 	}
 
 	return nil
 }
 
-func (s *Service) DeleteUser(id string) error {
-	if err := s.storage.DeleteUser(id); err != nil {
+// userChanges checks changes of current user, passHash is hash of changed password.
+func (s *Service) userChanges(current *database.User, user models.UserUpdate, schema models.AttributeSchema, passHash *string) (database.UserUpdate, error) {
+	if user.SuperAdmin != nil && *user.SuperAdmin && current.OrgID != s.defaultOrgID {
+		return database.UserUpdate{}, validation.ErrSuperAdminOutsideDefault
+	}
+
+	dbUser := database.UserUpdate{
+		ID:         current.ID,
+		Email:      user.Email,
+		Username:   user.Username,
+		PassHash:   passHash,
+		Admin:      user.Admin,
+		SuperAdmin: user.SuperAdmin,
+		Disabled:   user.Disabled,
+		UpdatedAt:  s.clock.Now(),
+	}
+
+	if user.Attributes != nil {
+		attributes := mergeAttributes(current.Attributes, user.Attributes)
+		if err := validation.Attributes(attributes, schema); err != nil {
+			return database.UserUpdate{}, err
+		}
+		dbUser.Attributes = attributes
+	}
+
+	return dbUser, nil
+}
+
+// ReplaceUser saves profile built by replace from the current one. If user is changed while replacement is built,
+// replacement is built again from the new profile, so concurrent changes are never lost.
+func (s *Service) ReplaceUser(ctx context.Context, orgID, id string, replace func(current models.UserReplace) (models.UserReplace, error)) error {
+	ctx, span := tracer.Start(ctx, "Service.ReplaceUser")
+	defer span.End()
+
+	schema, err := s.GetAttributeSchema(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to replace user: %w", err)
+	}
+
+	for attempt := 1; ; attempt++ {
+		current, err := s.getUser(ctx, orgID, id)
+		if err != nil {
+			return fmt.Errorf("failed to replace user: %w", err)
+		}
+
+		user, err := replace(userReplacement(*current))
+		if err != nil {
+			return fmt.Errorf("failed to replace user: %w", err)
+		}
+
+		changes, err := s.replacementChanges(ctx, current, user, schema)
+		if err != nil {
+			return fmt.Errorf("failed to replace user: %w", err)
+		}
+
+		err = s.storage.Transaction(ctx, func(tx *database.Tx) error {
+			latest, err := tx.GetUserByID(id)
+			if err != nil {
+				return err
+			}
+			// stored users are replaced on every change, so the same pointer means the same profile
+			if latest != current {
+				return ErrConcurrentChange
+			}
+			return tx.ChangeUser(changes)
+		})
+		if errors.Is(err, ErrConcurrentChange) && attempt < replaceAttempts {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to replace user: %w", err)
+		}
+
+		return nil
+	}
+}
+
+// replacementChanges checks replacement of current user, all fields of the profile are changed.
+func (s *Service) replacementChanges(ctx context.Context, current *database.User, user models.UserReplace, schema models.AttributeSchema) (database.UserUpdate, error) {
+	if user.SuperAdmin && current.OrgID != s.defaultOrgID {
+		return database.UserUpdate{}, validation.ErrSuperAdminOutsideDefault
+	}
+
+	attributes := user.Attributes
+	if attributes == nil {
+		attributes = make(map[string]any)
+	}
+	if err := validation.Attributes(attributes, schema); err != nil {
+		return database.UserUpdate{}, err
+	}
+
+	var passHash *string
+	if user.Password != nil {
+		hashPass, err := s.hashPassword(ctx, *user.Password)
+		if err != nil {
+			return database.UserUpdate{}, err
+		}
+		passHash = &hashPass
+	}
+
+	admin := user.Admin || user.SuperAdmin
+	return database.UserUpdate{
+		ID:         current.ID,
+		Email:      &user.Email,
+		Username:   &user.Username,
+		PassHash:   passHash,
+		Admin:      &admin,
+		SuperAdmin: &user.SuperAdmin,
+		Disabled:   &user.Disabled,
+		Attributes: attributes,
+		UpdatedAt:  s.clock.Now(),
+	}, nil
+}
+
+// DeleteUser deletes user with its avatar, only super admins can delete other super admins.
+func (s *Service) DeleteUser(ctx context.Context, orgID, id string, callerSuperAdmin bool) error {
+	ctx, span := tracer.Start(ctx, "Service.DeleteUser")
+	defer span.End()
+
+	err := s.storage.Transaction(ctx, func(tx *database.Tx) error {
+		current, err := txUser(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if err := checkTarget(current, callerSuperAdmin); err != nil {
+			return err
+		}
+		return tx.DeleteUser(id)
+	})
+	if err != nil {
 		return fmt.Errorf("failed to delete user: %w", err)
 	}
 
+	if err := s.blobStore.DeleteAll(avatarPrefix(id)); err != nil {
+		return fmt.Errorf("failed to delete user's avatar: %w", err)
+	}
+
 	return nil
 }
+
+// SetPasswordPolicy replaces policy of passwords set from now on, existing passwords are not checked again.
+func (s *Service) SetPasswordPolicy(policy config.PasswordPolicy) {
+	s.passwords.Store(&policy)
+}
+
+func (s *Service) checkPassword(password string) error {
+	if minLength := s.passwords.Load().PasswordMinLength; utf8.RuneCountInString(password) < minLength {
+		return fmt.Errorf("%w: at least %d characters required", validation.ErrWeakPassword, minLength)
+	}
+
+	return nil
+}
+
+// hashPassword is traced separately, bcrypt is usually the slowest part of user changes.
+// Password is checked against password policy first.
+func (s *Service) hashPassword(ctx context.Context, password string) (string, error) {
+	if err := s.checkPassword(password); err != nil {
+		return "", err
+	}
+
+	_, span := tracer.Start(ctx, "bcrypt.GenerateFromPassword")
+	defer span.End()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password+s.salt), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+
+	return string(hash), nil
+}
+
+// getUser returns user only if it belongs to organization, users of other organizations are reported as not existing.
+func (s *Service) getUser(ctx context.Context, orgID, id string) (*database.User, error) {
+	user, err := s.storage.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.OrgID != orgID {
+		return nil, database.ErrUserDoesNotExist
+	}
+
+	return user, nil
+}
+
+// checkTarget forbids changes of super admins to callers who are not super admins.
+func checkTarget(target *database.User, callerSuperAdmin bool) error {
+	if target.SuperAdmin && !callerSuperAdmin {
+		return validation.ErrIsNotSuperAdmin
+	}
+
+	return nil
+}
+
+func userFilter(orgID string, filter models.UserFilter) database.UserFilter {
+	return database.UserFilter{
+		OrgID:           orgID,
+		Attributes:      filter.Attributes,
+		CreatedAfter:    filter.CreatedAfter,
+		CreatedBefore:   filter.CreatedBefore,
+		UpdatedAfter:    filter.UpdatedAfter,
+		UpdatedBefore:   filter.UpdatedBefore,
+		LastLoginAfter:  filter.LastLoginAfter,
+		LastLoginBefore: filter.LastLoginBefore,
+		SortBy:          filter.SortBy,
+		SortDesc:        filter.SortDesc,
+	}
+}
+
+func userReplacement(user database.User) models.UserReplace {
+	attributes := maps.Clone(user.Attributes)
+	if attributes == nil {
+		attributes = make(map[string]any)
+	}
+
+	return models.UserReplace{
+		Email:      user.Email,
+		Username:   user.Username,
+		Admin:      user.Admin,
+		SuperAdmin: user.SuperAdmin,
+		Disabled:   user.Disabled,
+		Attributes: attributes,
+	}
+}
+
+func userResponse(user database.User) models.UserResponse {
+	response := models.UserResponse{
+		ID:                user.ID,
+		Email:             user.Email,
+		Username:          user.Username,
+		Admin:             user.Admin,
+		Disabled:          user.Disabled,
+		Attributes:        user.Attributes,
+		CreatedAt:         user.CreatedAt,
+		UpdatedAt:         user.UpdatedAt,
+		PasswordChangedAt: user.PasswordChangedAt,
+	}
+
+	if !user.LastLoginAt.IsZero() {
+		lastLogin := user.LastLoginAt
+		response.LastLoginAt = &lastLogin
+	}
+
+	return response
+}
@@ -0,0 +1,82 @@
+package session
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const cleanupInterval = time.Minute
+
+// Memory keeps sessions of one instance, they are lost on restart.
+type Memory struct {
+	mutex       sync.Mutex
+	sessions    map[string]Session
+	lastCleanup time.Time
+	now         func() time.Time
+}
+
+func NewMemory() *Memory {
+	return &Memory{sessions: make(map[string]Session), now: time.Now}
+}
+
+func (m *Memory) Create(ctx context.Context, session Session) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.cleanup()
+
+	key := hashToken(session.Token)
+	session.Token = ""
+	m.sessions[key] = session
+
+	return nil
+}
+
+func (m *Memory) Get(ctx context.Context, token string) (Session, error) {
+	if err := ctx.Err(); err != nil {
+		return Session{}, err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	session, ok := m.sessions[hashToken(token)]
+	if !ok || !m.now().Before(session.ExpiresAt) {
+		return Session{}, ErrSessionDoesNotExist
+	}
+
+	return session, nil
+}
+
+func (m *Memory) Delete(ctx context.Context, token string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	delete(m.sessions, hashToken(token))
+
+	return nil
+}
+
+// cleanup removes expired sessions at most once per interval.
+func (m *Memory) cleanup() {
+	now := m.now()
+	if now.Sub(m.lastCleanup) < cleanupInterval {
+		return
+	}
+	m.lastCleanup = now
+
+	for key, session := range m.sessions {
+		if !now.Before(session.ExpiresAt) {
+			delete(m.sessions, key)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package session
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemory(t1 *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewMemory()
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	created, err := New("user id", "org id", "username", time.Hour, now)
+	require.NoError(t1, err)
+	require.NoError(t1, store.Create(ctx, created))
+
+	got, err := store.Get(ctx, created.Token)
+	require.NoError(t1, err)
+	assert.Equal(t1, "user id", got.UserID)
+	assert.Empty(t1, got.Token, "token is not kept")
+	assert.True(t1, got.ValidCSRF(created.CSRFToken))
+	assert.False(t1, got.ValidCSRF(""))
+	assert.False(t1, got.ValidCSRF(created.Token))
+
+	_, err = store.Get(ctx, created.CSRFToken)
+	assert.ErrorIs(t1, err, ErrSessionDoesNotExist)
+
+	now = now.Add(time.Hour)
+	_, err = store.Get(ctx, created.Token)
+	assert.ErrorIs(t1, err, ErrSessionDoesNotExist, "session has expired")
+
+	other, err := New("user id", "org id", "username", time.Hour, now)
+	require.NoError(t1, err)
+	require.NoError(t1, store.Create(ctx, other))
+	assert.Len(t1, store.sessions, 1, "expired session is cleaned up")
+
+	require.NoError(t1, store.Delete(ctx, other.Token))
+	_, err = store.Get(ctx, other.Token)
+	assert.ErrorIs(t1, err, ErrSessionDoesNotExist)
+}
@@ -0,0 +1,77 @@
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const tokenLength = 32
+
+var ErrSessionDoesNotExist = errors.New("session does not exist or has expired")
+
+// Session is created on login, its token is kept by browser in cookie and its CSRF token is sent in header by the client code.
+type Session struct {
+	Token     string // only returned by Create, stores keep its hash
+	CSRFToken string
+	UserID    string
+	OrgID     string
+	Username  string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// ValidCSRF compares token in constant time.
+func (s Session) ValidCSRF(token string) bool {
+	return token != "" && subtle.ConstantTimeCompare([]byte(s.CSRFToken), []byte(token)) == 1
+}
+
+// Store keeps sessions by token.
+type Store interface {
+	Create(ctx context.Context, session Session) error
+	Get(ctx context.Context, token string) (Session, error)
+	Delete(ctx context.Context, token string) error
+}
+
+// New fills tokens and times of session.
+func New(userID, orgID, username string, ttl time.Duration, now time.Time) (Session, error) {
+	token, err := randomToken()
+	if err != nil {
+		return Session{}, err
+	}
+	csrf, err := randomToken()
+	if err != nil {
+		return Session{}, err
+	}
+
+	return Session{
+		Token:     token,
+		CSRFToken: csrf,
+		UserID:    userID,
+		OrgID:     orgID,
+		Username:  username,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}, nil
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, tokenLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken keeps session tokens out of the store, so its content can't be used to log in.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,5 @@
+package tracing
+
+import "errors"
+
+var ErrUnknownExporter = errors.New("unknown tracing exporter")
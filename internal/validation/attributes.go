@@ -0,0 +1,166 @@
+package validation
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/KseniiaSalmina/Profiles/internal/api/models"
+)
+
+const (
+	attributeTypeString  = "string"
+	attributeTypeNumber  = "number"
+	attributeTypeInteger = "integer"
+	attributeTypeBoolean = "boolean"
+)
+
+// patterns keeps compiled patterns of attribute schemas, schema is rebuilt from storage on every read,
+// so patterns are compiled when schema is set and reused by validation of attributes.
+var patterns sync.Map // pattern string to *regexp.Regexp
+
+func AttributeSchema(schema models.AttributeSchema) error {
+	if schema.Type != "" && schema.Type != "object" {
+		return fmt.Errorf("%w: root type should be object", ErrIncorrectAttributeSchema)
+	}
+
+	for name, property := range schema.Properties {
+		if name == "" {
+			return fmt.Errorf("%w: empty property name", ErrIncorrectAttributeSchema)
+		}
+
+		switch property.Type {
+		case attributeTypeString, attributeTypeNumber, attributeTypeInteger, attributeTypeBoolean:
+		default:
+			return fmt.Errorf("%w: property %q has unsupported type %q", ErrIncorrectAttributeSchema, name, property.Type)
+		}
+
+		if property.MaxLength < 0 {
+			return fmt.Errorf("%w: property %q has negative maxLength", ErrIncorrectAttributeSchema, name)
+		}
+
+		if property.Pattern != "" {
+			if _, err := compilePattern(property.Pattern); err != nil {
+				return fmt.Errorf("%w: property %q has invalid pattern: %s", ErrIncorrectAttributeSchema, name, err.Error())
+			}
+		}
+
+		for _, value := range property.Enum {
+			if err := attributeType(value, property.Type); err != nil {
+				return fmt.Errorf("%w: property %q enum: %s", ErrIncorrectAttributeSchema, name, err.Error())
+			}
+		}
+	}
+
+	for _, name := range schema.Required {
+		if _, ok := schema.Properties[name]; !ok {
+			return fmt.Errorf("%w: required property %q is not defined", ErrIncorrectAttributeSchema, name)
+		}
+	}
+
+	return nil
+}
+
+// Attributes checks user's attributes against schema. Attributes not described in schema are allowed.
+func Attributes(attributes map[string]any, schema models.AttributeSchema) error {
+	for _, name := range schema.Required {
+		if _, ok := attributes[name]; !ok {
+			return fmt.Errorf("%w: %q is required", ErrInvalidAttribute, name)
+		}
+	}
+
+	for name, value := range attributes {
+		if name == "" {
+			return fmt.Errorf("%w: empty attribute name", ErrInvalidAttribute)
+		}
+
+		property, ok := schema.Properties[name]
+		if !ok {
+			continue
+		}
+
+		if err := attributeValue(value, property); err != nil {
+			return fmt.Errorf("%w: %q %s", ErrInvalidAttribute, name, err.Error())
+		}
+	}
+
+	return nil
+}
+
+func attributeValue(value any, property models.AttributeProperty) error {
+	if err := attributeType(value, property.Type); err != nil {
+		return err
+	}
+
+	if len(property.Enum) != 0 {
+		found := false
+		for _, allowed := range property.Enum {
+			if allowed == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("is not one of allowed values")
+		}
+	}
+
+	str, ok := value.(string)
+	if !ok {
+		return nil
+	}
+
+	if property.MaxLength > 0 && utf8.RuneCountInString(str) > property.MaxLength {
+		return fmt.Errorf("is longer than %d characters", property.MaxLength)
+	}
+
+	if property.Pattern != "" {
+		re, err := compilePattern(property.Pattern)
+		if err != nil {
+			return fmt.Errorf("has invalid pattern in schema: %w", err)
+		}
+		if !re.MatchString(str) {
+			return fmt.Errorf("does not match pattern %q", property.Pattern)
+		}
+	}
+
+	return nil
+}
+
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	patterns.Store(pattern, re)
+
+	return re, nil
+}
+
+func attributeType(value any, typ string) error {
+	var ok bool
+	switch typ {
+	case attributeTypeString:
+		_, ok = value.(string)
+	case attributeTypeNumber:
+		_, ok = value.(float64)
+	case attributeTypeInteger:
+		var f float64
+		f, ok = value.(float64)
+		ok = ok && f == math.Trunc(f)
+	case attributeTypeBoolean:
+		_, ok = value.(bool)
+	}
+
+	if !ok {
+		return fmt.Errorf("should be %s", typ)
+	}
+
+	return nil
+}
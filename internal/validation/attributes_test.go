@@ -0,0 +1,98 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KseniiaSalmina/Profiles/internal/api/models"
+)
+
+var testSchema = models.AttributeSchema{
+	Type: "object",
+	Properties: map[string]models.AttributeProperty{
+		"department": {Type: "string", Enum: []any{"sales", "dev"}},
+		"phone":      {Type: "string", Pattern: `^\+[0-9]+$`, MaxLength: 12},
+		"floor":      {Type: "integer"},
+		"remote":     {Type: "boolean"},
+	},
+	Required: []string{"department"},
+}
+
+func TestAttributes(t1 *testing.T) {
+	type args struct {
+		attributes map[string]any
+	}
+	type res struct {
+		wantErr bool
+		err     error
+	}
+	tests := []struct {
+		name string
+		args args
+		want res
+	}{
+		{name: "standard case", args: args{attributes: map[string]any{
+			"department": "sales",
+			"phone":      "+79991234567",
+			"floor":      float64(3),
+			"remote":     true,
+			"nickname":   "not in schema",
+		}}, want: res{wantErr: false}},
+		{name: "required attribute is missing", args: args{attributes: map[string]any{"floor": float64(3)}}, want: res{wantErr: true, err: ErrInvalidAttribute}},
+		{name: "value is not in enum", args: args{attributes: map[string]any{"department": "hr"}}, want: res{wantErr: true, err: ErrInvalidAttribute}},
+		{name: "pattern mismatch", args: args{attributes: map[string]any{"department": "dev", "phone": "phone"}}, want: res{wantErr: true, err: ErrInvalidAttribute}},
+		{name: "too long", args: args{attributes: map[string]any{"department": "dev", "phone": "+7999123456789"}}, want: res{wantErr: true, err: ErrInvalidAttribute}},
+		{name: "not integer", args: args{attributes: map[string]any{"department": "dev", "floor": 2.5}}, want: res{wantErr: true, err: ErrInvalidAttribute}},
+		{name: "wrong type", args: args{attributes: map[string]any{"department": "dev", "remote": "yes"}}, want: res{wantErr: true, err: ErrInvalidAttribute}},
+	}
+
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			err := Attributes(tt.args.attributes, testSchema)
+			if !tt.want.wantErr {
+				assert.NoError(t1, err)
+				return
+			}
+			assert.True(t1, errors.Is(err, tt.want.err))
+		})
+	}
+}
+
+func TestAttributeSchema(t1 *testing.T) {
+	type args struct {
+		schema models.AttributeSchema
+	}
+	type res struct {
+		wantErr bool
+	}
+	tests := []struct {
+		name string
+		args args
+		want res
+	}{
+		{name: "standard case", args: args{schema: testSchema}, want: res{wantErr: false}},
+		{name: "unsupported type", args: args{schema: models.AttributeSchema{Properties: map[string]models.AttributeProperty{
+			"tags": {Type: "array"},
+		}}}, want: res{wantErr: true}},
+		{name: "invalid pattern", args: args{schema: models.AttributeSchema{Properties: map[string]models.AttributeProperty{
+			"phone": {Type: "string", Pattern: "[0-9"},
+		}}}, want: res{wantErr: true}},
+		{name: "enum of wrong type", args: args{schema: models.AttributeSchema{Properties: map[string]models.AttributeProperty{
+			"floor": {Type: "integer", Enum: []any{"first"}},
+		}}}, want: res{wantErr: true}},
+		{name: "required is not defined", args: args{schema: models.AttributeSchema{Required: []string{"department"}}}, want: res{wantErr: true}},
+	}
+
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			err := AttributeSchema(tt.args.schema)
+			if !tt.want.wantErr {
+				assert.NoError(t1, err)
+				return
+			}
+			assert.True(t1, errors.Is(err, ErrIncorrectAttributeSchema))
+		})
+	}
+}
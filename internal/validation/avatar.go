@@ -0,0 +1,46 @@
+package validation
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"golang.org/x/image/webp"
+)
+
+const (
+	ImagePNG  = "image/png"
+	ImageJPEG = "image/jpeg"
+	ImageWebP = "image/webp"
+)
+
+// Avatar sniffs image format by content and checks dimensions without decoding whole image, returns content type.
+func Avatar(data []byte, maxWidth, maxHeight int) (string, error) {
+	contentType := http.DetectContentType(data)
+
+	var decodeConfig func(r *bytes.Reader) (image.Config, error)
+	switch contentType {
+	case ImagePNG:
+		decodeConfig = func(r *bytes.Reader) (image.Config, error) { return png.DecodeConfig(r) }
+	case ImageJPEG:
+		decodeConfig = func(r *bytes.Reader) (image.Config, error) { return jpeg.DecodeConfig(r) }
+	case ImageWebP:
+		decodeConfig = func(r *bytes.Reader) (image.Config, error) { return webp.DecodeConfig(r) }
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedImage, contentType)
+	}
+
+	cfg, err := decodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedImage, err.Error())
+	}
+
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width > maxWidth || cfg.Height > maxHeight {
+		return "", fmt.Errorf("%w: %dx%d, max %dx%d", ErrIncorrectImageSize, cfg.Width, cfg.Height, maxWidth, maxHeight)
+	}
+
+	return contentType, nil
+}
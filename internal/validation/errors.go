@@ -6,3 +6,18 @@ var ErrIncorrectAuthData = errors.New("user with this username or password is no
 var ErrIsNotAdmin = errors.New("user is not admin")
 var ErrNoChanges = errors.New("no changes submitted")
 var ErrIncorrectUserData = errors.New("user should have username, password and email")
+var ErrIncorrectAttributeSchema = errors.New("incorrect attribute schema")
+var ErrInvalidAttribute = errors.New("invalid attribute")
+var ErrUnsupportedImage = errors.New("image should be png, jpeg or webp")
+var ErrIncorrectImageSize = errors.New("incorrect image dimensions")
+var ErrImageTooLarge = errors.New("image is too large")
+var ErrIncorrectAvatarSize = errors.New("incorrect avatar size")
+var ErrIncorrectGroupData = errors.New("group should have name")
+var ErrIncorrectParentID = errors.New("parent id should be in uuid format")
+var ErrIsNotSuperAdmin = errors.New("user is not super admin")
+var ErrSuperAdminOutsideDefault = errors.New("super admin can exist only in default organization")
+var ErrIncorrectOrganizationData = errors.New("organization should have name of latin letters, digits, \"-\" and \"_\"")
+var ErrDefaultOrganization = errors.New("default organization can not be deleted")
+var ErrIncorrectSort = errors.New("unknown sort field")
+var ErrUserDisabled = errors.New("user is disabled")
+var ErrWeakPassword = errors.New("password does not satisfy password policy")
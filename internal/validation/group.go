@@ -0,0 +1,39 @@
+package validation
+
+import (
+	"github.com/google/uuid"
+
+	"github.com/KseniiaSalmina/Profiles/internal/api/models"
+)
+
+func GroupAdd(group models.GroupAdd) error {
+	if group.Name == "" {
+		return ErrIncorrectGroupData
+	}
+
+	if group.ParentID != "" {
+		if _, err := uuid.Parse(group.ParentID); err != nil {
+			return ErrIncorrectParentID
+		}
+	}
+
+	return nil
+}
+
+func GroupUpdate(group models.GroupUpdate) error {
+	if group.Name == nil && group.Description == nil && group.ParentID == nil {
+		return ErrNoChanges
+	}
+
+	if group.Name != nil && *group.Name == "" {
+		return ErrIncorrectGroupData
+	}
+
+	if group.ParentID != nil && *group.ParentID != "" {
+		if _, err := uuid.Parse(*group.ParentID); err != nil {
+			return ErrIncorrectParentID
+		}
+	}
+
+	return nil
+}
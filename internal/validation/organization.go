@@ -0,0 +1,26 @@
+package validation
+
+import (
+	"regexp"
+
+	"github.com/KseniiaSalmina/Profiles/internal/api/models"
+)
+
+// organization name is used as tenant in path prefix and header, so it is limited to url-safe characters
+var organizationName = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+func OrganizationAdd(org models.OrganizationAdd) error {
+	if !organizationName.MatchString(org.Name) {
+		return ErrIncorrectOrganizationData
+	}
+
+	return nil
+}
+
+func OrganizationUpdate(org models.OrganizationUpdate) error {
+	if !organizationName.MatchString(org.Name) {
+		return ErrIncorrectOrganizationData
+	}
+
+	return nil
+}
@@ -19,6 +19,10 @@ func Auth(username, password string, user database.User) error {
 		return ErrIncorrectAuthData
 	}
 
+	if user.Disabled {
+		return ErrUserDisabled
+	}
+
 	return nil
 }
 
@@ -35,7 +39,7 @@ func UserAdd(user models.UserAdd) error {
 }
 
 func UserUpdate(user models.UserUpdate) error {
-	if user.Email == nil && user.Username == nil && user.Password == nil && user.Admin == nil {
+	if user.Email == nil && user.Username == nil && user.Password == nil && user.Admin == nil && user.SuperAdmin == nil && user.Disabled == nil && user.Attributes == nil {
 		return ErrNoChanges
 	}
 
@@ -58,3 +62,25 @@ func UserUpdate(user models.UserUpdate) error {
 
 	return nil
 }
+
+func UserReplace(user models.UserReplace) error {
+	if user.Username == "" || user.Email == "" || (user.Password != nil && *user.Password == "") {
+		return ErrIncorrectUserData
+	}
+
+	if _, err := mail.ParseAddress(user.Email); err != nil {
+		return fmt.Errorf("invalid email address: %w", err)
+	}
+
+	return nil
+}
+
+func UserFilter(filter models.UserFilter) error {
+	switch filter.SortBy {
+	case "", database.SortByUsername, database.SortByEmail, database.SortByCreatedAt, database.SortByUpdatedAt,
+		database.SortByLastLoginAt, database.SortByPasswordChangedAt:
+		return nil
+	default:
+		return fmt.Errorf("%w: %q", ErrIncorrectSort, filter.SortBy)
+	}
+}
@@ -2,28 +2,17 @@ package main
 
 import (
 	"log"
-
-	"github.com/caarlos0/env/v6"
-	"github.com/joho/godotenv"
+	"os"
 
 	_ "github.com/KseniiaSalmina/Profiles/docs"
 	app "github.com/KseniiaSalmina/Profiles/internal"
 	"github.com/KseniiaSalmina/Profiles/internal/config"
 )
 
-var cfg config.Application
-
-func init() {
-	_ = godotenv.Load(".env")
-	if err := env.Parse(&cfg); err != nil {
-		panic(err)
-	}
-
-}
-
 // @title Profiles managment API
 // @version 1.0.0
 // @description service to managment users profiles
+// @description organization (tenant) is chosen by "/tenant/{name}" path prefix or X-Tenant header, default organization is used when neither is set
 
 // @host localhost:8080
 // @BasePath /
@@ -32,7 +21,36 @@ func init() {
 // @in header
 // @name Authorization
 func main() {
-	application, err := app.NewApplication(cfg)
+	loader, err := config.NewLoader(os.Args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// secret is put before the whole config is loaded, which may refer to this secret
+	if loader.KeystorePut != "" {
+		keystore, err := loader.LoadKeystore()
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := keystore.Put(loader.KeystorePut, os.Stdin); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	cfg, err := loader.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if loader.PrintConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	application, err := app.NewApplication(cfg, loader)
 	if err != nil {
 		log.Fatal(err)
 	}